@@ -0,0 +1,272 @@
+// Package anki reads and writes Anki .apkg deck packages.
+//
+// An .apkg file is a zip archive holding an SQLite collection
+// (collection.anki21 or collection.anki2), a "media" JSON file mapping the
+// numbered zip entries to the original media file names, and the media files.
+package anki
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"langlearner1/backend/types"
+)
+
+// fieldSeparator separates the fields of a note in the notes.flds column
+const fieldSeparator = "\x1f"
+
+// Note is a note read from an Anki collection
+type Note struct {
+	ID   int64
+	GUID string
+	// Model is the name of the note type
+	Model      string
+	FieldNames []string
+	Fields     []string
+	Tags       []string
+	Modified   int64
+}
+
+// Field returns the value of the named field, or "" if the note has no such field
+func (n Note) Field(name string) string {
+	for i, fieldName := range n.FieldNames {
+		if strings.EqualFold(fieldName, name) && i < len(n.Fields) {
+			return n.Fields[i]
+		}
+	}
+	return ""
+}
+
+// Package is an opened .apkg archive
+type Package struct {
+	Notes []Note
+	// Media maps media file names to their entries in the archive
+	Media map[string]*zip.File
+
+	archive *zip.ReadCloser
+}
+
+// Open reads the notes and the media index of an .apkg file
+func Open(apkgPath string) (*Package, error) {
+	archive, err := zip.OpenReader(apkgPath)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &Package{archive: archive, Media: map[string]*zip.File{}}
+	if err := pkg.load(); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Close releases the archive
+func (p *Package) Close() error {
+	return p.archive.Close()
+}
+
+// MediaNames returns the media file names in sorted order
+func (p *Package) MediaNames() []string {
+	names := make([]string, 0, len(p.Media))
+	for name := range p.Media {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenMedia opens the content of a media file by its original name
+func (p *Package) OpenMedia(name string) (io.ReadCloser, error) {
+	file, ok := p.Media[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", types.ErrAnkiMediaNotFound, name)
+	}
+	return file.Open()
+}
+
+func (p *Package) load() error {
+	entries := make(map[string]*zip.File, len(p.archive.File))
+	for _, file := range p.archive.File {
+		entries[file.Name] = file
+	}
+
+	// Newer exports keep a stub collection.anki2 next to the real collection.anki21
+	collection := entries["collection.anki21"]
+	if collection == nil {
+		collection = entries["collection.anki2"]
+	}
+	if collection == nil {
+		// The zstd compressed format of Anki 2.1.50+
+		if entries["collection.anki21b"] != nil {
+			return types.ErrAnkiCompressed
+		}
+		return types.ErrAnkiNoCollection
+	}
+	if err := p.readCollection(collection); err != nil {
+		return err
+	}
+
+	if mediaIndex := entries["media"]; mediaIndex != nil {
+		names, err := readMediaIndex(mediaIndex)
+		if err != nil {
+			return err
+		}
+		for entry, name := range names {
+			// Media names come from the archive, never let them point outside the media directory
+			name = path.Base(filepath.ToSlash(name))
+			if file := entries[entry]; file != nil && name != "." && name != "/" {
+				p.Media[name] = file
+			}
+		}
+	}
+	return nil
+}
+
+func readMediaIndex(file *zip.File) (map[string]string, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	names := map[string]string{}
+	if err := json.NewDecoder(r).Decode(&names); err != nil {
+		return nil, fmt.Errorf("%w: media index: %w", types.ErrAnkiInvalid, err)
+	}
+	return names, nil
+}
+
+// readCollection copies the SQLite collection out of the archive and reads its notes
+func (p *Package) readCollection(file *zip.File) error {
+	tmp, err := os.CreateTemp("", "langlearner-apkg-*.anki2")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	r, err := file.Open()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	_, err = io.Copy(tmp, r)
+	r.Close()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	db, err := gorm.Open(sqlite.Open(tmp.Name()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	models, err := readModels(db)
+	if err != nil {
+		return err
+	}
+
+	var rows []struct {
+		ID   int64
+		GUID string
+		Mid  int64
+		Mod  int64
+		Tags string
+		Flds string
+	}
+	if err := db.Raw("SELECT id, guid, mid, mod, tags, flds FROM notes ORDER BY id").Scan(&rows).Error; err != nil {
+		return err
+	}
+	p.Notes = make([]Note, 0, len(rows))
+	for _, row := range rows {
+		model := models[row.Mid]
+		p.Notes = append(p.Notes, Note{
+			ID:         row.ID,
+			GUID:       row.GUID,
+			Model:      model.Name,
+			FieldNames: model.Fields,
+			Fields:     strings.Split(row.Flds, fieldSeparator),
+			Tags:       strings.Fields(row.Tags),
+			Modified:   row.Mod,
+		})
+	}
+	return nil
+}
+
+// model is the part of an Anki note type the importer needs
+type model struct {
+	Name   string
+	Fields []string
+}
+
+// readModels returns the note types by id, from col.models in the legacy
+// schema or from the notetypes and fields tables in schema 18
+func readModels(db *gorm.DB) (map[int64]model, error) {
+	models := map[int64]model{}
+
+	var raw string
+	if err := db.Raw("SELECT models FROM col").Row().Scan(&raw); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) != "" && raw != "{}" {
+		var legacy map[string]struct {
+			Name string `json:"name"`
+			Flds []struct {
+				Name string `json:"name"`
+				Ord  int    `json:"ord"`
+			} `json:"flds"`
+		}
+		if err := json.Unmarshal([]byte(raw), &legacy); err != nil {
+			return nil, fmt.Errorf("%w: note types: %w", types.ErrAnkiInvalid, err)
+		}
+		for id, m := range legacy {
+			mid, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				continue
+			}
+			sort.Slice(m.Flds, func(i, j int) bool { return m.Flds[i].Ord < m.Flds[j].Ord })
+			fields := make([]string, 0, len(m.Flds))
+			for _, field := range m.Flds {
+				fields = append(fields, field.Name)
+			}
+			models[mid] = model{Name: m.Name, Fields: fields}
+		}
+		return models, nil
+	}
+
+	if !db.Migrator().HasTable("notetypes") {
+		return models, nil
+	}
+	var notetypes []struct {
+		ID   int64
+		Name string
+	}
+	if err := db.Raw("SELECT id, name FROM notetypes").Scan(&notetypes).Error; err != nil {
+		return nil, err
+	}
+	for _, nt := range notetypes {
+		var fields []string
+		if err := db.Raw("SELECT name FROM fields WHERE ntid = ? ORDER BY ord", nt.ID).Scan(&fields).Error; err != nil {
+			return nil, err
+		}
+		models[nt.ID] = model{Name: nt.Name, Fields: fields}
+	}
+	return models, nil
+}
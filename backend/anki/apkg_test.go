@@ -0,0 +1,108 @@
+package anki
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"langlearner1/backend/types"
+)
+
+// writeTestPackage builds a legacy-schema .apkg with the given notes and media
+func writeTestPackage(t *testing.T, collectionName string, notes [][]string, media map[string]string) string {
+	dir := t.TempDir()
+	collectionPath := filepath.Join(dir, "collection")
+	db, err := gorm.Open(sqlite.Open(collectionPath), &gorm.Config{})
+	require.NoError(t, err)
+	models := `{"1600000000000": {"name": "Basic", "flds": [{"name": "Back", "ord": 1}, {"name": "Front", "ord": 0}]}}`
+	require.NoError(t, db.Exec("CREATE TABLE col (id integer primary key, models text, decks text)").Error)
+	require.NoError(t, db.Exec("INSERT INTO col (id, models, decks) VALUES (1, ?, '{}')", models).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE notes (id integer primary key, guid text, mid integer,
+		mod integer, usn integer, tags text, flds text, sfld text, csum integer, flags integer, data text)`).Error)
+	for i, note := range notes {
+		// note is guid, tags, fields...
+		flds := note[2]
+		for _, field := range note[3:] {
+			flds += fieldSeparator + field
+		}
+		require.NoError(t, db.Exec("INSERT INTO notes (id, guid, mid, mod, tags, flds) VALUES (?, ?, 1600000000000, 0, ?, ?)",
+			i+1, note[0], note[1], flds).Error)
+	}
+	sqlDB, _ := db.DB()
+	require.NoError(t, sqlDB.Close())
+
+	apkgPath := filepath.Join(dir, "deck.apkg")
+	out, err := os.Create(apkgPath)
+	require.NoError(t, err)
+	zw := zip.NewWriter(out)
+	w, err := zw.Create(collectionName)
+	require.NoError(t, err)
+	collection, err := os.ReadFile(collectionPath)
+	require.NoError(t, err)
+	_, err = w.Write(collection)
+	require.NoError(t, err)
+
+	index := map[string]string{}
+	i := 0
+	for name, content := range media {
+		entry := string(rune('0' + i))
+		index[entry] = name
+		w, err := zw.Create(entry)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+		i++
+	}
+	w, err = zw.Create("media")
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(w).Encode(index))
+	require.NoError(t, zw.Close())
+	require.NoError(t, out.Close())
+	return apkgPath
+}
+
+func TestOpen(t *testing.T) {
+	path := writeTestPackage(t, "collection.anki2", [][]string{
+		{"guid-1", " n5 greeting ", "こんにちは[sound:hello.mp3]", "Hello"},
+		{"guid-2", "", "ありがとう", "Thank you"},
+	}, map[string]string{"hello.mp3": "ID3", "../../evil.png": "PNG"})
+
+	pkg, err := Open(path)
+	require.NoError(t, err)
+	defer pkg.Close()
+
+	require.Len(t, pkg.Notes, 2)
+	assert.Equal(t, "guid-1", pkg.Notes[0].GUID)
+	assert.Equal(t, "Basic", pkg.Notes[0].Model)
+	assert.Equal(t, []string{"Front", "Back"}, pkg.Notes[0].FieldNames)
+	assert.Equal(t, []string{"こんにちは[sound:hello.mp3]", "Hello"}, pkg.Notes[0].Fields)
+	assert.Equal(t, "Hello", pkg.Notes[0].Field("back"))
+	assert.Equal(t, []string{"n5", "greeting"}, pkg.Notes[0].Tags)
+	assert.Empty(t, pkg.Notes[1].Tags)
+
+	assert.Equal(t, []string{"evil.png", "hello.mp3"}, pkg.MediaNames())
+	r, err := pkg.OpenMedia("hello.mp3")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "ID3", string(content))
+	_, err = pkg.OpenMedia("missing.mp3")
+	assert.ErrorIs(t, err, types.ErrAnkiMediaNotFound)
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	path := writeTestPackage(t, "collection.anki21b", nil, nil)
+	_, err := Open(path)
+	assert.Equal(t, types.ErrAnkiCompressed, err)
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.apkg"))
+	assert.Error(t, err)
+}
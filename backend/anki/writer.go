@@ -0,0 +1,365 @@
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"html"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// basicModelID and the deck id are fixed so repeated exports update the
+	// same note type and deck in Anki instead of creating copies
+	basicModelID = 1735689600000
+	defaultDeck  = "LangLearner"
+)
+
+var (
+	soundRef = regexp.MustCompile(`\[sound:([^\]]+)\]`)
+	imageRef = regexp.MustCompile(`(?i)<img[^>]*\ssrc\s*=\s*["']?([^"'\s>]+)`)
+	htmlTag  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Deck is the content of an .apkg file to write
+type Deck struct {
+	// Name of the deck in Anki, "::" separates subdecks
+	Name string
+	// Notes use the basic model, Fields holds the front and back
+	Notes []Note
+	// Media maps media file names as referenced by the notes to local files
+	Media map[string]string
+}
+
+// MediaReferences returns the media file names referenced by [sound:...] and <img src> in a field
+func MediaReferences(field string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, re := range []*regexp.Regexp{soundRef, imageRef} {
+		for _, match := range re.FindAllStringSubmatch(field, -1) {
+			name := html.UnescapeString(strings.TrimSpace(match[1]))
+			// Remote images are not media files
+			if name == "" || strings.Contains(name, "://") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ReplaceMedia returns field with every media file name referenced by
+// [sound:...] and <img src> replaced by the result of replace, which is called
+// with the name and whether it is a sound
+func ReplaceMedia(field string, replace func(name string, sound bool) string) string {
+	for _, re := range []*regexp.Regexp{soundRef, imageRef} {
+		sound := re == soundRef
+		var b strings.Builder
+		last := 0
+		for _, match := range re.FindAllStringSubmatchIndex(field, -1) {
+			name := html.UnescapeString(strings.TrimSpace(field[match[2]:match[3]]))
+			if name == "" || strings.Contains(name, "://") {
+				continue
+			}
+			if replaced := replace(name, sound); replaced != name {
+				b.WriteString(field[last:match[2]])
+				b.WriteString(replaced)
+				last = match[3]
+			}
+		}
+		b.WriteString(field[last:])
+		field = b.String()
+	}
+	return field
+}
+
+// Write creates an .apkg file in the legacy collection.anki2 format, which
+// every Anki version including AnkiDroid can import
+func Write(apkgPath string, deck Deck) error {
+	tmp, err := os.CreateTemp("", "langlearner-apkg-*.anki2")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := writeCollection(tmp.Name(), deck); err != nil {
+		return err
+	}
+
+	out, err := os.Create(apkgPath)
+	if err != nil {
+		return err
+	}
+	if err := writeArchive(out, tmp.Name(), deck.Media); err != nil {
+		out.Close()
+		os.Remove(apkgPath)
+		return err
+	}
+	return out.Close()
+}
+
+func writeArchive(w io.Writer, collectionPath string, media map[string]string) error {
+	zw := zip.NewWriter(w)
+	if err := addFile(zw, "collection.anki2", collectionPath); err != nil {
+		return err
+	}
+
+	// Media files are stored as numbered entries, the media index maps them back to their names
+	index := make(map[string]string, len(media))
+	for i, name := range sortedKeys(media) {
+		entry := strconv.Itoa(i)
+		if err := addFile(zw, entry, media[name]); err != nil {
+			return err
+		}
+		index[entry] = path.Base(filepath.ToSlash(name))
+	}
+	mediaIndex, err := zw.Create("media")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(mediaIndex).Encode(index); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func addFile(zw *zip.Writer, name string, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+func writeCollection(collectionPath string, deck Deck) error {
+	db, err := gorm.Open(sqlite.Open(collectionPath), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range collectionSchema {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		name := deck.Name
+		if strings.TrimSpace(name) == "" {
+			name = defaultDeck
+		}
+		deckID := deckIDFor(name)
+		if err := insertCol(tx, now, deckID, name); err != nil {
+			return err
+		}
+
+		// Anki ids are millisecond timestamps and must be unique per table
+		baseID := now.UnixMilli()
+		for i, note := range deck.Notes {
+			front, back := noteSide(note, 0), noteSide(note, 1)
+			sortField := stripHTML(front)
+			modified := note.Modified
+			if modified == 0 {
+				modified = now.Unix()
+			}
+			noteID := baseID + int64(i)
+			if err := tx.Exec(`INSERT INTO notes (id, guid, mid, mod, usn, tags, flds, sfld, csum, flags, data)
+				VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`,
+				noteID, note.GUID, basicModelID, modified, joinTags(note.Tags),
+				front+fieldSeparator+back, sortField, checksum(sortField)).Error; err != nil {
+				return err
+			}
+			// New cards, due is the position in the new card queue
+			if err := tx.Exec(`INSERT INTO cards (id, nid, did, ord, mod, usn, type, queue, due, ivl,
+				factor, reps, lapses, left, odue, odid, flags, data)
+				VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')`,
+				noteID, noteID, deckID, modified, i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func noteSide(note Note, index int) string {
+	if index < len(note.Fields) {
+		return note.Fields[index]
+	}
+	return ""
+}
+
+// joinTags formats tags the way Anki stores them, space separated with surrounding spaces
+func joinTags(tags []string) string {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		// Anki tags cannot contain spaces
+		if tag = strings.Join(strings.Fields(tag), "_"); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	if len(cleaned) == 0 {
+		return ""
+	}
+	return " " + strings.Join(cleaned, " ") + " "
+}
+
+func stripHTML(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, "")))
+}
+
+// checksum is the first 8 hex digits of the sha1 of the sort field, used by Anki to find duplicates
+func checksum(s string) int64 {
+	sum := sha1.Sum([]byte(s))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+// deckIDFor derives a stable deck id from the deck name
+func deckIDFor(name string) int64 {
+	sum := sha1.Sum([]byte(name))
+	return basicModelID + 1 + int64(binary.BigEndian.Uint32(sum[:4])%1000000)
+}
+
+func insertCol(tx *gorm.DB, now time.Time, deckID int64, deckName string) error {
+	mod := now.UnixMilli()
+	models := map[string]any{
+		strconv.FormatInt(basicModelID, 10): map[string]any{
+			"id":    basicModelID,
+			"name":  "LangLearner Basic",
+			"type":  0,
+			"mod":   now.Unix(),
+			"usn":   -1,
+			"sortf": 0,
+			"did":   deckID,
+			"tmpls": []map[string]any{{
+				"name":  "Card 1",
+				"ord":   0,
+				"qfmt":  "{{Front}}",
+				"afmt":  "{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}",
+				"did":   nil,
+				"bqfmt": "",
+				"bafmt": "",
+			}},
+			"flds": []map[string]any{
+				{"name": "Front", "ord": 0, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}},
+				{"name": "Back", "ord": 1, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}},
+			},
+			"css":       ".card {\n font-family: arial;\n font-size: 20px;\n text-align: center;\n color: black;\n background-color: white;\n}\n",
+			"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+			"tags":      []string{},
+			"vers":      []any{},
+			"req":       []any{[]any{0, "any", []int{0}}},
+		},
+	}
+	decks := map[string]any{
+		"1":                           newDeckJSON(1, "Default", now),
+		strconv.FormatInt(deckID, 10): newDeckJSON(deckID, deckName, now),
+	}
+	dconf := map[string]any{
+		"1": map[string]any{
+			"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true,
+			"timer": 0, "replayq": true, "dyn": false,
+			"new": map[string]any{
+				"bury": true, "delays": []int{1, 10}, "initialFactor": 2500,
+				"ints": []int{1, 4, 7}, "order": 1, "perDay": 20, "separate": true,
+			},
+			"rev": map[string]any{
+				"bury": true, "ease4": 1.3, "fuzz": 0.05, "ivlFct": 1,
+				"maxIvl": 36500, "minSpace": 1, "perDay": 100,
+			},
+			"lapse": map[string]any{
+				"delays": []int{10}, "leechAction": 0, "leechFails": 8, "minInt": 1, "mult": 0,
+			},
+		},
+	}
+	conf := map[string]any{
+		"activeDecks": []int64{1}, "curDeck": 1, "newSpread": 0, "collapseTime": 1200,
+		"timeLim": 0, "estTimes": true, "dueCounts": true, "curModel": nil,
+		"nextPos": 1, "sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+	}
+
+	values := make([]string, 0, 4)
+	for _, v := range []any{conf, models, decks, dconf} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		values = append(values, string(b))
+	}
+	return tx.Exec(`INSERT INTO col (id, crt, mod, scm, ver, dty, usn, ls, conf, models, decks, dconf, tags)
+		VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		now.Unix(), mod, mod, values[0], values[1], values[2], values[3]).Error
+}
+
+func newDeckJSON(id int64, name string, now time.Time) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "mod": now.Unix(), "usn": -1, "desc": "", "dyn": 0, "conf": 1,
+		"collapsed": false, "extendNew": 10, "extendRev": 50,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+// collectionSchema is the schema 11 layout of collection.anki2
+var collectionSchema = []string{
+	`CREATE TABLE col (
+		id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+		ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+		conf text not null, models text not null, decks text not null, dconf text not null, tags text not null)`,
+	`CREATE TABLE notes (
+		id integer primary key, guid text not null, mid integer not null, mod integer not null,
+		usn integer not null, tags text not null, flds text not null, sfld integer not null,
+		csum integer not null, flags integer not null, data text not null)`,
+	`CREATE TABLE cards (
+		id integer primary key, nid integer not null, did integer not null, ord integer not null,
+		mod integer not null, usn integer not null, type integer not null, queue integer not null,
+		due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+		lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+		flags integer not null, data text not null)`,
+	`CREATE TABLE revlog (
+		id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+		ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+		type integer not null)`,
+	`CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null)`,
+	`CREATE INDEX ix_notes_usn on notes (usn)`,
+	`CREATE INDEX ix_cards_usn on cards (usn)`,
+	`CREATE INDEX ix_revlog_usn on revlog (usn)`,
+	`CREATE INDEX ix_cards_nid on cards (nid)`,
+	`CREATE INDEX ix_cards_sched on cards (did, queue, due)`,
+	`CREATE INDEX ix_revlog_cid on revlog (cid)`,
+	`CREATE INDEX ix_notes_csum on notes (csum)`,
+}
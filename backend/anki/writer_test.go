@@ -0,0 +1,130 @@
+package anki
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMediaReferences(t *testing.T) {
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{"plain text", nil},
+		{"いぬ[sound:inu.mp3]", []string{"inu.mp3"}},
+		{`<img src="dog.png"> [sound:inu.mp3] <IMG class="x" src='dog.png'>`, []string{"inu.mp3", "dog.png"}},
+		{`<img src=cat&amp;dog.jpg>`, []string{"cat&dog.jpg"}},
+		{`<img src="https://example.com/dog.png">`, nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MediaReferences(tt.field), tt.field)
+	}
+}
+
+func TestReplaceMedia(t *testing.T) {
+	replace := func(name string, sound bool) string {
+		if name == "keep.png" {
+			return name
+		}
+		if sound {
+			return "/media/" + name
+		}
+		return "/img/" + name
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"plain text", "plain text"},
+		{"いぬ[sound:inu.mp3]", "いぬ[sound:/media/inu.mp3]"},
+		{`<img src="dog.png"> [sound:inu.mp3] <IMG class="x" src='keep.png'>`, `<img src="/img/dog.png"> [sound:/media/inu.mp3] <IMG class="x" src='keep.png'>`},
+		{`<img src=cat&amp;dog.jpg>`, `<img src=/img/cat&dog.jpg>`},
+		{`<img src="https://example.com/dog.png">`, `<img src="https://example.com/dog.png">`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ReplaceMedia(tt.field, replace), tt.field)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "inu.mp3")
+	require.NoError(t, os.WriteFile(audio, []byte("ID3"), 0644))
+
+	apkgPath := filepath.Join(dir, "out.apkg")
+	err := Write(apkgPath, Deck{
+		Name: "Japanese::N5",
+		Notes: []Note{
+			{GUID: "g1", Fields: []string{"<b>いぬ</b>[sound:inu.mp3]", "dog"}, Tags: []string{"n5", "animal words"}},
+			{GUID: "g2", Fields: []string{"ねこ"}},
+		},
+		Media: map[string]string{"inu.mp3": audio},
+	})
+	require.NoError(t, err)
+
+	pkg, err := Open(apkgPath)
+	require.NoError(t, err)
+	defer pkg.Close()
+	require.Len(t, pkg.Notes, 2)
+	assert.Equal(t, "g1", pkg.Notes[0].GUID)
+	assert.Equal(t, []string{"Front", "Back"}, pkg.Notes[0].FieldNames)
+	assert.Equal(t, []string{"<b>いぬ</b>[sound:inu.mp3]", "dog"}, pkg.Notes[0].Fields)
+	assert.Equal(t, []string{"n5", "animal_words"}, pkg.Notes[0].Tags)
+	assert.Equal(t, []string{"ねこ", ""}, pkg.Notes[1].Fields)
+
+	assert.Equal(t, []string{"inu.mp3"}, pkg.MediaNames())
+	r, err := pkg.OpenMedia("inu.mp3")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "ID3", string(content))
+
+	// Every note needs a card in the deck or Anki imports nothing
+	db := openCollection(t, pkg.archive)
+	var cards []struct {
+		Nid int64
+		Did int64
+	}
+	require.NoError(t, db.Raw("SELECT nid, did FROM cards ORDER BY due").Scan(&cards).Error)
+	require.Len(t, cards, 2)
+	assert.Equal(t, pkg.Notes[0].ID, cards[0].Nid)
+	assert.Equal(t, deckIDFor("Japanese::N5"), cards[0].Did)
+	var sortField string
+	require.NoError(t, db.Raw("SELECT sfld FROM notes WHERE guid = 'g1'").Row().Scan(&sortField))
+	assert.Equal(t, "いぬ[sound:inu.mp3]", sortField)
+}
+
+func openCollection(t *testing.T, archive *zip.ReadCloser) *gorm.DB {
+	var collection *zip.File
+	for _, file := range archive.File {
+		if file.Name == "collection.anki2" {
+			collection = file
+		}
+	}
+	require.NotNil(t, collection)
+	r, err := collection.Open()
+	require.NoError(t, err)
+	defer r.Close()
+	dst := filepath.Join(t.TempDir(), "collection.anki2")
+	out, err := os.Create(dst)
+	require.NoError(t, err)
+	_, err = io.Copy(out, r)
+	require.NoError(t, err)
+	require.NoError(t, out.Close())
+
+	db, err := gorm.Open(sqlite.Open(dst), &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
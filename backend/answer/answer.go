@@ -0,0 +1,155 @@
+// Package answer grades typed recall attempts. Answers are compared with the
+// expected text after normalizing width, kana, case, punctuation and spacing,
+// then scored by edit distance.
+package answer
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+	"langlearner1/backend/reading"
+	"langlearner1/backend/srs"
+	"langlearner1/backend/types"
+)
+
+// DefaultPass is the score threshold of a Checker created by NewChecker
+const DefaultPass = 0.8
+
+// Checker compares typed answers with the expected text of a note
+type Checker struct {
+	// Pass is the lowest score that counts as recalled. An exact match is
+	// graded Good, a match from Pass up is Hard and anything lower is Again.
+	Pass float64
+}
+
+// NewChecker creates a Checker with the default thresholds
+func NewChecker() *Checker {
+	return &Checker{Pass: DefaultPass}
+}
+
+// Check compares answer with expected
+func (c *Checker) Check(expected string, answer string) *types.AnswerCheck {
+	want := []rune(Normalize(expected))
+	got := []rune(Normalize(answer))
+	distance, diff := compare(want, got)
+
+	score := 1.0
+	if longest := max(len(want), len(got)); longest > 0 {
+		score = 1 - float64(distance)/float64(longest)
+	}
+	grade := srs.Again
+	switch {
+	case len(got) == 0:
+		// An empty answer is never right, even for an empty expected text
+		score = 0
+	case distance == 0:
+		grade = srs.Good
+	case score >= c.Pass:
+		grade = srs.Hard
+	}
+	return &types.AnswerCheck{
+		Expected: string(want),
+		Answer:   string(got),
+		Distance: distance,
+		Score:    score,
+		Correct:  grade != srs.Again,
+		Grade:    int(grade),
+		Diff:     diff,
+	}
+}
+
+// Normalize folds the differences between answers that do not matter for
+// recall: full and half width forms, katakana and hiragana, letter case,
+// punctuation and runs of whitespace
+func Normalize(text string) string {
+	text = reading.ToHiragana(width.Fold.String(text))
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// compare returns the Levenshtein distance from want to got and the diff of
+// the two, with substitutions shown as the missing text followed by the extra text
+func compare(want []rune, got []rune) (int, []types.AnswerDiff) {
+	// cost[i][j] is the distance between want[:i] and got[:j]
+	cost := make([][]int, len(want)+1)
+	for i := range cost {
+		cost[i] = make([]int, len(got)+1)
+		cost[i][0] = i
+	}
+	for j := range cost[0] {
+		cost[0][j] = j
+	}
+	for i := 1; i <= len(want); i++ {
+		for j := 1; j <= len(got); j++ {
+			substitute := cost[i-1][j-1]
+			if want[i-1] != got[j-1] {
+				substitute++
+			}
+			cost[i][j] = min(substitute, cost[i-1][j]+1, cost[i][j-1]+1)
+		}
+	}
+
+	// Walk back from the end, collecting operations in reverse
+	var ops []types.AnswerDiff
+	i, j := len(want), len(got)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && want[i-1] == got[j-1] && cost[i][j] == cost[i-1][j-1]:
+			ops = append(ops, types.AnswerDiff{Op: types.DiffEqual, Text: string(want[i-1])})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && cost[i][j] == cost[i-1][j-1]+1:
+			// Reversed below, so the extra text ends up after the missing text
+			ops = append(ops, types.AnswerDiff{Op: types.DiffExtra, Text: string(got[j-1])})
+			ops = append(ops, types.AnswerDiff{Op: types.DiffMissing, Text: string(want[i-1])})
+			i, j = i-1, j-1
+		case i > 0 && cost[i][j] == cost[i-1][j]+1:
+			ops = append(ops, types.AnswerDiff{Op: types.DiffMissing, Text: string(want[i-1])})
+			i--
+		default:
+			ops = append(ops, types.AnswerDiff{Op: types.DiffExtra, Text: string(got[j-1])})
+			j--
+		}
+	}
+
+	diff := []types.AnswerDiff{}
+	for k := len(ops) - 1; k >= 0; k-- {
+		diff = appendDiff(diff, ops[k])
+	}
+	return cost[len(want)][len(got)], diff
+}
+
+// appendDiff adds op to diff, merging it into the run before it when the
+// operation is the same. Between two equal runs the missing text comes before
+// the extra text, so "ab" for "xy" is missing "ab" and extra "xy".
+func appendDiff(diff []types.AnswerDiff, op types.AnswerDiff) []types.AnswerDiff {
+	n := len(diff)
+	if n > 0 && diff[n-1].Op == op.Op {
+		diff[n-1].Text += op.Text
+		return diff
+	}
+	if n > 0 && op.Op == types.DiffMissing && diff[n-1].Op == types.DiffExtra {
+		if n > 1 && diff[n-2].Op == types.DiffMissing {
+			diff[n-2].Text += op.Text
+			return diff
+		}
+		extra := diff[n-1]
+		diff[n-1] = op
+		return append(diff, extra)
+	}
+	return append(diff, op)
+}
@@ -0,0 +1,94 @@
+package answer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"langlearner1/backend/srs"
+	"langlearner1/backend/types"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"コンニチハ":              "こんにちは",
+		"ｺﾝﾆﾁﾊ":              "こんにちは",
+		"ｈｅｌｌｏ　ＷＯＲＬＤ":        "hello world",
+		"  Hello,   world! ": "hello world",
+		"「いぬ」です。":            "いぬです",
+		"コーヒー":               "こーひー",
+		"１２３":                "123",
+	}
+	for text, normalized := range tests {
+		assert.Equal(t, normalized, Normalize(text), text)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	checker := NewChecker()
+
+	tests := []struct {
+		name     string
+		expected string
+		answer   string
+		distance int
+		grade    srs.Grade
+		diff     []types.AnswerDiff
+	}{
+		{
+			name:     "exact after normalizing",
+			expected: "こんにちは。",
+			answer:   "コンニチハ",
+			grade:    srs.Good,
+			diff:     []types.AnswerDiff{{Op: types.DiffEqual, Text: "こんにちは"}},
+		},
+		{
+			name:     "close enough",
+			expected: "thank you very much",
+			answer:   "thank you vary much",
+			distance: 1,
+			grade:    srs.Hard,
+			diff: []types.AnswerDiff{
+				{Op: types.DiffEqual, Text: "thank you v"},
+				{Op: types.DiffMissing, Text: "e"},
+				{Op: types.DiffExtra, Text: "a"},
+				{Op: types.DiffEqual, Text: "ry much"},
+			},
+		},
+		{
+			name:     "kana for kanji",
+			expected: "犬が走る",
+			answer:   "犬がはしる",
+			distance: 2,
+			grade:    srs.Again,
+			diff: []types.AnswerDiff{
+				{Op: types.DiffEqual, Text: "犬が"},
+				{Op: types.DiffMissing, Text: "走"},
+				{Op: types.DiffExtra, Text: "はし"},
+				{Op: types.DiffEqual, Text: "る"},
+			},
+		},
+		{
+			name:     "empty answer",
+			expected: "いぬ",
+			answer:   " 。",
+			distance: 2,
+			grade:    srs.Again,
+			diff:     []types.AnswerDiff{{Op: types.DiffMissing, Text: "いぬ"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(tt.expected, tt.answer)
+			assert.Equal(t, tt.distance, result.Distance)
+			assert.Equal(t, int(tt.grade), result.Grade)
+			assert.Equal(t, tt.grade != srs.Again, result.Correct)
+			assert.Equal(t, tt.diff, result.Diff)
+		})
+	}
+
+	result := checker.Check("kitten", "sitting")
+	assert.InDelta(t, 1-3.0/7, result.Score, 1e-9)
+	result = checker.Check("", "")
+	assert.Equal(t, 0.0, result.Score)
+	assert.False(t, result.Correct)
+}
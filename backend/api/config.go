@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DefaultAddress only accepts connections from the local machine
+const DefaultAddress = "127.0.0.1:7788"
+
+// Config configures the REST API server
+type Config struct {
+	// Address is the host:port to listen on, DefaultAddress when empty
+	Address string `json:"address"`
+	// Token, when set, must be sent by clients as "Authorization: Bearer <token>"
+	Token string `json:"token"`
+	// AllowOrigin is sent as Access-Control-Allow-Origin so a browser app on
+	// another origin can call the API, such as "*" or "http://localhost:5173"
+	AllowOrigin string `json:"allow_origin"`
+}
+
+// Load reads the server config from a JSON file, a missing file disables the server and returns nil
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("api: invalid config %s: %w", path, err)
+	}
+	if config.Address == "" {
+		config.Address = DefaultAddress
+	}
+	return config, nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+
+	"langlearner1/backend/types"
+)
+
+// errorStatus maps the errors services report by their JSResp code to HTTP
+// status codes, other codes are internal errors
+var errorStatus = []struct {
+	err    *types.Error
+	status int
+}{
+	{types.ErrTagNotFound, http.StatusNotFound},
+	{types.ErrCategoryNotFound, http.StatusNotFound},
+	{types.ErrDeckNotFound, http.StatusNotFound},
+	{types.ErrNoteNotFound, http.StatusNotFound},
+	{types.ErrSessionNotFound, http.StatusNotFound},
+	{types.ErrAssetNotFound, http.StatusNotFound},
+	{types.ErrJobNotFound, http.StatusNotFound},
+	{types.ErrProfileNotFound, http.StatusNotFound},
+
+	{types.ErrTagExists, http.StatusConflict},
+	{types.ErrTagInUse, http.StatusConflict},
+	{types.ErrCategoryExists, http.StatusConflict},
+	{types.ErrCategoryInUse, http.StatusConflict},
+	{types.ErrDeckExists, http.StatusConflict},
+	{types.ErrDeckInUse, http.StatusConflict},
+	{types.ErrNoteExists, http.StatusConflict},
+	{types.ErrNoteInUse, http.StatusConflict},
+	{types.ErrSessionFinished, http.StatusConflict},
+	{types.ErrUnexpectedCard, http.StatusConflict},
+	{types.ErrJobFinished, http.StatusConflict},
+	{types.ErrProfileExists, http.StatusConflict},
+	{types.ErrProfileActive, http.StatusConflict},
+
+	{types.ErrTagNameEmpty, http.StatusBadRequest},
+	{types.ErrCategoryNameEmpty, http.StatusBadRequest},
+	{types.ErrCategoryCycle, http.StatusBadRequest},
+	{types.ErrDeckNameEmpty, http.StatusBadRequest},
+	{types.ErrInvalidRequest, http.StatusBadRequest},
+	{types.ErrInvalidPageNum, http.StatusBadRequest},
+	{types.ErrInvalidCursor, http.StatusBadRequest},
+	{types.ErrNoteNameEmpty, http.StatusBadRequest},
+	{types.ErrNoteFrontEmpty, http.StatusBadRequest},
+	{types.ErrSessionEmpty, http.StatusBadRequest},
+	{types.ErrInvalidOutcome, http.StatusBadRequest},
+	{types.ErrInvalidGrade, http.StatusBadRequest},
+	{types.ErrUnknownAlgorithm, http.StatusBadRequest},
+	{types.ErrInvalidAssetRole, http.StatusBadRequest},
+	{types.ErrAssetType, http.StatusBadRequest},
+	{types.ErrInvalidLanguage, http.StatusBadRequest},
+	{types.ErrTextEmpty, http.StatusBadRequest},
+	{types.ErrInvalidSide, http.StatusBadRequest},
+	{types.ErrSpeechTextEmpty, http.StatusBadRequest},
+	{types.ErrAnswerTextEmpty, http.StatusBadRequest},
+	{types.ErrUnknownJobKind, http.StatusBadRequest},
+	{types.ErrProfileNameEmpty, http.StatusBadRequest},
+	{types.ErrInvalidDelimiter, http.StatusBadRequest},
+	{types.ErrUnknownColumn, http.StatusBadRequest},
+	{types.ErrUnknownField, http.StatusBadRequest},
+	{types.ErrUnknownEncoding, http.StatusBadRequest},
+	{types.ErrAnkiNoCollection, http.StatusBadRequest},
+	{types.ErrAnkiCompressed, http.StatusBadRequest},
+	{types.ErrAnkiInvalid, http.StatusBadRequest},
+	{types.ErrAnkiMediaNotFound, http.StatusBadRequest},
+
+	{types.ErrInvalidToken, http.StatusUnauthorized},
+
+	{types.ErrNoTranslator, http.StatusServiceUnavailable},
+	{types.ErrNoSynthesizer, http.StatusServiceUnavailable},
+	{types.ErrProviderEndpoint, http.StatusServiceUnavailable},
+	{types.ErrProviderCommand, http.StatusServiceUnavailable},
+	{types.ErrProviderConfig, http.StatusServiceUnavailable},
+
+	{types.ErrTranslateFailed, http.StatusBadGateway},
+	{types.ErrSpeechFailed, http.StatusBadGateway},
+}
+
+// statusOf returns the HTTP status of the error code reported by a service
+func statusOf(code string) int {
+	for _, e := range errorStatus {
+		if e.err.Code == code {
+			return e.status
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"net/http"
+
+	"langlearner1/backend/types"
+)
+
+// namePayload is the body of the requests that only carry a name
+type namePayload struct {
+	Name string `json:"name"`
+}
+
+func (h *handler) listTags(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	page, pageSize := q.Int("page"), q.Int("page_size")
+	if badRequest(w, q.err) {
+		return
+	}
+	respond(w, h.Tags.List(page, pageSize, q.String("keyword")), http.StatusOK)
+}
+
+func (h *handler) createTag(w http.ResponseWriter, r *http.Request) {
+	var payload namePayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Tags.Create(payload.Name), http.StatusCreated)
+}
+
+func (h *handler) updateTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	var payload namePayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Tags.Update(id, payload.Name), http.StatusOK)
+}
+
+func (h *handler) deleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	respondDeleted(w, h.Tags.Delete(id), "message.tag_deleted")
+}
+
+// listNotes pages through the notes matching the filter parameters, by page
+// number or, when the cursor parameter is given, by cursor
+func (h *handler) listNotes(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	filter := types.NoteFilter{
+		Keyword:      q.String("keyword"),
+		DeckID:       q.Int("deck_id"),
+		TagIDs:       q.IDs("tag_ids"),
+		MatchAllTags: q.Bool("match_all_tags"),
+		CategoryID:   q.Int("category_id"),
+	}
+	page, pageSize := q.Int("page"), q.Int("page_size")
+	if badRequest(w, q.err) {
+		return
+	}
+	if q.values.Has("cursor") {
+		respond(w, h.Notes.ListByCursor(q.String("cursor"), pageSize, filter), http.StatusOK)
+		return
+	}
+	respond(w, h.Notes.List(page, pageSize, filter), http.StatusOK)
+}
+
+func (h *handler) searchNotes(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	limit := q.Int("limit")
+	if badRequest(w, q.err) {
+		return
+	}
+	respond(w, h.Notes.Search(q.String("q"), limit), http.StatusOK)
+}
+
+func (h *handler) getNote(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	respond(w, h.Notes.Get(id), http.StatusOK)
+}
+
+func (h *handler) createNote(w http.ResponseWriter, r *http.Request) {
+	var payload types.NotePayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Notes.Create(payload), http.StatusCreated)
+}
+
+func (h *handler) updateNote(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	var payload types.NotePayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Notes.Update(id, payload), http.StatusOK)
+}
+
+func (h *handler) deleteNote(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	respondDeleted(w, h.Notes.Delete(id), "message.note_deleted")
+}
+
+// setNoteTags replaces the tags of a note with the tag_ids of the body
+func (h *handler) setNoteTags(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	var payload struct {
+		TagIDs []int `json:"tag_ids"`
+	}
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Notes.SetTags(id, payload.TagIDs), http.StatusOK)
+}
+
+func (h *handler) noteReviews(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	q := newQuery(r)
+	limit := q.Int("limit")
+	if badRequest(w, q.err) {
+		return
+	}
+	respond(w, h.Reviews.History(id, limit), http.StatusOK)
+}
+
+func (h *handler) listDecks(w http.ResponseWriter, r *http.Request) {
+	respond(w, h.Notes.ListDecks(), http.StatusOK)
+}
+
+func (h *handler) createDeck(w http.ResponseWriter, r *http.Request) {
+	var payload types.DeckPayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Notes.CreateDeck(payload), http.StatusCreated)
+}
+
+func (h *handler) updateDeck(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	var payload types.DeckPayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Notes.UpdateDeck(id, payload), http.StatusOK)
+}
+
+func (h *handler) deleteDeck(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	respondDeleted(w, h.Notes.DeleteDeck(id), "message.deck_deleted")
+}
+
+// categoryPayload is the body of the requests that create or change a
+// category, parent_id 0 makes it a top level category
+type categoryPayload struct {
+	Name     string `json:"name"`
+	ParentID int    `json:"parent_id"`
+}
+
+func (h *handler) listCategories(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	page, pageSize := q.Int("page"), q.Int("page_size")
+	if badRequest(w, q.err) {
+		return
+	}
+	respond(w, h.Categories.List(page, pageSize, q.String("keyword")), http.StatusOK)
+}
+
+func (h *handler) categoryTree(w http.ResponseWriter, r *http.Request) {
+	respond(w, h.Categories.Tree(), http.StatusOK)
+}
+
+func (h *handler) createCategory(w http.ResponseWriter, r *http.Request) {
+	var payload categoryPayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Categories.Create(payload.Name, payload.ParentID), http.StatusCreated)
+}
+
+func (h *handler) updateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	var payload categoryPayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Categories.Update(id, payload.Name, payload.ParentID), http.StatusOK)
+}
+
+func (h *handler) deleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if badRequest(w, err) {
+		return
+	}
+	respondDeleted(w, h.Categories.Delete(id), "message.category_deleted")
+}
+
+func (h *handler) dueNotes(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	limit := q.Int("limit")
+	if badRequest(w, q.err) {
+		return
+	}
+	respond(w, h.Reviews.GetDueNotes(limit), http.StatusOK)
+}
+
+// submitReview records a review graded by hand and returns the next state
+func (h *handler) submitReview(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		NoteID int `json:"note_id"`
+		Grade  int `json:"grade"`
+	}
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Reviews.SubmitReview(payload.NoteID, payload.Grade), http.StatusCreated)
+}
+
+// checkAnswer grades a typed answer and records it as a review
+func (h *handler) checkAnswer(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		NoteID int    `json:"note_id"`
+		Side   string `json:"side"`
+		Answer string `json:"answer"`
+	}
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Reviews.CheckAnswer(payload.NoteID, payload.Side, payload.Answer), http.StatusCreated)
+}
+
+func (h *handler) reviewStats(w http.ResponseWriter, r *http.Request) {
+	respond(w, h.Reviews.Stats(), http.StatusOK)
+}
+
+func (h *handler) algorithm(w http.ResponseWriter, r *http.Request) {
+	respond(w, h.Reviews.Algorithm(), http.StatusOK)
+}
+
+func (h *handler) setAlgorithm(w http.ResponseWriter, r *http.Request) {
+	var payload namePayload
+	if badRequest(w, decode(r, &payload)) {
+		return
+	}
+	respond(w, h.Reviews.SetAlgorithm(payload.Name), http.StatusOK)
+}
@@ -0,0 +1,266 @@
+// Package api serves the tags, notes, categories and reviews of the app as a
+// JSON REST API, so the app can be scripted or used from a companion app. It
+// calls the same services the Wails frontend is bound to.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/types"
+)
+
+// Services are the services the API exposes
+type Services struct {
+	Tags       types.TagServiceIf
+	Notes      types.NoteServiceIf
+	Categories types.CategoryServiceIf
+	Reviews    types.ReviewServiceIf
+}
+
+// NewHandler returns the handler of the REST resources under /api/
+func NewHandler(services Services, config Config) http.Handler {
+	h := &handler{services}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/tags", h.listTags)
+	mux.HandleFunc("POST /api/tags", h.createTag)
+	mux.HandleFunc("PUT /api/tags/{id}", h.updateTag)
+	mux.HandleFunc("DELETE /api/tags/{id}", h.deleteTag)
+
+	mux.HandleFunc("GET /api/notes", h.listNotes)
+	mux.HandleFunc("GET /api/notes/search", h.searchNotes)
+	mux.HandleFunc("GET /api/notes/{id}", h.getNote)
+	mux.HandleFunc("POST /api/notes", h.createNote)
+	mux.HandleFunc("PUT /api/notes/{id}", h.updateNote)
+	mux.HandleFunc("DELETE /api/notes/{id}", h.deleteNote)
+	mux.HandleFunc("PUT /api/notes/{id}/tags", h.setNoteTags)
+	mux.HandleFunc("GET /api/notes/{id}/reviews", h.noteReviews)
+
+	mux.HandleFunc("GET /api/decks", h.listDecks)
+	mux.HandleFunc("POST /api/decks", h.createDeck)
+	mux.HandleFunc("PUT /api/decks/{id}", h.updateDeck)
+	mux.HandleFunc("DELETE /api/decks/{id}", h.deleteDeck)
+
+	mux.HandleFunc("GET /api/categories", h.listCategories)
+	mux.HandleFunc("GET /api/categories/tree", h.categoryTree)
+	mux.HandleFunc("POST /api/categories", h.createCategory)
+	mux.HandleFunc("PUT /api/categories/{id}", h.updateCategory)
+	mux.HandleFunc("DELETE /api/categories/{id}", h.deleteCategory)
+
+	mux.HandleFunc("GET /api/reviews/due", h.dueNotes)
+	mux.HandleFunc("POST /api/reviews", h.submitReview)
+	mux.HandleFunc("POST /api/reviews/check", h.checkAnswer)
+	mux.HandleFunc("GET /api/reviews/stats", h.reviewStats)
+	mux.HandleFunc("GET /api/reviews/algorithm", h.algorithm)
+	mux.HandleFunc("PUT /api/reviews/algorithm", h.setAlgorithm)
+	return withAccess(config, mux)
+}
+
+// withAccess checks the bearer token of requests and answers CORS preflight requests
+func withAccess(config Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if config.AllowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", config.AllowOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		if config.Token != "" {
+			token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(config.Token)) != 1 {
+				var resp types.JSResp
+				resp.SetError(types.ErrInvalidToken)
+				writeError(w, resp)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Server runs the REST API in the background of the app
+type Server struct {
+	http     *http.Server
+	listener net.Listener
+}
+
+// NewServer creates a server for the services listening on config.Address
+func NewServer(config Config, services Services) *Server {
+	return &Server{http: &http.Server{
+		Addr:              config.Address,
+		Handler:           NewHandler(services, config),
+		ReadHeaderTimeout: 10 * time.Second,
+	}}
+}
+
+// Start listens on the address and serves requests until Stop is called
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("api: %w", err)
+	}
+	s.listener = listener
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			println("Error:", err.Error())
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on, which tells the port
+// chosen for an address ending in ":0"
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.http.Addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop waits for the requests in progress to finish and stops the server
+func (s *Server) Stop(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// handler implements the REST resources on top of the services
+type handler struct {
+	Services
+}
+
+// respond writes the data of a successful service response with status, or
+// the error of a failed one with the status its code maps to
+func respond(w http.ResponseWriter, resp types.JSResp, status int) {
+	if resp.Success != 1 {
+		writeError(w, resp)
+		return
+	}
+	writeJSON(w, status, resp.Data)
+}
+
+// respondDeleted writes the message of key for a successful delete
+func respondDeleted(w http.ResponseWriter, resp types.JSResp, key string) {
+	if resp.Success != 1 {
+		writeError(w, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": i18n.T(key)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		println("Error:", err.Error())
+	}
+}
+
+// errorBody is the body of a failed request
+type errorBody struct {
+	Error   string              `json:"error"`
+	Code    string              `json:"code"`
+	Key     string              `json:"key"`
+	Details []types.ErrorDetail `json:"details,omitempty"`
+}
+
+// writeError writes the error of a failed service response
+func writeError(w http.ResponseWriter, resp types.JSResp) {
+	writeJSON(w, statusOf(resp.Code), errorBody{Error: resp.Msg, Code: resp.Code, Key: resp.Key, Details: resp.Details})
+}
+
+// pathID returns the {id} of the request path
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// decode reads the JSON body of a request into v
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// query reads query parameters, remembering the first one that is malformed
+type query struct {
+	values url.Values
+	err    error
+}
+
+func newQuery(r *http.Request) *query {
+	return &query{values: r.URL.Query()}
+}
+
+func (q *query) String(name string) string {
+	return q.values.Get(name)
+}
+
+// Int returns an integer parameter, 0 when it is missing
+func (q *query) Int(name string) int {
+	value := q.values.Get(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil && q.err == nil {
+		q.err = fmt.Errorf("invalid query parameter %s", name)
+	}
+	return n
+}
+
+// Bool returns a boolean parameter, false when it is missing
+func (q *query) Bool(name string) bool {
+	value := q.values.Get(name)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil && q.err == nil {
+		q.err = fmt.Errorf("invalid query parameter %s", name)
+	}
+	return b
+}
+
+// IDs returns a comma separated list of ids, such as tag_ids=1,2
+func (q *query) IDs(name string) []int {
+	var ids []int
+	for _, field := range strings.Split(q.values.Get(name), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil && q.err == nil {
+			q.err = fmt.Errorf("invalid query parameter %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// badRequest writes err when it is set and reports whether it did
+func badRequest(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+	// The message tells which part of the request is malformed
+	resp := types.JSResp{Msg: err.Error(), Code: types.ErrInvalidRequest.Code, Key: types.ErrInvalidRequest.Key}
+	writeError(w, resp)
+	return true
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/services"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// newTestHandler serves the API on the services of a new in-memory database
+func newTestHandler(t *testing.T, config Config) http.Handler {
+	store, err := storage.OpenMemory()
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	tagSvc := services.NewTagService(store)
+	tagSvc.(*services.TagServiceImpl).Start(context.Background())
+	noteSvc := services.NewNoteServiceImpl(store)
+	noteSvc.(*services.NoteServiceImpl).Start(context.Background())
+	categorySvc := services.NewCategoryService(store)
+	categorySvc.(*services.CategoryServiceImpl).Start(context.Background())
+	reviewSvc := services.NewReviewService(store)
+	reviewSvc.(*services.ReviewServiceImpl).Start(context.Background())
+	return NewHandler(Services{Tags: tagSvc, Notes: noteSvc, Categories: categorySvc, Reviews: reviewSvc}, config)
+}
+
+// call sends a request with an optional JSON body and decodes the JSON response into out
+func call(t *testing.T, h http.Handler, method string, target string, body string, out interface{}) int {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestTagAPI(t *testing.T) {
+	h := newTestHandler(t, Config{})
+
+	var tag types.Tag
+	assert.Equal(t, http.StatusCreated, call(t, h, "POST", "/api/tags", `{"name": "n5"}`, &tag))
+	assert.Equal(t, "n5", tag.Name)
+	assert.Equal(t, http.StatusCreated, call(t, h, "POST", "/api/tags", `{"name": "n4"}`, nil))
+
+	var failure errorBody
+	assert.Equal(t, http.StatusConflict, call(t, h, "POST", "/api/tags", `{"name": "n5"}`, &failure))
+	assert.Equal(t, errorBody{Error: "tag already exists", Code: "TAG_EXISTS", Key: "error.tag_exists"}, failure)
+	failure = errorBody{}
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "POST", "/api/tags", `{"name": ""}`, &failure))
+	require.Len(t, failure.Details, 1)
+	assert.Equal(t, "name", failure.Details[0].Field)
+	assert.Equal(t, "TAG_NAME_EMPTY", failure.Details[0].Code)
+	failure = errorBody{}
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "POST", "/api/tags", `{`, &failure))
+	assert.Equal(t, "INVALID_REQUEST", failure.Code)
+
+	var list types.TagList
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/tags?page=1&page_size=1&keyword=n", "", &list))
+	assert.Equal(t, 2, list.Total)
+	assert.Equal(t, 2, list.TotalPages)
+	assert.Len(t, list.Data, 1)
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "GET", "/api/tags?page=first", "", nil))
+
+	assert.Equal(t, http.StatusOK, call(t, h, "PUT", "/api/tags/1", `{"name": "JLPT N5"}`, &tag))
+	assert.Equal(t, types.Tag{ID: 1, Name: "JLPT N5"}, tag)
+	assert.Equal(t, http.StatusConflict, call(t, h, "PUT", "/api/tags/2", `{"name": "JLPT N5"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "PUT", "/api/tags/x", `{"name": "x"}`, nil))
+
+	i18n.SetLocale("zh-CN")
+	t.Cleanup(func() { i18n.SetLocale(i18n.DefaultLocale) })
+	var message map[string]string
+	assert.Equal(t, http.StatusOK, call(t, h, "DELETE", "/api/tags/1", "", &message))
+	assert.Equal(t, "标签删除成功", message["message"])
+	assert.Equal(t, http.StatusNotFound, call(t, h, "DELETE", "/api/tags/1", "", nil))
+	assert.Equal(t, http.StatusNotFound, call(t, h, "GET", "/api/unknown", "", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, call(t, h, "PATCH", "/api/tags/2", "", nil))
+}
+
+func TestNoteAndReviewAPI(t *testing.T) {
+	h := newTestHandler(t, Config{})
+
+	var category types.Category
+	assert.Equal(t, http.StatusCreated, call(t, h, "POST", "/api/categories", `{"name": "Lesson 1"}`, &category))
+	assert.Equal(t, http.StatusCreated, call(t, h, "POST", "/api/tags", `{"name": "n5"}`, nil))
+
+	var note types.Note
+	body := `{"front": "犬", "back": "dog", "category_id": 1, "tag_ids": [1]}`
+	assert.Equal(t, http.StatusCreated, call(t, h, "POST", "/api/notes", body, &note))
+	assert.Equal(t, "inu", note.Romaji)
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "POST", "/api/notes", `{"front": " "}`, nil))
+	assert.Equal(t, http.StatusNotFound, call(t, h, "POST", "/api/notes", `{"front": "猫", "tag_ids": [9]}`, nil))
+	assert.Equal(t, http.StatusConflict, call(t, h, "DELETE", "/api/categories/1", "", nil))
+
+	var list types.NoteList
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/notes?tag_ids=1&category_id=1", "", &list))
+	assert.Equal(t, 1, list.Total)
+	var page types.NotePage
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/notes?cursor=&page_size=5", "", &page))
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "GET", "/api/notes?cursor=bogus", "", nil))
+
+	assert.Equal(t, http.StatusOK, call(t, h, "PUT", "/api/notes/1", `{"front": "猫", "back": "cat"}`, &note))
+	assert.Equal(t, "neko", note.Romaji)
+	assert.Equal(t, http.StatusNotFound, call(t, h, "GET", "/api/notes/2", "", nil))
+
+	var check types.AnswerCheck
+	assert.Equal(t, http.StatusCreated, call(t, h, "POST", "/api/reviews/check", `{"note_id": 1, "side": "back", "answer": "Cat"}`, &check))
+	assert.True(t, check.Correct)
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "POST", "/api/reviews", `{"note_id": 1, "grade": 9}`, nil))
+	var history []types.ReviewLog
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/notes/1/reviews", "", &history))
+	require.Len(t, history, 1)
+	assert.Equal(t, "Cat", history[0].Answer)
+
+	var algorithm string
+	assert.Equal(t, http.StatusOK, call(t, h, "PUT", "/api/reviews/algorithm", `{"name": "fsrs"}`, &algorithm))
+	assert.Equal(t, "fsrs", algorithm)
+
+	assert.Equal(t, http.StatusOK, call(t, h, "DELETE", "/api/notes/1", "", nil))
+	assert.Equal(t, http.StatusOK, call(t, h, "DELETE", "/api/categories/1", "", nil))
+}
+
+func TestAPIAccess(t *testing.T) {
+	h := newTestHandler(t, Config{Token: "secret", AllowOrigin: "*"})
+
+	assert.Equal(t, http.StatusUnauthorized, call(t, h, "GET", "/api/tags", "", nil))
+
+	req := httptest.NewRequest("GET", "/api/tags", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// Preflight requests carry no token
+	assert.Equal(t, http.StatusNoContent, call(t, h, "OPTIONS", "/api/tags", "", nil))
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(Config{Address: "127.0.0.1:0"}, Services{Tags: services.NewTagService(nil)})
+	require.NoError(t, server.Start())
+	defer server.Stop(context.Background())
+
+	res, err := http.Get("http://" + server.Addr() + "/api/unknown")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
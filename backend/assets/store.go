@@ -0,0 +1,143 @@
+// Package assets keeps media files in a content-addressed directory.
+//
+// Every file is stored once under the hex SHA-256 of its content, in a
+// subdirectory named after the first two hex digits:
+//
+//	<dir>/ab/ab12...ef
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrInvalidHash is returned for hashes that are not a hex SHA-256
+var ErrInvalidHash = errors.New("assets: invalid hash")
+
+// Store is a content-addressed file store rooted at a directory
+type Store struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewStore creates a store in dir, the directory is created on the first write
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the directory of the store
+func (s *Store) Dir() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dir
+}
+
+// SetDir moves the store to another directory, files are not copied
+func (s *Store) SetDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dir = dir
+}
+
+// ValidHash reports whether hash is a lowercase hex SHA-256
+func ValidHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range hash {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Path returns the location of the file with the given hash
+func (s *Store) Path(hash string) (string, error) {
+	if !ValidHash(hash) {
+		return "", ErrInvalidHash
+	}
+	return filepath.Join(s.Dir(), hash[:2], hash), nil
+}
+
+// Put stores the content of r and returns its hash and size. Content that is
+// already stored is not written again.
+func (s *Store) Put(r io.Reader) (string, int64, error) {
+	dir := s.Dir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(tmp, io.TeeReader(r, h))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	dst := filepath.Join(dir, hash[:2], hash)
+	if _, err := os.Stat(dst); err == nil {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", 0, err
+	}
+	return hash, size, nil
+}
+
+// Open opens the file with the given hash
+func (s *Store) Open(hash string) (*os.File, error) {
+	path, err := s.Path(hash)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Remove deletes the file with the given hash, a missing file is not an error
+func (s *Store) Remove(hash string) error {
+	path, err := s.Path(hash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Hashes returns the hashes of all stored files
+func (s *Store) Hashes() ([]string, error) {
+	dir := s.Dir()
+	var hashes []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && ValidHash(d.Name()) {
+			hashes = append(hashes, d.Name())
+		}
+		return nil
+	})
+	return hashes, err
+}
@@ -0,0 +1,62 @@
+package assets
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sha256 of "hello"
+const helloHash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "assets")
+	store := NewStore(dir)
+
+	hashes, err := store.Hashes()
+	require.NoError(t, err)
+	assert.Empty(t, hashes, "a store that was never written to is empty")
+
+	hash, size, err := store.Put(strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, helloHash, hash)
+	assert.Equal(t, int64(5), size)
+	path, err := store.Path(hash)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2c", helloHash), path)
+
+	// The same content is stored once
+	again, _, err := store.Put(strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+	_, _, err = store.Put(strings.NewReader("world"))
+	require.NoError(t, err)
+	hashes, err = store.Hashes()
+	require.NoError(t, err)
+	assert.Len(t, hashes, 2)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.True(t, entry.IsDir(), "no temporary files are left behind: %s", entry.Name())
+	}
+
+	f, err := store.Open(hash)
+	require.NoError(t, err)
+	content, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, "hello", string(content))
+
+	require.NoError(t, store.Remove(hash))
+	require.NoError(t, store.Remove(hash), "removing a missing file is not an error")
+	_, err = store.Open(hash)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = store.Path("../../etc/passwd")
+	assert.Equal(t, ErrInvalidHash, err)
+	assert.False(t, ValidHash(strings.ToUpper(helloHash)))
+}
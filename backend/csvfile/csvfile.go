@@ -0,0 +1,141 @@
+// Package csvfile reads and writes CSV and TSV files in legacy encodings such
+// as Shift-JIS and GBK, as produced by spreadsheet programs in Japan and China.
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+
+	"langlearner1/backend/types"
+)
+
+// Options describes the dialect of a file
+type Options struct {
+	// Delimiter separates the fields, 0 picks a tab for .tsv and .tab files and a comma otherwise
+	Delimiter rune
+	// Encoding is a WHATWG encoding label such as "utf-8", "shift_jis", "gbk" or "utf-16", empty means UTF-8
+	Encoding string
+}
+
+// Encoding returns the encoding for a label. "utf-16" is little-endian with a byte order mark.
+func Encoding(label string) (encoding.Encoding, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	switch label {
+	case "", "utf-8", "utf8":
+		return unicode.UTF8, nil
+	case "utf-16", "utf16":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", types.ErrUnknownEncoding, label)
+	}
+	return enc, nil
+}
+
+func (o Options) delimiter(path string) rune {
+	if o.Delimiter != 0 {
+		return o.Delimiter
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		return '\t'
+	}
+	return ','
+}
+
+// Reader reads records from a file
+type Reader struct {
+	file *os.File
+	csv  *csv.Reader
+}
+
+// Open opens a file for reading, a byte order mark overrides the configured encoding
+func Open(path string, options Options) (*Reader, error) {
+	enc, err := Encoding(options.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(transform.NewReader(file, unicode.BOMOverride(enc.NewDecoder())))
+	r.Comma = options.delimiter(path)
+	r.FieldsPerRecord = -1
+	// Quotes in TSV files are usually part of the text rather than quoting
+	r.LazyQuotes = r.Comma == '\t'
+	return &Reader{file: file, csv: r}, nil
+}
+
+// Read returns the next record and the line it starts on. A malformed record
+// returns a *csv.ParseError and reading can continue with the next record.
+// At the end of the file it returns io.EOF.
+func (r *Reader) Read() ([]string, int, error) {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.StartLine, err
+		}
+		return nil, 0, err
+	}
+	line, _ := r.csv.FieldPos(0)
+	return record, line, nil
+}
+
+// Close closes the file
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// Writer writes records to a file
+type Writer struct {
+	file *os.File
+	enc  io.WriteCloser
+	csv  *csv.Writer
+}
+
+// Create creates or truncates a file for writing
+func Create(path string, options Options) (*Writer, error) {
+	enc, err := Encoding(options.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	encoder := transform.NewWriter(file, enc.NewEncoder())
+	w := csv.NewWriter(encoder)
+	w.Comma = options.delimiter(path)
+	return &Writer{file: file, enc: encoder, csv: w}, nil
+}
+
+// Write writes a record, characters the encoding cannot represent fail the write
+func (w *Writer) Write(record []string) error {
+	return w.csv.Write(record)
+}
+
+// Close flushes the buffered records and closes the file
+func (w *Writer) Close() error {
+	w.csv.Flush()
+	err := w.csv.Error()
+	if closeErr := w.enc.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
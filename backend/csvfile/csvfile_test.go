@@ -0,0 +1,111 @@
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func readAll(t *testing.T, path string, options Options) [][]string {
+	r, err := Open(path, options)
+	require.NoError(t, err)
+	defer r.Close()
+	var records [][]string
+	for {
+		record, _, err := r.Read()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	japanese := [][]string{
+		{"front", "back"},
+		{"こんにちは", "hello, \"friend\""},
+		{"ありがとう", "thanks\nthank you"},
+	}
+	chinese := [][]string{
+		{"front", "back"},
+		{"你好", "hello"},
+		{"谢谢", "thanks\tthank you"},
+	}
+	tests := []struct {
+		name     string
+		file     string
+		encoding string
+		records  [][]string
+		// raw is a byte sequence the encoded file must contain
+		raw []byte
+	}{
+		{"utf-8", "notes.csv", "", japanese, []byte("こ")},
+		{"shift_jis", "notes.csv", "shift_jis", japanese, []byte{0x82, 0xb1}},
+		{"gbk", "notes.tsv", "gbk", chinese, []byte{0xc4, 0xe3}},
+		{"utf-16", "notes.tsv", "utf-16", chinese, []byte{0xff, 0xfe}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			w, err := Create(path, Options{Encoding: tt.encoding})
+			require.NoError(t, err)
+			for _, record := range tt.records {
+				require.NoError(t, w.Write(record))
+			}
+			require.NoError(t, w.Close())
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), string(tt.raw))
+			assert.Equal(t, tt.records, readAll(t, path, Options{Encoding: tt.encoding}))
+		})
+	}
+}
+
+func TestByteOrderMark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bom.csv")
+	require.NoError(t, os.WriteFile(path, []byte("\xef\xbb\xbffront,back\nいぬ,dog\n"), 0644))
+	// The UTF-8 BOM wins over the configured encoding and is not part of the first field
+	assert.Equal(t, [][]string{{"front", "back"}, {"いぬ", "dog"}}, readAll(t, path, Options{Encoding: "shift_jis"}))
+}
+
+func TestReadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a,b\n\"c,d\n"), 0644))
+	r, err := Open(path, Options{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	record, line, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, record)
+	assert.Equal(t, 1, line)
+	_, line, err = r.Read()
+	var parseErr *csv.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 2, line)
+
+	// Quotes inside TSV fields are text
+	tsv := filepath.Join(t.TempDir(), "quotes.tsv")
+	require.NoError(t, os.WriteFile(tsv, []byte("say \"hi\"\tback\n"), 0644))
+	assert.Equal(t, [][]string{{"say \"hi\"", "back"}}, readAll(t, tsv, Options{}))
+
+	_, err = Open(path, Options{Encoding: "klingon"})
+	assert.ErrorIs(t, err, types.ErrUnknownEncoding)
+}
+
+func TestWriteUnsupportedCharacter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.csv")
+	w, err := Create(path, Options{Encoding: "shift_jis"})
+	require.NoError(t, err)
+	require.NoError(t, w.Write([]string{"😀"}))
+	assert.Error(t, w.Close())
+}
@@ -0,0 +1,162 @@
+// Package i18n translates the user-facing messages of the backend. The
+// catalogs in locales/ map message keys to text, one JSON file per locale,
+// and a key missing from a catalog falls back to DefaultLocale.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is used until another locale is set and for keys missing from a catalog
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+var (
+	// catalogs holds the messages by locale and key
+	catalogs = mustLoad()
+	// matcher picks the catalog for a requested language
+	matcher language.Matcher
+	// locales lists the catalogs in the order of matcher
+	locales []string
+
+	mu      sync.RWMutex
+	current = DefaultLocale
+)
+
+func init() {
+	locales = []string{DefaultLocale}
+	for locale := range catalogs {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales[1:])
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tags[i] = language.Make(locale)
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// mustLoad reads the embedded catalogs, the locale of a catalog is its file name
+func mustLoad() map[string]map[string]string {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// Locales returns the locales that have a catalog, DefaultLocale first
+func Locales() []string {
+	return append([]string(nil), locales...)
+}
+
+// Keys returns the keys of the catalog of locale in order
+func Keys(locale string) []string {
+	keys := make([]string, 0, len(catalogs[locale]))
+	for key := range catalogs[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Match returns the locale with a catalog that suits tag best, which may be a
+// BCP 47 tag such as "zh-Hans-CN" or a POSIX locale such as "zh_CN.UTF-8".
+// DefaultLocale is returned for an empty or unsupported tag.
+func Match(tag string) string {
+	// Drop the encoding and modifier of a POSIX locale
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" || tag == "C" || tag == "POSIX" {
+		return DefaultLocale
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(parsed)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return locales[index]
+}
+
+// SystemLocale returns the locale of the system the app runs in, matched like
+// Match. It is the user locale of Windows or macOS, and otherwise comes from
+// the LC_ALL, LC_MESSAGES and LANG variables.
+func SystemLocale() string {
+	if tag := platformLocale(); tag != "" {
+		return Match(tag)
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return Match(value)
+		}
+	}
+	return DefaultLocale
+}
+
+// Locale returns the locale messages are translated to
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// SetLocale translates the following messages to the locale that matches tag
+// best and returns that locale
+func SetLocale(tag string) string {
+	locale := Match(tag)
+	mu.Lock()
+	current = locale
+	mu.Unlock()
+	return locale
+}
+
+// T returns the message of key in the current locale, formatted with args
+func T(key string, args ...interface{}) string {
+	return Translate(Locale(), key, args...)
+}
+
+// Translate returns the message of key in locale, formatted with args. A key
+// missing from the catalog is taken from DefaultLocale, and an unknown key is
+// returned as it is.
+func Translate(locale string, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
@@ -0,0 +1,72 @@
+package i18n_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/types"
+)
+
+func TestCatalogsCoverSameKeys(t *testing.T) {
+	keys := i18n.Keys(i18n.DefaultLocale)
+	require.NotEmpty(t, keys)
+	assert.Contains(t, i18n.Locales(), "zh-CN")
+	for _, locale := range i18n.Locales() {
+		assert.Equal(t, keys, i18n.Keys(locale), "keys of %s", locale)
+	}
+	for _, e := range types.Errors() {
+		assert.Contains(t, keys, e.Key, "message of %s", e.Code)
+		assert.Equal(t, e.Message, i18n.Translate(i18n.DefaultLocale, e.Key), "English message of %s", e.Code)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for tag, locale := range map[string]string{
+		"":                "en",
+		"C":               "en",
+		"en_US.UTF-8":     "en",
+		"zh_CN.UTF-8":     "zh-CN",
+		"zh-Hans":         "zh-CN",
+		"zh_CN":           "zh-CN",
+		"zh-Hans-CN":      "zh-CN",
+		"en_GB@rg=gbzzzz": "en",
+		"zh":              "zh-CN",
+		"ja-JP":           "en",
+		"not a tag":       "en",
+	} {
+		assert.Equal(t, locale, i18n.Match(tag), "tag %q", tag)
+	}
+}
+
+func TestSystemLocale(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("the locale comes from the system settings")
+	}
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "zh_CN.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	assert.Equal(t, "zh-CN", i18n.SystemLocale())
+	t.Setenv("LC_MESSAGES", "")
+	assert.Equal(t, "en", i18n.SystemLocale())
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "标签已存在", i18n.Translate("zh-CN", "error.tag_exists"))
+	assert.Equal(t, "tag already exists", i18n.Translate("fr", "error.tag_exists"), "unknown locales fall back")
+	assert.Equal(t, "no.such.key", i18n.Translate("zh-CN", "no.such.key"))
+	assert.Equal(t, "你好 Ann，开始吧！", i18n.Translate("zh-CN", "app.greeting", "Ann"))
+
+	assert.Equal(t, "zh-CN", i18n.SetLocale("zh_CN.UTF-8"))
+	t.Cleanup(func() { i18n.SetLocale(i18n.DefaultLocale) })
+	var resp types.JSResp
+	resp.SetError(types.NewFieldError("name", types.ErrTagNameEmpty))
+	assert.Equal(t, "标签名称不能为空", resp.Msg)
+	assert.Equal(t, "标签名称不能为空", resp.Details[0].Message)
+	assert.ErrorIs(t, resp.Err(), types.ErrTagNameEmpty)
+	resp.SetError(fmt.Errorf("%w: %q", types.ErrInvalidLanguage, "xx"))
+	assert.Equal(t, `无效的语言代码: "xx"`, resp.Msg, "details around the message are kept")
+}
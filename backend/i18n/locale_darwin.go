@@ -0,0 +1,16 @@
+package i18n
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// platformLocale returns the AppleLocale user default, such as "zh_CN" or
+// "en_US@rg=gbzzzz". Apps started from the Finder do not get LANG.
+func platformLocale() string {
+	out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,8 @@
+//go:build !windows && !darwin
+
+package i18n
+
+// platformLocale returns nothing, other systems set the locale in the environment
+func platformLocale() string {
+	return ""
+}
@@ -0,0 +1,24 @@
+package i18n
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var getUserDefaultLocaleName = syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// localeNameMaxLength is LOCALE_NAME_MAX_LENGTH, the buffer size the locale name fits in
+const localeNameMaxLength = 85
+
+// platformLocale returns the locale of the Windows user, such as "zh-CN"
+func platformLocale() string {
+	if getUserDefaultLocaleName.Find() != nil {
+		return ""
+	}
+	buf := make([]uint16, localeNameMaxLength)
+	n, _, _ := getUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
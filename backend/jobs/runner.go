@@ -0,0 +1,328 @@
+// Package jobs runs long operations in the background on a bounded pool of
+// workers. Jobs are persisted, so queued work survives a restart of the app.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// DefaultWorkers is the number of jobs run at the same time unless configured otherwise
+const DefaultWorkers = 2
+
+// progressInterval limits how often progress is saved and emitted
+const progressInterval = 250 * time.Millisecond
+
+// Progress reports that done out of total items were processed, total is 0 when unknown
+type Progress func(done int, total int)
+
+// Handler runs a job of one kind. It should stop when ctx is cancelled and
+// return a result that is stored with the job as JSON.
+type Handler func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error)
+
+// EmitFunc sends an event to the frontend, runtime.EventsEmit in the app
+type EmitFunc func(ctx context.Context, event string, data ...interface{})
+
+// Runner executes persisted jobs on a pool of workers
+type Runner struct {
+	storage  storage.JobStorage
+	handlers map[string]Handler
+	// once holds the job kinds that must not run twice
+	once    map[string]bool
+	workers int
+	emit    EmitFunc
+	wake    chan struct{}
+	wg      sync.WaitGroup
+
+	// mu guards ctx and stop, which Start sets, and cancel
+	mu     sync.Mutex
+	ctx    context.Context
+	stop   context.CancelFunc
+	cancel map[int]context.CancelFunc
+}
+
+// Option configures a Runner
+type Option func(*Runner)
+
+// WithWorkers sets the number of jobs run at the same time
+func WithWorkers(workers int) Option {
+	return func(r *Runner) {
+		if workers > 0 {
+			r.workers = workers
+		}
+	}
+}
+
+// WithEmitter replaces runtime.EventsEmit, which only works with the context of a running app
+func WithEmitter(emit EmitFunc) Option {
+	return func(r *Runner) {
+		r.emit = emit
+	}
+}
+
+// NewRunner creates a runner keeping its jobs in st
+func NewRunner(st storage.JobStorage, options ...Option) *Runner {
+	r := &Runner{
+		storage:  st,
+		handlers: map[string]Handler{},
+		once:     map[string]bool{},
+		workers:  DefaultWorkers,
+		emit:     runtime.EventsEmit,
+		cancel:   map[int]context.CancelFunc{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	r.wake = make(chan struct{}, r.workers)
+	return r
+}
+
+// Handle registers the handler of a job kind, it must be called before Start
+func (r *Runner) Handle(kind string, handler Handler) {
+	r.handlers[kind] = handler
+}
+
+// HandleOnce registers the handler of a job kind that must not run twice, such
+// as an import that creates notes. An interrupted job of that kind is failed
+// with ErrJobInterrupted instead of being resumed, since it may have written
+// part of its work already.
+func (r *Runner) HandleOnce(kind string, handler Handler) {
+	r.Handle(kind, handler)
+	r.once[kind] = true
+}
+
+// Start resumes the jobs left queued or running by a previous run and starts
+// the workers. Cancelling ctx or calling Stop interrupts running jobs, which
+// are queued again so the next start resumes them.
+func (r *Runner) Start(ctx context.Context) error {
+	once := make([]string, 0, len(r.once))
+	for kind := range r.once {
+		once = append(once, kind)
+	}
+	sort.Strings(once)
+	if _, err := r.storage.Interrupt(once, types.Localize(types.ErrJobInterrupted)); err != nil {
+		return err
+	}
+	if _, err := r.storage.Requeue(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ctx, r.stop = context.WithCancel(ctx)
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go r.work(r.ctx)
+	}
+	return nil
+}
+
+// Stop interrupts the running jobs and waits for the workers to exit
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	stop := r.stop
+	r.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	stop()
+	r.wg.Wait()
+}
+
+// context returns the context of the workers, nil before Start
+func (r *Runner) context() context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ctx
+}
+
+// Submit queues a job of kind with params encoded as JSON
+func (r *Runner) Submit(kind string, params interface{}) (*types.Job, error) {
+	if _, ok := r.handlers[kind]; !ok {
+		return nil, fmt.Errorf("%w: %q", types.ErrUnknownJobKind, kind)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	job := &types.Job{Kind: kind, Status: types.JobQueued, Params: data}
+	if err := r.storage.Create(job); err != nil {
+		return nil, err
+	}
+	r.notify(job)
+	r.signal()
+	return job, nil
+}
+
+// Cancel stops a queued or running job
+func (r *Runner) Cancel(id int) error {
+	job, err := r.cancelJob(id)
+	if job != nil {
+		r.notify(job)
+	}
+	return err
+}
+
+// cancelJob cancels a job and returns it when it was not running, the worker
+// of a running job reports it when it stops
+func (r *Runner) cancelJob(id int) (*types.Job, error) {
+	// Holding mu keeps workers from claiming the job while it is cancelled
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cancel, ok := r.cancel[id]; ok {
+		cancel()
+		return nil, nil
+	}
+
+	cancelled, err := r.storage.CancelQueued(id)
+	if err != nil {
+		return nil, err
+	}
+	job, err := r.storage.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		if job.Finished() {
+			return nil, types.ErrJobFinished
+		}
+		// Left running by a previous run that was not resumed yet
+		job.Status = types.JobCancelled
+		job.FinishedAt = time.Now().Unix()
+		if err := r.storage.Update(job); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// signal wakes an idle worker without blocking when all of them are busy
+func (r *Runner) signal() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work runs jobs until runCtx, the context the runner was started with, is done
+func (r *Runner) work(runCtx context.Context) {
+	defer r.wg.Done()
+	for runCtx.Err() == nil {
+		job, ctx, err := r.claim()
+		if err != nil {
+			println("Error:", err.Error())
+		}
+		if job != nil {
+			r.run(runCtx, ctx, job)
+			continue
+		}
+		select {
+		case <-runCtx.Done():
+			return
+		case <-r.wake:
+		case <-time.After(time.Minute):
+			// Failed claims are retried even without new submissions
+		}
+	}
+}
+
+// claim takes the next queued job and registers the function that cancels it
+func (r *Runner) claim() (*types.Job, context.Context, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, err := r.storage.Claim()
+	if err != nil || job == nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.cancel[job.ID] = cancel
+	return job, ctx, nil
+}
+
+// run executes a claimed job and records how it ended
+func (r *Runner) run(runCtx context.Context, ctx context.Context, job *types.Job) {
+	defer func() {
+		r.mu.Lock()
+		r.cancel[job.ID]()
+		delete(r.cancel, job.ID)
+		r.mu.Unlock()
+	}()
+	r.notify(job)
+
+	var last time.Time
+	progress := func(done int, total int) {
+		job.Progress, job.Total = done, total
+		if time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		r.save(job)
+	}
+
+	result, err := r.execute(ctx, job, progress)
+	switch {
+	case err == nil:
+		job.Status = types.JobDone
+		job.Result, err = json.Marshal(result)
+		if err != nil {
+			job.Status = types.JobFailed
+			job.Error = err.Error()
+		}
+	case runCtx.Err() != nil && r.once[job.Kind]:
+		job.Status = types.JobFailed
+		job.Error = types.Localize(types.ErrJobInterrupted)
+	case runCtx.Err() != nil:
+		// The app is shutting down, run the job again on the next start
+		job.Status = types.JobQueued
+		job.StartedAt = 0
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		job.Status = types.JobCancelled
+	default:
+		job.Status = types.JobFailed
+		job.Error = types.Localize(err)
+	}
+	if job.Status != types.JobQueued {
+		job.FinishedAt = time.Now().Unix()
+	}
+	r.save(job)
+}
+
+// execute calls the handler of a job, turning a panic into an error
+func (r *Runner) execute(ctx context.Context, job *types.Job, progress Progress) (result interface{}, err error) {
+	handler, ok := r.handlers[job.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", types.ErrUnknownJobKind, job.Kind)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return handler(ctx, job.Params, progress)
+}
+
+func (r *Runner) save(job *types.Job) {
+	if err := r.storage.Update(job); err != nil {
+		println("Error:", err.Error())
+	}
+	r.notify(job)
+}
+
+// notify emits the current state of a job to the frontend, it must not be
+// called with mu held
+func (r *Runner) notify(job *types.Job) {
+	ctx := r.context()
+	if ctx == nil || r.emit == nil {
+		return
+	}
+	snapshot := *job
+	r.emit(ctx, types.JobEvent, &snapshot)
+}
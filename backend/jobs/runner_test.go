@@ -0,0 +1,321 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+// memoryStorage keeps jobs in memory
+type memoryStorage struct {
+	mu   sync.Mutex
+	jobs map[int]types.Job
+	next int
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{jobs: map[int]types.Job{}}
+}
+
+func (m *memoryStorage) Create(job *types.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next++
+	job.ID = m.next
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *memoryStorage) Get(id int) (*types.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, types.ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (m *memoryStorage) List(status string, limit int) ([]types.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []types.Job
+	for _, job := range m.jobs {
+		if status == "" || job.Status == status {
+			list = append(list, job)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	return list[:min(len(list), limit)], nil
+}
+
+func (m *memoryStorage) Claim() (*types.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := 1; id <= m.next; id++ {
+		if job, ok := m.jobs[id]; ok && job.Status == types.JobQueued {
+			job.Status = types.JobRunning
+			m.jobs[id] = job
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryStorage) Update(job *types.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.jobs[job.ID]; !ok {
+		return types.ErrJobNotFound
+	}
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *memoryStorage) CancelQueued(id int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || job.Status != types.JobQueued {
+		return false, nil
+	}
+	job.Status = types.JobCancelled
+	m.jobs[id] = job
+	return true, nil
+}
+
+func (m *memoryStorage) Requeue() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for id, job := range m.jobs {
+		if job.Status == types.JobRunning {
+			job.Status = types.JobQueued
+			m.jobs[id] = job
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryStorage) Interrupt(kinds []string, message string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for id, job := range m.jobs {
+		if job.Status == types.JobRunning && slices.Contains(kinds, job.Kind) {
+			job.Status = types.JobFailed
+			job.Error = message
+			m.jobs[id] = job
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryStorage) DeleteFinished() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for id, job := range m.jobs {
+		if job.Finished() {
+			delete(m.jobs, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// events records the jobs emitted to the frontend
+type events struct {
+	mu   sync.Mutex
+	jobs []types.Job
+}
+
+func (e *events) emit(ctx context.Context, event string, data ...interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if event == types.JobEvent {
+		e.jobs = append(e.jobs, *data[0].(*types.Job))
+	}
+}
+
+func (e *events) statuses(id int) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var statuses []string
+	for _, job := range e.jobs {
+		if job.ID == id && (len(statuses) == 0 || statuses[len(statuses)-1] != job.Status) {
+			statuses = append(statuses, job.Status)
+		}
+	}
+	return statuses
+}
+
+func newTestRunner(t *testing.T, st *memoryStorage, workers int) (*Runner, *events) {
+	e := &events{}
+	r := NewRunner(st, WithWorkers(workers), WithEmitter(e.emit))
+	t.Cleanup(r.Stop)
+	return r, e
+}
+
+// waitFor polls the stored job until it reaches status
+func waitFor(t *testing.T, st *memoryStorage, id int, status string) *types.Job {
+	t.Helper()
+	var job *types.Job
+	require.Eventually(t, func() bool {
+		job, _ = st.Get(id)
+		return job != nil && job.Status == status
+	}, 5*time.Second, 5*time.Millisecond, "job %d never became %s", id, status)
+	return job
+}
+
+func TestRunnerRunsJobs(t *testing.T) {
+	st := newMemoryStorage()
+	r, e := newTestRunner(t, st, 1)
+	r.Handle("sum", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		var numbers []int
+		if err := json.Unmarshal(params, &numbers); err != nil {
+			return nil, err
+		}
+		sum := 0
+		for i, n := range numbers {
+			progress(i, len(numbers))
+			sum += n
+		}
+		progress(len(numbers), len(numbers))
+		return map[string]int{"sum": sum}, nil
+	})
+	r.Handle("fail", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		return nil, errors.New("broken")
+	})
+	r.Handle("panic", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		panic("boom")
+	})
+	require.NoError(t, r.Start(context.Background()))
+
+	job, err := r.Submit("sum", []int{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, types.JobQueued, job.Status)
+	done := waitFor(t, st, job.ID, types.JobDone)
+	assert.JSONEq(t, `{"sum":6}`, string(done.Result))
+	assert.Equal(t, 3, done.Progress)
+	assert.Equal(t, 3, done.Total)
+	assert.NotZero(t, done.FinishedAt)
+	assert.Equal(t, []string{types.JobRunning, types.JobDone}, e.statuses(job.ID)[1:])
+
+	job, err = r.Submit("fail", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "broken", waitFor(t, st, job.ID, types.JobFailed).Error)
+
+	job, err = r.Submit("panic", nil)
+	require.NoError(t, err)
+	assert.Contains(t, waitFor(t, st, job.ID, types.JobFailed).Error, "boom")
+
+	_, err = r.Submit("unknown", nil)
+	assert.ErrorIs(t, err, types.ErrUnknownJobKind)
+}
+
+func TestRunnerCancel(t *testing.T) {
+	st := newMemoryStorage()
+	r, _ := newTestRunner(t, st, 1)
+	started := make(chan struct{})
+	r.Handle("block", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	r.Handle("noop", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, r.Start(context.Background()))
+
+	running, err := r.Submit("block", nil)
+	require.NoError(t, err)
+	<-started
+	// The only worker is busy, so this job stays queued
+	queued, err := r.Submit("noop", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Cancel(queued.ID))
+	waitFor(t, st, queued.ID, types.JobCancelled)
+	require.NoError(t, r.Cancel(running.ID))
+	waitFor(t, st, running.ID, types.JobCancelled)
+
+	assert.ErrorIs(t, r.Cancel(running.ID), types.ErrJobFinished)
+	assert.ErrorIs(t, r.Cancel(99), types.ErrJobNotFound)
+}
+
+func TestRunnerResumesAfterRestart(t *testing.T) {
+	st := newMemoryStorage()
+	first, _ := newTestRunner(t, st, 1)
+	started := make(chan struct{})
+	first.Handle("work", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, first.Start(context.Background()))
+	interrupted, err := first.Submit("work", nil)
+	require.NoError(t, err)
+	<-started
+	waiting, err := first.Submit("work", nil)
+	require.NoError(t, err)
+
+	// Shutting down puts the running job back in the queue
+	first.Stop()
+	job, err := st.Get(interrupted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobQueued, job.Status)
+
+	second, _ := newTestRunner(t, st, 2)
+	second.Handle("work", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		return "resumed", nil
+	})
+	require.NoError(t, second.Start(context.Background()))
+	assert.JSONEq(t, `"resumed"`, string(waitFor(t, st, interrupted.ID, types.JobDone).Result))
+	waitFor(t, st, waiting.ID, types.JobDone)
+}
+
+func TestRunnerDoesNotResumeOnceJobs(t *testing.T) {
+	st := newMemoryStorage()
+	first, _ := newTestRunner(t, st, 1)
+	started := make(chan struct{})
+	first.HandleOnce("import", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, first.Start(context.Background()))
+	interrupted, err := first.Submit("import", nil)
+	require.NoError(t, err)
+	<-started
+	first.Stop()
+	job := waitFor(t, st, interrupted.ID, types.JobFailed)
+	assert.Equal(t, types.ErrJobInterrupted.Error(), job.Error)
+
+	// A job left running by a crash is not run again either
+	crashed := &types.Job{Kind: "import", Status: types.JobRunning}
+	require.NoError(t, st.Create(crashed))
+	queued := &types.Job{Kind: "import", Status: types.JobQueued}
+	require.NoError(t, st.Create(queued))
+	second, _ := newTestRunner(t, st, 1)
+	second.HandleOnce("import", func(ctx context.Context, params json.RawMessage, progress Progress) (interface{}, error) {
+		return "imported", nil
+	})
+	require.NoError(t, second.Start(context.Background()))
+	waitFor(t, st, queued.ID, types.JobDone)
+	job, err = st.Get(crashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobFailed, job.Status)
+	assert.Equal(t, types.ErrJobInterrupted.Error(), job.Error)
+}
@@ -0,0 +1,293 @@
+// Package profiles keeps the learner profiles, and which one is active, in a
+// JSON config file. Every profile has a directory relative to the config file
+// holding its database and files:
+//
+//	<dir>/langlearner.db
+//	<dir>/assets/
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"langlearner1/backend/types"
+)
+
+// DefaultName is the name of the profile created with a new config
+const DefaultName = "Default"
+
+// DatabaseFile is the name of a profile's database in its directory
+const DatabaseFile = "langlearner.db"
+
+// entry is a profile as stored in the config file
+type entry struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+}
+
+// config is the content of the config file
+type config struct {
+	Active   string  `json:"active"`
+	Profiles []entry `json:"profiles"`
+}
+
+// Manager reads and writes the profiles config file
+type Manager struct {
+	mu     sync.Mutex
+	path   string
+	root   string
+	config config
+}
+
+// Load reads the profiles config at path. Without the file a config is created
+// with a single default profile kept in the config's own directory, where the
+// app stored its data before there were profiles.
+func Load(path string) (*Manager, error) {
+	m := &Manager{path: path, root: filepath.Dir(path)}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &m.config); err != nil {
+			return nil, fmt.Errorf("profiles: invalid config %s: %w", path, err)
+		}
+	}
+	if len(m.config.Profiles) == 0 {
+		m.config.Profiles = []entry{{Name: DefaultName, Dir: "."}}
+	}
+	if m.find(m.config.Active) < 0 {
+		m.config.Active = m.config.Profiles[0].Name
+	}
+	if err := m.save(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// List returns all profiles in the order they were created
+func (m *Manager) List() []types.Profile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]types.Profile, 0, len(m.config.Profiles))
+	for _, e := range m.config.Profiles {
+		list = append(list, m.profile(e))
+	}
+	return list
+}
+
+// Active returns the active profile
+func (m *Manager) Active() types.Profile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.profile(m.config.Profiles[m.find(m.config.Active)])
+}
+
+// Get returns the profile with the given name
+func (m *Manager) Get(name string) (types.Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(name)
+	if i < 0 {
+		return types.Profile{}, types.ErrProfileNotFound
+	}
+	return m.profile(m.config.Profiles[i]), nil
+}
+
+// Create adds a profile with a new directory under profiles/
+func (m *Manager) Create(name string) (types.Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = strings.TrimSpace(name)
+	if err := m.checkName(name); err != nil {
+		return types.Profile{}, err
+	}
+	e := entry{Name: name, Dir: m.newDir(name)}
+	m.config.Profiles = append(m.config.Profiles, e)
+	if err := m.save(); err != nil {
+		m.config.Profiles = m.config.Profiles[:len(m.config.Profiles)-1]
+		return types.Profile{}, err
+	}
+	return m.profile(e), nil
+}
+
+// Rename changes the name of a profile, its directory is kept
+func (m *Manager) Rename(name string, newName string) (types.Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(name)
+	if i < 0 {
+		return types.Profile{}, types.ErrProfileNotFound
+	}
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return types.Profile{}, types.ErrProfileNameEmpty
+	}
+	// Changing only the case of the name is allowed
+	if j := m.find(newName); j >= 0 && j != i {
+		return types.Profile{}, types.ErrProfileExists
+	}
+	previous := m.config
+	m.config.Profiles = append([]entry(nil), m.config.Profiles...)
+	if m.config.Active == m.config.Profiles[i].Name {
+		m.config.Active = newName
+	}
+	m.config.Profiles[i].Name = newName
+	if err := m.save(); err != nil {
+		m.config = previous
+		return types.Profile{}, err
+	}
+	return m.profile(m.config.Profiles[i]), nil
+}
+
+// Delete removes a profile from the config, then its database and files. The
+// active profile cannot be deleted.
+func (m *Manager) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(name)
+	if i < 0 {
+		return types.ErrProfileNotFound
+	}
+	if m.config.Profiles[i].Name == m.config.Active {
+		return types.ErrProfileActive
+	}
+	e := m.config.Profiles[i]
+	previous := m.config
+	m.config.Profiles = append(append([]entry(nil), m.config.Profiles[:i]...), m.config.Profiles[i+1:]...)
+	if err := m.save(); err != nil {
+		m.config = previous
+		return err
+	}
+	return m.removeFiles(e)
+}
+
+// SetActive makes the profile with the given name active
+func (m *Manager) SetActive(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(name)
+	if i < 0 {
+		return types.ErrProfileNotFound
+	}
+	previous := m.config.Active
+	m.config.Active = m.config.Profiles[i].Name
+	if err := m.save(); err != nil {
+		m.config.Active = previous
+		return err
+	}
+	return nil
+}
+
+// DatabasePath returns the location of the profile's database
+func (m *Manager) DatabasePath(profile types.Profile) string {
+	return filepath.Join(m.root, profile.Dir, DatabaseFile)
+}
+
+// AssetDir returns the directory of the profile's asset store
+func (m *Manager) AssetDir(profile types.Profile) string {
+	return filepath.Join(m.root, profile.Dir, "assets")
+}
+
+// find returns the index of the profile named name ignoring case, or -1
+func (m *Manager) find(name string) int {
+	name = strings.TrimSpace(name)
+	for i, e := range m.config.Profiles {
+		if strings.EqualFold(e.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Manager) checkName(name string) error {
+	if name == "" {
+		return types.ErrProfileNameEmpty
+	}
+	if m.find(name) >= 0 {
+		return types.ErrProfileExists
+	}
+	return nil
+}
+
+func (m *Manager) profile(e entry) types.Profile {
+	return types.Profile{Name: e.Name, Dir: e.Dir, Active: e.Name == m.config.Active}
+}
+
+// newDir picks an unused directory for a profile named name
+func (m *Manager) newDir(name string) string {
+	slug := strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name), "-")
+	if slug == "" {
+		slug = "profile"
+	}
+	for n := 1; ; n++ {
+		dir := filepath.ToSlash(filepath.Join("profiles", slug))
+		if n > 1 {
+			dir += "-" + strconv.Itoa(n)
+		}
+		if !m.dirUsed(dir) {
+			return dir
+		}
+	}
+}
+
+// dirUsed reports whether dir belongs to a profile or exists on disk
+func (m *Manager) dirUsed(dir string) bool {
+	for _, e := range m.config.Profiles {
+		if filepath.Clean(e.Dir) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	_, err := os.Stat(filepath.Join(m.root, dir))
+	return err == nil
+}
+
+// removeFiles deletes the database, its backups and the files of a profile.
+// The directory itself is removed when nothing else is left in it.
+func (m *Manager) removeFiles(e entry) error {
+	profile := m.profile(e)
+	databases, err := filepath.Glob(m.DatabasePath(profile) + "*")
+	if err != nil {
+		return err
+	}
+	for _, path := range databases {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(m.AssetDir(profile)); err != nil {
+		return err
+	}
+	if filepath.Clean(e.Dir) != "." {
+		os.Remove(filepath.Join(m.root, e.Dir))
+	}
+	return nil
+}
+
+// save writes the config, replacing the file only once it is fully written
+func (m *Manager) save() error {
+	data, err := json.MarshalIndent(m.config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.root, 0755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
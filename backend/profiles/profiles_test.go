@@ -0,0 +1,97 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestLoadCreatesDefault(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(filepath.Join(dir, "profiles.json"))
+	require.NoError(t, err)
+
+	active := m.Active()
+	assert.Equal(t, DefaultName, active.Name)
+	assert.True(t, active.Active)
+	// The default profile uses the data that existed before profiles
+	assert.Equal(t, filepath.Join(dir, "langlearner.db"), m.DatabasePath(active))
+	assert.Equal(t, filepath.Join(dir, "assets"), m.AssetDir(active))
+	assert.FileExists(t, filepath.Join(dir, "profiles.json"))
+}
+
+func TestManager(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	m, err := Load(path)
+	require.NoError(t, err)
+
+	ana, err := m.Create("  Ana García ")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana García", ana.Name)
+	assert.Equal(t, "profiles/ana-garcía", ana.Dir)
+	assert.False(t, ana.Active)
+	other, err := m.Create("ana-garcía!")
+	require.NoError(t, err)
+	assert.Equal(t, "profiles/ana-garcía-2", other.Dir, "directories are never shared")
+	symbols, err := m.Create("???")
+	require.NoError(t, err)
+	assert.Equal(t, "profiles/profile", symbols.Dir)
+
+	_, err = m.Create("ANA GARCÍA")
+	assert.ErrorIs(t, err, types.ErrProfileExists)
+	_, err = m.Create(" ")
+	assert.ErrorIs(t, err, types.ErrProfileNameEmpty)
+
+	require.NoError(t, m.SetActive("ana garcía"))
+	assert.Equal(t, "Ana García", m.Active().Name)
+
+	renamed, err := m.Rename("Ana García", "Ana")
+	require.NoError(t, err)
+	assert.Equal(t, ana.Dir, renamed.Dir)
+	assert.True(t, renamed.Active, "renaming the active profile keeps it active")
+	_, err = m.Rename("Ana", "???")
+	assert.ErrorIs(t, err, types.ErrProfileExists)
+	_, err = m.Rename("Ana", "")
+	assert.ErrorIs(t, err, types.ErrProfileNameEmpty)
+	_, err = m.Rename("Ana", "ANA")
+	assert.NoError(t, err, "changing the case of a name is allowed")
+	_, err = m.Rename("missing", "x")
+	assert.ErrorIs(t, err, types.ErrProfileNotFound)
+
+	// The config is saved on every change
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, m.List(), loaded.List())
+	assert.Equal(t, "ANA", loaded.Active().Name)
+}
+
+func TestDelete(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(filepath.Join(dir, "profiles.json"))
+	require.NoError(t, err)
+	profile, err := m.Create("Work")
+	require.NoError(t, err)
+	for _, path := range []string{
+		m.DatabasePath(profile),
+		m.DatabasePath(profile) + "-wal",
+		m.DatabasePath(profile) + ".v1-20240101-000000.bak",
+		filepath.Join(m.AssetDir(profile), "2c", "2cf2"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+
+	assert.ErrorIs(t, m.Delete(DefaultName), types.ErrProfileActive)
+	assert.ErrorIs(t, m.Delete("missing"), types.ErrProfileNotFound)
+	require.NoError(t, m.Delete("work"))
+
+	_, err = os.Stat(filepath.Join(dir, profile.Dir))
+	assert.True(t, os.IsNotExist(err))
+	_, err = m.Get("Work")
+	assert.ErrorIs(t, err, types.ErrProfileNotFound)
+	assert.Len(t, m.List(), 1)
+}
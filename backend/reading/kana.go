@@ -0,0 +1,134 @@
+package reading
+
+import (
+	"strings"
+	"unicode"
+)
+
+// syllables maps hiragana to Hepburn romaji
+var syllables = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// punctuation maps Japanese punctuation to its ASCII form in romaji
+var punctuation = map[rune]string{
+	'。': ".", '、': ",", '，': ",", '．': ".", '！': "!", '？': "?",
+	'「': "\"", '」': "\"", '『': "\"", '』': "\"", '（': "(", '）': ")",
+	'・': " ", '：': ":", '；': ";", '〜': "~", '　': " ",
+}
+
+// ToHiragana converts the katakana in s to hiragana, other characters are kept
+func ToHiragana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'ァ' && r <= 'ヶ' {
+			return r - 0x60
+		}
+		return r
+	}, s)
+}
+
+// isKanji reports whether r is written with kanji, including the iteration mark
+func isKanji(r rune) bool {
+	return unicode.Is(unicode.Han, r) || r == '々' || r == '〆' || r == 'ヶ'
+}
+
+// hasKanji reports whether s contains a kanji
+func hasKanji(s string) bool {
+	return strings.IndexFunc(s, isKanji) >= 0
+}
+
+// isKana reports whether s is written only in hiragana or katakana
+func isKana(s string) bool {
+	for _, r := range s {
+		if !unicode.In(r, unicode.Hiragana, unicode.Katakana) && r != 'ー' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// romanize converts hiragana and katakana to Hepburn romaji without long vowel
+// marks, other characters are kept as they are
+func romanize(kana string) string {
+	runes := []rune(ToHiragana(kana))
+	var b strings.Builder
+	sokuon := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case 'っ':
+			sokuon = true
+			continue
+		case 'ー':
+			// Lengthen the previous vowel
+			if s := b.String(); s != "" && strings.ContainsRune("aiueo", rune(s[len(s)-1])) {
+				b.WriteByte(s[len(s)-1])
+			}
+			continue
+		}
+		syllable, ok := syllables[r]
+		if !ok {
+			if p, ok := punctuation[r]; ok {
+				b.WriteString(p)
+			} else {
+				b.WriteRune(r)
+			}
+			sokuon = false
+			continue
+		}
+		// Small kana combine with the syllable before them: きゃ kya, しゃ sha, ふぁ fa
+		if i+1 < len(runes) && strings.ContainsRune("ゃゅょぁぃぅぇぉ", runes[i+1]) && r != 'ん' {
+			small := syllables[runes[i+1]]
+			syllable = combine(syllable, small)
+			i++
+		}
+		if r == 'ん' && i+1 < len(runes) {
+			if next, ok := syllables[runes[i+1]]; ok && strings.ContainsRune("aiueoy", rune(next[0])) {
+				syllable = "n'"
+			}
+		}
+		if sokuon {
+			if strings.HasPrefix(syllable, "ch") {
+				b.WriteByte('t')
+			} else if !strings.ContainsRune("aiueon", rune(syllable[0])) {
+				b.WriteByte(syllable[0])
+			}
+			sokuon = false
+		}
+		b.WriteString(syllable)
+	}
+	return b.String()
+}
+
+// combine joins a syllable with the small kana that follows it
+func combine(syllable string, small string) string {
+	switch syllable {
+	case "shi", "chi", "ji":
+		// しゃ sha rather than shya
+		return syllable[:len(syllable)-1] + small[len(small)-1:]
+	case "u":
+		return "w" + small[len(small)-1:]
+	}
+	stem := syllable[:len(syllable)-1]
+	if strings.HasSuffix(syllable, "i") && small[0] == 'y' {
+		return stem + small
+	}
+	return strings.TrimSuffix(stem, "s") + small[len(small)-1:]
+}
@@ -0,0 +1,181 @@
+// Package reading generates the readings of Japanese text: furigana as HTML
+// ruby markup and romaji, using the kagome morphological analyzer with the
+// IPA dictionary embedded in the binary.
+package reading
+
+import (
+	"html"
+	"strings"
+	"sync"
+
+	"github.com/ikawaha/kagome-dict/ipa"
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// Reading holds the readings generated for a text
+type Reading struct {
+	// Furigana is the text as HTML with <ruby> readings over the words written in kanji
+	Furigana string
+	// Romaji is the Hepburn romanization with words separated by spaces
+	Romaji string
+}
+
+// analyzer is shared by all callers, tokenizing is safe for concurrent use
+var (
+	analyzerOnce sync.Once
+	analyzer     *tokenizer.Tokenizer
+	analyzerErr  error
+)
+
+// Read returns the furigana and romaji of text. The dictionary is loaded on the
+// first call, it takes a moment and some memory, so apps without Japanese decks
+// never pay for it.
+func Read(text string) (Reading, error) {
+	analyzerOnce.Do(func() {
+		analyzer, analyzerErr = tokenizer.New(ipa.Dict(), tokenizer.OmitBosEos())
+	})
+	if analyzerErr != nil {
+		return Reading{}, analyzerErr
+	}
+	if strings.TrimSpace(text) == "" {
+		return Reading{}, nil
+	}
+
+	var furigana strings.Builder
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if romaji := romanize(word.String()); romaji != "" {
+			words = append(words, romaji)
+		}
+		word.Reset()
+	}
+	var previous []string
+	for _, token := range analyzer.Tokenize(text) {
+		reading := tokenReading(token)
+		furigana.WriteString(ruby(token.Surface, reading))
+
+		switch {
+		case strings.TrimSpace(token.Surface) == "":
+			flush()
+			previous = nil
+			continue
+		case isPunctuation(token.Surface):
+			// Punctuation sticks to the word before it
+			words = appendToLast(words, word.String(), romanize(token.Surface))
+			word.Reset()
+			previous = nil
+			continue
+		case !attaches(token, previous):
+			flush()
+		}
+		word.WriteString(spoken(token, reading))
+		previous = token.POS()
+	}
+	flush()
+	return Reading{Furigana: furigana.String(), Romaji: strings.Join(words, " ")}, nil
+}
+
+// tokenReading returns the reading of a token in hiragana. Words the dictionary
+// does not know are read as written.
+func tokenReading(token tokenizer.Token) string {
+	reading, ok := token.Reading()
+	if !ok || reading == "" || reading == "*" {
+		return ToHiragana(token.Surface)
+	}
+	return ToHiragana(reading)
+}
+
+// spoken returns the reading of a token for romaji, where the particles は, へ
+// and を are pronounced wa, e and o, also at the end of words like こんにちは
+func spoken(token tokenizer.Token, reading string) string {
+	if pos := token.POS(); len(pos) > 0 && pos[0] == "助詞" {
+		switch token.Surface {
+		case "は":
+			return "わ"
+		case "へ":
+			return "え"
+		case "を":
+			return "お"
+		}
+	}
+	if pronunciation, ok := token.Pronunciation(); ok && strings.HasSuffix(reading, "は") && strings.HasSuffix(pronunciation, "ワ") {
+		return strings.TrimSuffix(reading, "は") + "わ"
+	}
+	return reading
+}
+
+// attaches reports whether a token belongs to the word before it in romaji,
+// like the auxiliaries and endings of a conjugated verb: 食べ|まし|た is
+// tabemashita. Auxiliaries after a noun stay apart, 天気|です is tenki desu.
+func attaches(token tokenizer.Token, previous []string) bool {
+	pos := token.POS()
+	if len(pos) == 0 || len(previous) == 0 {
+		return false
+	}
+	if strings.HasPrefix(token.Surface, "ー") {
+		return true
+	}
+	switch pos[0] {
+	case "助動詞":
+		return previous[0] == "動詞" || previous[0] == "形容詞" || previous[0] == "助動詞"
+	case "動詞", "形容詞", "名詞":
+		return len(pos) > 1 && (pos[1] == "接尾" || pos[1] == "非自立")
+	case "助詞":
+		return len(pos) > 1 && pos[1] == "接続助詞" && (token.Surface == "て" || token.Surface == "で") &&
+			previous[0] != "名詞"
+	}
+	return false
+}
+
+// isPunctuation reports whether s only holds Japanese or ASCII punctuation
+func isPunctuation(s string) bool {
+	for _, r := range s {
+		if _, ok := punctuation[r]; !ok && !strings.ContainsRune(".,!?\"'():;~", r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// appendToLast romanizes the pending word and appends punctuation to it, or to
+// the last finished word when nothing is pending
+func appendToLast(words []string, pending string, punct string) []string {
+	if pending != "" {
+		return append(words, romanize(pending)+punct)
+	}
+	if len(words) == 0 {
+		return []string{punct}
+	}
+	words[len(words)-1] += punct
+	return words
+}
+
+// ruby returns the HTML of a token, with its reading over the kanji. Kana at the
+// start and end of the word that the reading repeats, like the okurigana of
+// 食べる, stay outside the ruby.
+func ruby(surface string, reading string) string {
+	if !hasKanji(surface) {
+		return html.EscapeString(surface)
+	}
+	base := []rune(surface)
+	read := []rune(reading)
+	start := 0
+	for start < len(base) && start < len(read) && !isKanji(base[start]) &&
+		[]rune(ToHiragana(string(base[start])))[0] == read[start] {
+		start++
+	}
+	end := 0
+	for end < len(base)-start && end < len(read)-start && !isKanji(base[len(base)-1-end]) &&
+		[]rune(ToHiragana(string(base[len(base)-1-end])))[0] == read[len(read)-1-end] {
+		end++
+	}
+	core := string(base[start : len(base)-end])
+	coreReading := string(read[start : len(read)-end])
+	if coreReading == "" || coreReading == ToHiragana(core) {
+		return html.EscapeString(surface)
+	}
+	return html.EscapeString(string(base[:start])) +
+		"<ruby>" + html.EscapeString(core) + "<rt>" + html.EscapeString(coreReading) + "</rt></ruby>" +
+		html.EscapeString(string(base[len(base)-end:]))
+}
@@ -0,0 +1,90 @@
+package reading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRomanize(t *testing.T) {
+	tests := map[string]string{
+		"いぬ":      "inu",
+		"カタカナ":    "katakana",
+		"しゃしん":    "shashin",
+		"ちょっと":    "chotto",
+		"まっちゃ":    "matcha",
+		"きんえん":    "kin'en",
+		"こんや":     "kon'ya",
+		"コーヒー":    "koohii",
+		"ファイル":    "fairu",
+		"ティー":     "tii",
+		"ウィキ":     "wiki",
+		"じゅう":     "juu",
+		"ABC 123": "ABC 123",
+		"。、":      ".,",
+	}
+	for kana, romaji := range tests {
+		assert.Equal(t, romaji, romanize(kana), kana)
+	}
+}
+
+func TestRuby(t *testing.T) {
+	assert.Equal(t, "<ruby>日本語<rt>にほんご</rt></ruby>", ruby("日本語", "にほんご"))
+	// Okurigana stay outside the ruby
+	assert.Equal(t, "<ruby>食<rt>た</rt></ruby>べる", ruby("食べる", "たべる"))
+	assert.Equal(t, "お<ruby>茶<rt>ちゃ</rt></ruby>", ruby("お茶", "おちゃ"))
+	assert.Equal(t, "ひらがな", ruby("ひらがな", "ひらがな"))
+	assert.Equal(t, "&lt;b&gt;", ruby("<b>", "<b>"))
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		text     string
+		furigana string
+		romaji   string
+	}{
+		{
+			text:     "私は日本語を勉強しています。",
+			furigana: "<ruby>私<rt>わたし</rt></ruby>は<ruby>日本語<rt>にほんご</rt></ruby>を<ruby>勉強<rt>べんきょう</rt></ruby>しています。",
+			romaji:   "watashi wa nihongo o benkyou shiteimasu.",
+		},
+		{
+			text:     "東京へ行きたい",
+			furigana: "<ruby>東京<rt>とうきょう</rt></ruby>へ<ruby>行<rt>い</rt></ruby>きたい",
+			romaji:   "toukyou e ikitai",
+		},
+		{
+			text:     "今日は、いい天気ですね。",
+			furigana: "<ruby>今日<rt>きょう</rt></ruby>は、いい<ruby>天気<rt>てんき</rt></ruby>ですね。",
+			romaji:   "kyou wa, ii tenki desu ne.",
+		},
+		{
+			text:     "コーヒーを飲みました!",
+			furigana: "コーヒーを<ruby>飲<rt>の</rt></ruby>みました!",
+			romaji:   "koohii o nomimashita!",
+		},
+		{
+			text:     "こんにちは",
+			furigana: "こんにちは",
+			romaji:   "konnichiwa",
+		},
+		{
+			text:     "ABCと123",
+			furigana: "ABCと123",
+			romaji:   "ABC to 123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result, err := Read(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.furigana, result.Furigana)
+			assert.Equal(t, tt.romaji, result.Romaji)
+		})
+	}
+
+	result, err := Read("  ")
+	require.NoError(t, err)
+	assert.Equal(t, Reading{}, result)
+}
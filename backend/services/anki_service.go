@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"langlearner1/backend/anki"
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// AnkiServiceImpl implements the AnkiService interface
+type AnkiServiceImpl struct {
+	ctx    context.Context
+	notes  storage.NoteStorageIf
+	decks  storage.DeckStorage
+	assets *AssetServiceImpl
+}
+
+// NewAnkiService creates a new instance of AnkiService on store that keeps media in the asset store of assetSvc
+func NewAnkiService(store *storage.Store, assetSvc types.AssetServiceIf) types.AnkiServiceIf {
+	return &AnkiServiceImpl{
+		notes:  storage.NewSQLiteNoteStorage(store),
+		decks:  storage.NewSQLiteDeckStorage(store),
+		assets: assetSvc.(*AssetServiceImpl),
+	}
+}
+
+func (s *AnkiServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// Import reads an .apkg file into notes, tags and media
+func (s *AnkiServiceImpl) Import(path string, options types.AnkiImportOptions) (resp types.JSResp) {
+	report, err := s.importPackage(orBackground(s.ctx), path, options, nil)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = report
+	return
+}
+
+// importPackage imports an .apkg file, it stops when ctx is cancelled. The
+// media the notes reference is added to the asset store and linked to them,
+// the notes are written in one transaction. progress counts notes.
+func (s *AnkiServiceImpl) importPackage(ctx context.Context, path string, options types.AnkiImportOptions, progress jobs.Progress) (*types.ImportReport, error) {
+	pkg, err := anki.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	report := &types.ImportReport{
+		DryRun: options.DryRun,
+		Total:  len(pkg.Notes),
+		Errors: []types.ImportError{},
+	}
+	media := &ankiMedia{pkg: pkg, assets: s.assets, dryRun: options.DryRun, stored: map[string]*types.Asset{}, report: report}
+	notes := make([]*types.Note, 0, len(pkg.Notes))
+	links := make([][]types.NoteAsset, 0, len(pkg.Notes))
+	for i, ankiNote := range pkg.Notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i, len(pkg.Notes))
+		}
+		note, err := noteFromAnki(ankiNote, options)
+		if err != nil {
+			report.Skipped++
+			report.Errors = append(report.Errors, types.ImportError{Row: i + 1, Ref: ankiNote.GUID, Message: types.Localize(err)})
+			continue
+		}
+		notes = append(notes, note)
+		links = append(links, media.link(note))
+	}
+
+	if err := setImportReadings(s.decks, s.notes, notes, options.DryRun); err != nil {
+		return nil, err
+	}
+	stats, err := s.notes.Import(notes, options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.ImportStats = stats
+
+	if !options.DryRun {
+		for i, note := range notes {
+			for _, link := range links[i] {
+				attached, err := s.assets.attachAsset(note.ID, link.AssetID, link.Role)
+				if err != nil {
+					return nil, err
+				}
+				note.Assets = append(note.Assets, *attached)
+			}
+		}
+	}
+	if progress != nil {
+		progress(len(pkg.Notes), len(pkg.Notes))
+	}
+	return report, nil
+}
+
+// ankiMedia adds the media files of a package to the asset store as the notes reference them
+type ankiMedia struct {
+	pkg    *anki.Package
+	assets *AssetServiceImpl
+	dryRun bool
+	// stored holds the asset of every media file already added, nil for the ones that failed
+	stored map[string]*types.Asset
+	report *types.ImportReport
+}
+
+// link points the [sound:] and <img src> references of a note at the asset
+// server and returns the links the note needs. The first sound of each side
+// becomes its audio, later sounds keep their Anki reference since a side has
+// one audio asset.
+func (m *ankiMedia) link(note *types.Note) []types.NoteAsset {
+	var links []types.NoteAsset
+	sides := []struct {
+		field *string
+		role  string
+	}{
+		{&note.Front, types.AssetRoleFrontAudio},
+		{&note.Back, types.AssetRoleBackAudio},
+	}
+	for _, side := range sides {
+		hasAudio := false
+		*side.field = anki.ReplaceMedia(*side.field, func(name string, sound bool) string {
+			role := types.AssetRoleImage
+			if sound {
+				if hasAudio {
+					return name
+				}
+				role = side.role
+			}
+			asset := m.add(name, role)
+			if asset == nil || m.dryRun {
+				return name
+			}
+			hasAudio = hasAudio || sound
+			links = append(links, types.NoteAsset{Role: role, AssetID: asset.ID, Asset: *asset})
+			return asset.URL
+		})
+	}
+	return links
+}
+
+// add stores a media file of the package once and returns its asset, failures
+// are reported and return nil. In a dry run nothing is stored.
+func (m *ankiMedia) add(name string, role string) *types.Asset {
+	if asset, ok := m.stored[name]; ok {
+		return asset
+	}
+	asset, err := m.store(name, role)
+	if err != nil {
+		m.report.Errors = append(m.report.Errors, types.ImportError{Ref: name, Message: types.Localize(err)})
+	} else {
+		m.report.MediaCopied++
+	}
+	m.stored[name] = asset
+	return asset
+}
+
+func (m *ankiMedia) store(name string, role string) (*types.Asset, error) {
+	src, err := m.pkg.OpenMedia(name)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	if m.dryRun {
+		return &types.Asset{Name: name}, nil
+	}
+	return m.assets.storeContent(name, src, role)
+}
+
+// Export writes the notes matching the options to an .apkg file together with
+// the media they reference or have attached
+func (s *AnkiServiceImpl) Export(path string, options types.AnkiExportOptions) (resp types.JSResp) {
+	deck := anki.Deck{Name: options.DeckName, Media: map[string]string{}}
+	report := &types.ExportReport{MissingMedia: []string{}}
+	// names maps the asset URLs referenced by the notes to their file names in the package
+	names := map[string]string{}
+	missing := map[string]bool{}
+
+	err := forEachNote(s.notes, options.Filter, func(note types.Note) error {
+		addAssetReferences(&note)
+		for _, field := range []*string{&note.Front, &note.Back} {
+			*field = anki.ReplaceMedia(*field, func(ref string, _ bool) string {
+				if name, ok := names[ref]; ok {
+					return name
+				}
+				if missing[ref] {
+					return ref
+				}
+				name, src, err := s.exportMedia(ref, deck.Media)
+				if err != nil {
+					missing[ref] = true
+					report.MissingMedia = append(report.MissingMedia, ref)
+					return ref
+				}
+				names[ref] = name
+				deck.Media[name] = src
+				return name
+			})
+		}
+		deck.Notes = append(deck.Notes, noteToAnki(note))
+		return nil
+	})
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	if err := anki.Write(path, deck); err != nil {
+		resp.SetError(err)
+		return
+	}
+	report.Notes = len(deck.Notes)
+	report.Media = len(deck.Media)
+
+	resp.Success = 1
+	resp.Data = report
+	return
+}
+
+// addAssetReferences adds the audio and images attached to a note to its
+// fields as Anki shows them: the audio of a side as [sound:] on that side and
+// images as <img> on the back. Assets the fields already reference are skipped.
+func addAssetReferences(note *types.Note) {
+	for _, link := range note.Assets {
+		url := types.AssetURLPrefix + link.Asset.Hash
+		if strings.Contains(note.Front, url) || strings.Contains(note.Back, url) {
+			continue
+		}
+		switch link.Role {
+		case types.AssetRoleFrontAudio:
+			note.Front += "[sound:" + url + "]"
+		case types.AssetRoleBackAudio:
+			note.Back += "[sound:" + url + "]"
+		case types.AssetRoleImage:
+			note.Back += `<img src="` + url + `">`
+		}
+	}
+}
+
+// exportMedia returns the file name in the package and the local file of a
+// media reference, which must be the URL of a stored asset. The name of the
+// asset is used unless media already holds another file of that name.
+func (s *AnkiServiceImpl) exportMedia(ref string, media map[string]string) (string, string, error) {
+	hash, ok := strings.CutPrefix(ref, types.AssetURLPrefix)
+	if !ok {
+		return "", "", types.ErrAssetNotFound
+	}
+	asset, err := s.assets.storage.FindByHash(hash)
+	if err != nil {
+		return "", "", err
+	}
+	if asset == nil {
+		return "", "", types.ErrAssetNotFound
+	}
+	src, err := s.assets.store.Path(hash)
+	if err != nil {
+		return "", "", err
+	}
+	if info, err := os.Stat(src); err != nil || info.IsDir() {
+		return "", "", types.ErrAssetNotFound
+	}
+	name := filepath.Base(asset.Name)
+	if _, taken := media[name]; taken || name == "." || name == string(filepath.Separator) {
+		name = hash[:16] + filepath.Ext(asset.Name)
+	}
+	return name, src, nil
+}
+
+// noteToAnki maps a note onto the basic Anki model, notes that did not come
+// from Anki get a GUID derived from their id so re-exports update them in Anki
+func noteToAnki(note types.Note) anki.Note {
+	guid := note.GUID
+	if guid == "" {
+		guid = fmt.Sprintf("langlearner-%d", note.ID)
+	}
+	tags := make([]string, 0, len(note.Tags))
+	for _, tag := range note.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return anki.Note{
+		GUID:     guid,
+		Fields:   []string{note.Front, note.Back},
+		Tags:     tags,
+		Modified: note.UpdatedAt,
+	}
+}
+
+// noteFromAnki maps an Anki note onto a note with tags referenced by name
+func noteFromAnki(ankiNote anki.Note, options types.AnkiImportOptions) (*types.Note, error) {
+	front, err := ankiField(ankiNote, options.FrontField, 0)
+	if err != nil {
+		return nil, err
+	}
+	back, err := ankiField(ankiNote, options.BackField, 1)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(front) == "" {
+		return nil, types.ErrNoteFrontEmpty
+	}
+
+	note := &types.Note{
+		GUID:   ankiNote.GUID,
+		Front:  front,
+		Back:   back,
+		DeckID: options.DeckID,
+		Tags:   make([]types.Tag, 0, len(ankiNote.Tags)),
+	}
+	if options.CategoryID > 0 {
+		categoryID := options.CategoryID
+		note.CategoryID = &categoryID
+	}
+	for _, tag := range ankiNote.Tags {
+		note.Tags = append(note.Tags, types.Tag{Name: tag})
+	}
+	return note, nil
+}
+
+// ankiField returns the named field, or the field at index when no name is configured
+func ankiField(ankiNote anki.Note, name string, index int) (string, error) {
+	if name == "" {
+		if index < len(ankiNote.Fields) {
+			return ankiNote.Fields[index], nil
+		}
+		return "", nil
+	}
+	for _, fieldName := range ankiNote.FieldNames {
+		if strings.EqualFold(fieldName, name) {
+			return ankiNote.Field(name), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q in note type %q", types.ErrUnknownField, name, ankiNote.Model)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/anki"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+func newTestAnkiService(t *testing.T) (*AnkiServiceImpl, *MockNoteStorage) {
+	store, err := storage.OpenMemory()
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	noteStorage := new(MockNoteStorage)
+	service := &AnkiServiceImpl{
+		notes:  noteStorage,
+		decks:  newJapaneseDeckStorage(),
+		assets: NewAssetService(store, filepath.Join(t.TempDir(), "assets")).(*AssetServiceImpl),
+	}
+	service.Start(context.Background())
+	return service, noteStorage
+}
+
+func TestAnkiExport(t *testing.T) {
+	service, noteStorage := newTestAnkiService(t)
+	audio, err := service.assets.storeContent("inu.mp3", strings.NewReader("ID3"), types.AssetRoleFrontAudio)
+	require.NoError(t, err)
+	other, err := service.assets.storeContent("inu.mp3", strings.NewReader("ID3 other"), types.AssetRoleFrontAudio)
+	require.NoError(t, err)
+
+	image, err := service.assets.storeContent("neko.png", strings.NewReader("\x89PNG\r\n\x1a\n"), types.AssetRoleImage)
+	require.NoError(t, err)
+
+	filter := types.NoteFilter{TagIDs: []int{1}}
+	noteStorage.On("List", filter, 0, exportBatchSize).Return([]types.Note{
+		{ID: 7, Front: "いぬ[sound:" + audio.URL + "]", Back: `<img src="dog.png">`, Tags: []types.Tag{{ID: 1, Name: "n5"}},
+			Assets: []types.NoteAsset{{Role: types.AssetRoleFrontAudio, AssetID: audio.ID, Asset: *audio}}},
+		{ID: 8, GUID: "from-anki", Front: "ねこ[sound:" + audio.URL + "]", Back: "cat",
+			Assets: []types.NoteAsset{
+				{Role: types.AssetRoleBackAudio, AssetID: other.ID, Asset: *other},
+				{Role: types.AssetRoleImage, AssetID: image.ID, Asset: *image},
+			}},
+	}, int64(2), nil)
+
+	path := filepath.Join(t.TempDir(), "deck.apkg")
+	resp := service.Export(path, types.AnkiExportOptions{DeckName: "N5", Filter: filter})
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.Equal(t, &types.ExportReport{Notes: 2, Media: 3, MissingMedia: []string{"dog.png"}}, resp.Data)
+
+	pkg, err := anki.Open(path)
+	require.NoError(t, err)
+	defer pkg.Close()
+	require.Len(t, pkg.Notes, 2)
+	assert.Equal(t, "langlearner-7", pkg.Notes[0].GUID)
+	assert.Equal(t, []string{"n5"}, pkg.Notes[0].Tags)
+	assert.Equal(t, "いぬ[sound:inu.mp3]", pkg.Notes[0].Fields[0], "referenced assets are not added twice")
+	assert.Equal(t, "from-anki", pkg.Notes[1].GUID)
+	// Attached assets are added to the fields, another file of the same name gets a name of its own
+	otherName := other.Hash[:16] + ".mp3"
+	assert.Equal(t, []string{"ねこ[sound:inu.mp3]", `cat[sound:` + otherName + `]<img src="neko.png">`}, pkg.Notes[1].Fields)
+	assert.Equal(t, []string{otherName, "inu.mp3", "neko.png"}, pkg.MediaNames())
+	noteStorage.AssertExpectations(t)
+}
+
+func TestAnkiImport(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "inu.mp3")
+	require.NoError(t, os.WriteFile(audio, []byte("ID3"), 0644))
+	image := filepath.Join(dir, "dog.png")
+	require.NoError(t, os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n"), 0644))
+	path := filepath.Join(dir, "deck.apkg")
+	require.NoError(t, anki.Write(path, anki.Deck{
+		Notes: []anki.Note{
+			{GUID: "g1", Fields: []string{"いぬ[sound:inu.mp3]", `dog<img src="dog.png">`}, Tags: []string{"n5"}},
+			{GUID: "g2", Fields: []string{" ", "empty"}},
+		},
+		Media: map[string]string{"inu.mp3": audio, "dog.png": image, "_unused.css": audio},
+	}))
+
+	t.Run("dry run", func(t *testing.T) {
+		service, noteStorage := newTestAnkiService(t)
+		noteStorage.On("Import", mock.Anything, true).Return(types.ImportStats{Created: 1, TagsCreated: 1}, nil)
+
+		resp := service.Import(path, types.AnkiImportOptions{DryRun: true})
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		report := resp.Data.(*types.ImportReport)
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, 2, report.MediaCopied, "only referenced media is imported")
+		assert.Equal(t, []types.ImportError{{Row: 2, Ref: "g2", Message: types.ErrNoteFrontEmpty.Error()}}, report.Errors)
+		hashes, err := service.assets.store.Hashes()
+		require.NoError(t, err)
+		assert.Empty(t, hashes, "dry run must not store media")
+	})
+
+	t.Run("import", func(t *testing.T) {
+		store, err := storage.OpenMemory()
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		category := types.Category{Name: "Animals"}
+		require.NoError(t, store.DB().Create(&category).Error)
+		service := NewAnkiService(store, NewAssetService(store, filepath.Join(t.TempDir(), "assets"))).(*AnkiServiceImpl)
+		notes := storage.NewSQLiteNoteStorage(store)
+
+		resp := service.Import(path, types.AnkiImportOptions{CategoryID: category.ID})
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		report := resp.Data.(*types.ImportReport)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 2, report.MediaCopied)
+
+		list, _, err := notes.List(types.NoteFilter{}, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		note := list[0]
+		assert.Equal(t, "g1", note.GUID)
+		assert.Equal(t, category.ID, *note.CategoryID)
+		assert.Equal(t, "inu", note.Romaji)
+		links, err := service.assets.storage.NoteAssets(note.ID)
+		require.NoError(t, err)
+		require.Len(t, links, 2)
+		assert.Equal(t, types.AssetRoleFrontAudio, links[0].Role)
+		assert.Equal(t, types.AssetRoleImage, links[1].Role)
+		assert.Equal(t, "いぬ[sound:"+links[0].Asset.URL+"]", note.Front)
+		assert.Equal(t, `dog<img src="`+links[1].Asset.URL+`">`, note.Back)
+		file, err := service.assets.store.Open(links[0].Asset.Hash)
+		require.NoError(t, err)
+		content, err := io.ReadAll(file)
+		file.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "ID3", string(content))
+
+		// Importing again keeps the local title and tags and does not duplicate the media
+		note.Title = "Dog"
+		note.Tags = []types.Tag{{Name: "mine"}}
+		require.NoError(t, store.DB().Create(&note.Tags[0]).Error)
+		require.NoError(t, notes.Update(&note))
+		resp = service.Import(path, types.AnkiImportOptions{})
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		assert.Equal(t, 1, resp.Data.(*types.ImportReport).Updated)
+
+		reimported, err := notes.Get(note.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Dog", reimported.Title)
+		assert.Equal(t, note.Front, reimported.Front)
+		names := []string{}
+		for _, tag := range reimported.Tags {
+			names = append(names, tag.Name)
+		}
+		assert.ElementsMatch(t, []string{"n5", "mine"}, names)
+		links, err = service.assets.storage.NoteAssets(note.ID)
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+		hashes, err := service.assets.store.Hashes()
+		require.NoError(t, err)
+		assert.Len(t, hashes, 2)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		service, noteStorage := newTestAnkiService(t)
+		noteStorage.On("Import", []*types.Note{}, false).Return(types.ImportStats{}, nil)
+
+		resp := service.Import(path, types.AnkiImportOptions{FrontField: "Expression"})
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		report := resp.Data.(*types.ImportReport)
+		assert.Equal(t, 2, report.Skipped)
+		assert.Equal(t, `unknown note field: "Expression" in note type "LangLearner Basic"`, report.Errors[0].Message)
+	})
+}
@@ -2,7 +2,8 @@ package services
 
 import (
 	"context"
-	"fmt"
+
+	"langlearner1/backend/i18n"
 )
 
 // App struct
@@ -23,5 +24,5 @@ func (a *App) startup(ctx context.Context) {
 
 // Greet returns a greeting for the given name
 func (a *App) Greet(name string) string {
-	return fmt.Sprintf("Hello %s, It's show time!", name)
+	return i18n.T("app.greeting", name)
 }
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"langlearner1/backend/assets"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// audioMimeTypes covers the audio formats the system mime table often lacks
+var audioMimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+	".weba": "audio/webm",
+}
+
+// AssetServiceImpl implements the AssetService interface
+type AssetServiceImpl struct {
+	ctx     context.Context
+	storage storage.AssetStorage
+	store   *assets.Store
+	// mu keeps the orphan cleanup from removing a file that is being attached
+	mu sync.Mutex
+}
+
+// NewAssetService creates a new instance of AssetService on store that keeps files in dir
+func NewAssetService(store *storage.Store, dir string) types.AssetServiceIf {
+	return &AssetServiceImpl{
+		storage: storage.NewSQLiteAssetStorage(store),
+		store:   assets.NewStore(dir),
+	}
+}
+
+func (s *AssetServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// Attach copies a local file into the asset store and links it to a note in a role.
+// An audio role holds one file, attaching another replaces it.
+func (s *AssetServiceImpl) Attach(noteID int, path string, role string) (resp types.JSResp) {
+	if !validAssetRole(role) {
+		resp.SetError(types.NewFieldError("role", types.ErrInvalidAssetRole))
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	defer file.Close()
+
+	link, err := s.attachContent(noteID, filepath.Base(path), file, role)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = link
+	return
+}
+
+// attachContent stores content as an asset and links it to a note in a role
+func (s *AssetServiceImpl) attachContent(noteID int, name string, r io.Reader, role string) (*types.NoteAsset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	asset, err := s.addAsset(name, r, role)
+	if err != nil {
+		return nil, err
+	}
+	link := &types.NoteAsset{NoteID: noteID, Role: role, AssetID: asset.ID}
+	if err := s.storage.Attach(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// storeContent stores content as an asset that is linked to notes later
+func (s *AssetServiceImpl) storeContent(name string, r io.Reader, role string) (*types.Asset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAsset(name, r, role)
+}
+
+// attachAsset links a stored asset to a note in a role
+func (s *AssetServiceImpl) attachAsset(noteID int, assetID int, role string) (*types.NoteAsset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link := &types.NoteAsset{NoteID: noteID, Role: role, AssetID: assetID}
+	if err := s.storage.Attach(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// addAsset stores content and returns its asset, reusing the asset of identical content
+func (s *AssetServiceImpl) addAsset(name string, r io.Reader, role string) (*types.Asset, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	mimeType := detectMimeType(name, head)
+	if !assetTypeMatches(role, mimeType) {
+		return nil, types.ErrAssetType
+	}
+
+	hash, size, err := s.store.Put(br)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := s.storage.FindByHash(hash)
+	if err != nil || asset != nil {
+		return asset, err
+	}
+	asset = &types.Asset{Hash: hash, Name: name, MimeType: mimeType, Size: size}
+	if err := s.storage.Create(asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// Detach unlinks an asset from a note and removes it once no note uses it
+func (s *AssetServiceImpl) Detach(noteID int, assetID int, role string) (resp types.JSResp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.storage.Detach(noteID, assetID, role); err != nil {
+		resp.SetError(err)
+		return
+	}
+	if _, err := s.removeAssets([]int{assetID}); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	return
+}
+
+// List returns the assets of a note
+func (s *AssetServiceImpl) List(noteID int) (resp types.JSResp) {
+	links, err := s.storage.NoteAssets(noteID)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = links
+	return
+}
+
+// CleanOrphans removes assets no note uses, such as those of deleted notes,
+// and files left in the store without an asset
+func (s *AssetServiceImpl) CleanOrphans() (resp types.JSResp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orphans, err := s.storage.Orphans()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	ids := make([]int, 0, len(orphans))
+	for _, asset := range orphans {
+		ids = append(ids, asset.ID)
+	}
+	cleanup, err := s.removeAssets(ids)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	known, err := s.storage.Hashes()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	stored, err := s.store.Hashes()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	keep := make(map[string]bool, len(known))
+	for _, hash := range known {
+		keep[hash] = true
+	}
+	for _, hash := range stored {
+		if keep[hash] {
+			continue
+		}
+		freed, err := s.removeFile(hash)
+		if err != nil {
+			resp.SetError(err)
+			return
+		}
+		cleanup.Files++
+		cleanup.Bytes += freed
+	}
+
+	resp.Success = 1
+	resp.Data = cleanup
+	return
+}
+
+// removeAssets deletes the assets among ids that no note uses, together with their files
+func (s *AssetServiceImpl) removeAssets(ids []int) (*types.AssetCleanup, error) {
+	cleanup := &types.AssetCleanup{}
+	deleted, err := s.storage.Delete(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range deleted {
+		freed, err := s.removeFile(asset.Hash)
+		if err != nil {
+			return nil, err
+		}
+		cleanup.Assets++
+		cleanup.Files++
+		cleanup.Bytes += freed
+	}
+	return cleanup, nil
+}
+
+func (s *AssetServiceImpl) removeFile(hash string) (int64, error) {
+	var size int64
+	if path, err := s.store.Path(hash); err == nil {
+		if info, err := os.Stat(path); err == nil {
+			size = info.Size()
+		}
+	}
+	return size, s.store.Remove(hash)
+}
+
+// assetHandler serves asset content by hash under types.AssetURLPrefix. It is
+// a separate type so ServeHTTP does not become a method of the bound service.
+type assetHandler struct {
+	svc *AssetServiceImpl
+}
+
+// NewAssetHandler returns the http.Handler that serves the assets of svc to the frontend
+func NewAssetHandler(svc types.AssetServiceIf) http.Handler {
+	return &assetHandler{svc: svc.(*AssetServiceImpl)}
+}
+
+func (h *assetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hash, ok := strings.CutPrefix(r.URL.Path, types.AssetURLPrefix)
+	if !ok || !assets.ValidHash(hash) || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		http.NotFound(w, r)
+		return
+	}
+	asset, err := h.svc.storage.FindByHash(hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if asset == nil {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := h.svc.store.Open(hash)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", asset.MimeType)
+	// The content of a hash never changes
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeContent(w, r, asset.Name, info.ModTime(), file)
+}
+
+func validAssetRole(role string) bool {
+	switch role {
+	case types.AssetRoleFrontAudio, types.AssetRoleBackAudio, types.AssetRoleImage:
+		return true
+	}
+	return false
+}
+
+// assetTypeMatches reports whether a file of mimeType may be used in role
+func assetTypeMatches(role string, mimeType string) bool {
+	if role == types.AssetRoleImage {
+		return strings.HasPrefix(mimeType, "image/")
+	}
+	return strings.HasPrefix(mimeType, "audio/") || mimeType == "application/ogg"
+}
+
+// audioExtension returns the file extension of an audio MIME type, ".bin" when unknown
+func audioExtension(mimeType string) string {
+	exts := make([]string, 0, len(audioMimeTypes))
+	for ext := range audioMimeTypes {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		if audioMimeTypes[ext] == mimeType {
+			return ext
+		}
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// detectMimeType guesses the type of a file from its extension, then from its first bytes
+func detectMimeType(name string, head []byte) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	mimeType := audioMimeTypes[ext]
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(ext)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return mediaType
+	}
+	return mimeType
+}
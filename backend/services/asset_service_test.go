@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/assets"
+	"langlearner1/backend/types"
+)
+
+// MockAssetStorage is a mock implementation of AssetStorage interface
+type MockAssetStorage struct {
+	mock.Mock
+}
+
+func (m *MockAssetStorage) FindByHash(hash string) (*types.Asset, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*types.Asset), args.Error(1)
+}
+
+func (m *MockAssetStorage) Create(asset *types.Asset) error {
+	args := m.Called(asset)
+	return args.Error(0)
+}
+
+func (m *MockAssetStorage) Attach(link *types.NoteAsset) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func (m *MockAssetStorage) Detach(noteID int, assetID int, role string) error {
+	args := m.Called(noteID, assetID, role)
+	return args.Error(0)
+}
+
+func (m *MockAssetStorage) NoteAssets(noteID int) ([]types.NoteAsset, error) {
+	args := m.Called(noteID)
+	return args.Get(0).([]types.NoteAsset), args.Error(1)
+}
+
+func (m *MockAssetStorage) Orphans() ([]types.Asset, error) {
+	args := m.Called()
+	return args.Get(0).([]types.Asset), args.Error(1)
+}
+
+func (m *MockAssetStorage) Delete(ids []int) ([]types.Asset, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]types.Asset), args.Error(1)
+}
+
+func (m *MockAssetStorage) Hashes() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func newTestAssetService(t *testing.T) (*AssetServiceImpl, *MockAssetStorage) {
+	assetStorage := new(MockAssetStorage)
+	service := &AssetServiceImpl{
+		storage: assetStorage,
+		store:   assets.NewStore(filepath.Join(t.TempDir(), "assets")),
+	}
+	service.Start(context.Background())
+	return service, assetStorage
+}
+
+func TestAssetAttach(t *testing.T) {
+	audio := writeFile(t, "inu.mp3", "ID3 audio")
+
+	t.Run("new asset", func(t *testing.T) {
+		service, assetStorage := newTestAssetService(t)
+		var hash string
+		assetStorage.On("FindByHash", mock.Anything).Run(func(args mock.Arguments) {
+			hash = args.String(0)
+		}).Return((*types.Asset)(nil), nil)
+		assetStorage.On("Create", mock.MatchedBy(func(asset *types.Asset) bool {
+			return asset.Name == "inu.mp3" && asset.MimeType == "audio/mpeg" && asset.Size == 9
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*types.Asset).ID = 5
+		}).Return(nil)
+		assetStorage.On("Attach", &types.NoteAsset{NoteID: 1, Role: types.AssetRoleFrontAudio, AssetID: 5}).Return(nil)
+
+		resp := service.Attach(1, audio, types.AssetRoleFrontAudio)
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		path, err := service.store.Path(hash)
+		require.NoError(t, err)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "ID3 audio", string(content))
+		assetStorage.AssertExpectations(t)
+	})
+
+	t.Run("identical content reuses the asset", func(t *testing.T) {
+		service, assetStorage := newTestAssetService(t)
+		assetStorage.On("FindByHash", mock.Anything).Return(&types.Asset{ID: 3}, nil)
+		assetStorage.On("Attach", &types.NoteAsset{NoteID: 2, Role: types.AssetRoleBackAudio, AssetID: 3}).Return(nil)
+
+		resp := service.Attach(2, audio, types.AssetRoleBackAudio)
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		assetStorage.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		service, assetStorage := newTestAssetService(t)
+		assert.Equal(t, types.ErrInvalidAssetRole.Error(), service.Attach(1, audio, "video").Msg)
+		assert.Equal(t, types.ErrAssetType.Error(), service.Attach(1, audio, types.AssetRoleImage).Msg)
+		assert.Equal(t, 0, service.Attach(1, filepath.Join(t.TempDir(), "missing.mp3"), types.AssetRoleFrontAudio).Success)
+		assetStorage.AssertNotCalled(t, "Attach", mock.Anything)
+	})
+}
+
+func TestAssetDetachAndCleanup(t *testing.T) {
+	service, assetStorage := newTestAssetService(t)
+	hash, _, err := service.store.Put(strings.NewReader("ID3 audio"))
+	require.NoError(t, err)
+	stray, _, err := service.store.Put(strings.NewReader("left over"))
+	require.NoError(t, err)
+
+	assetStorage.On("Detach", 1, 5, types.AssetRoleFrontAudio).Return(nil)
+	assetStorage.On("Delete", []int{5}).Return([]types.Asset{{ID: 5, Hash: hash}}, nil)
+	resp := service.Detach(1, 5, types.AssetRoleFrontAudio)
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	_, err = service.store.Open(hash)
+	assert.True(t, os.IsNotExist(err), "an asset no note uses is removed")
+
+	assetStorage.On("Orphans").Return([]types.Asset{}, nil)
+	assetStorage.On("Delete", []int{}).Return([]types.Asset{}, nil)
+	assetStorage.On("Hashes").Return([]string{}, nil)
+	resp = service.CleanOrphans()
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.Equal(t, &types.AssetCleanup{Files: 1, Bytes: 9}, resp.Data)
+	_, err = service.store.Open(stray)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAssetHandler(t *testing.T) {
+	service, assetStorage := newTestAssetService(t)
+	hash, _, err := service.store.Put(strings.NewReader("ID3 audio"))
+	require.NoError(t, err)
+	assetStorage.On("FindByHash", hash).Return(&types.Asset{Hash: hash, Name: "inu.mp3", MimeType: "audio/mpeg"}, nil)
+	assetStorage.On("FindByHash", mock.Anything).Return((*types.Asset)(nil), nil)
+	handler := NewAssetHandler(service)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, types.AssetURLPrefix+hash, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "ID3 audio", rec.Body.String())
+
+	for _, path := range []string{
+		types.AssetURLPrefix + "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		types.AssetURLPrefix + "../langlearner.db",
+		"/assets/index.js",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// CategoryServiceImpl implements the CategoryService interface
+type CategoryServiceImpl struct {
+	ctx     context.Context
+	storage storage.CategoryStorage
+}
+
+// NewCategoryService creates a new instance of CategoryService on store
+func NewCategoryService(store *storage.Store) types.CategoryServiceIf {
+	return &CategoryServiceImpl{
+		storage: storage.NewSQLiteCategoryStorage(store),
+	}
+}
+
+func (s *CategoryServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// List returns a paginated flat list of categories
+func (s *CategoryServiceImpl) List(page, pageSize int, keyword string) (resp types.JSResp) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+	categories, total, err := s.storage.List(0, keyword, offset, pageSize)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if categories == nil {
+		categories = []types.Category{}
+	}
+
+	resp.Success = 1
+	resp.Data = &types.CategoryList{
+		Total:       int(total),
+		TotalPages:  pageCount(total, pageSize),
+		CurrentPage: page,
+		PageSize:    pageSize,
+		Data:        categories,
+	}
+	return
+}
+
+// Tree returns all categories nested under their parents
+func (s *CategoryServiceImpl) Tree() (resp types.JSResp) {
+	categories, err := s.storage.All()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = buildCategoryTree(categories)
+	return
+}
+
+// Create creates a new category, parentID 0 creates a top level category
+func (s *CategoryServiceImpl) Create(name string, parentID int) (resp types.JSResp) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		resp.SetError(types.NewFieldError("name", types.ErrCategoryNameEmpty))
+		return
+	}
+
+	newCategory := &types.Category{Name: name, ParentID: parentRef(parentID)}
+	if err := s.storage.Create(newCategory); err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = newCategory
+	return
+}
+
+// Update renames a category and moves it under another parent
+func (s *CategoryServiceImpl) Update(id int, name string, parentID int) (resp types.JSResp) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		resp.SetError(types.NewFieldError("name", types.ErrCategoryNameEmpty))
+		return
+	}
+	if id == parentID {
+		resp.SetError(types.NewFieldError("parent_id", types.ErrCategoryCycle))
+		return
+	}
+
+	updatedCategory := &types.Category{ID: id, Name: name, ParentID: parentRef(parentID)}
+	if err := s.storage.Update(updatedCategory); err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = updatedCategory
+	return
+}
+
+// Delete deletes a category that has no children and no notes
+func (s *CategoryServiceImpl) Delete(id int) (resp types.JSResp) {
+	if err := s.storage.Delete(id); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	return
+}
+
+// parentRef converts a parent id from the frontend into a nullable reference
+func parentRef(parentID int) *int {
+	if parentID <= 0 {
+		return nil
+	}
+	return &parentID
+}
+
+// buildCategoryTree nests a flat category list, keeping the input order among siblings
+func buildCategoryTree(categories []types.Category) []types.Category {
+	children := make(map[int][]types.Category)
+	known := make(map[int]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	roots := make([]types.Category, 0)
+	for _, category := range categories {
+		if category.ParentID == nil || !known[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var attach func(nodes []types.Category) []types.Category
+	attach = func(nodes []types.Category) []types.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"langlearner1/backend/types"
+)
+
+// MockCategoryStorage is a mock implementation of CategoryStorage interface
+type MockCategoryStorage struct {
+	mock.Mock
+}
+
+func (m *MockCategoryStorage) List(id int, keyword string, offset int, limit int) ([]types.Category, int64, error) {
+	args := m.Called(id, keyword, offset, limit)
+	return args.Get(0).([]types.Category), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCategoryStorage) All() ([]types.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]types.Category), args.Error(1)
+}
+
+func (m *MockCategoryStorage) Create(category *types.Category) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryStorage) Update(category *types.Category) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryStorage) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestCategoryTree(t *testing.T) {
+	mockStorage := new(MockCategoryStorage)
+	service := &CategoryServiceImpl{storage: mockStorage}
+	service.Start(context.Background())
+
+	mockStorage.On("All").Return([]types.Category{
+		{ID: 1, Name: "Grammar"},
+		{ID: 2, Name: "Particles", ParentID: intPtr(1)},
+		{ID: 3, Name: "Verbs", ParentID: intPtr(1)},
+		{ID: 4, Name: "Godan", ParentID: intPtr(3)},
+		{ID: 5, Name: "Vocabulary"},
+	}, nil)
+
+	result := service.Tree()
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, []types.Category{
+		{ID: 1, Name: "Grammar", Children: []types.Category{
+			{ID: 2, Name: "Particles", ParentID: intPtr(1)},
+			{ID: 3, Name: "Verbs", ParentID: intPtr(1), Children: []types.Category{
+				{ID: 4, Name: "Godan", ParentID: intPtr(3)},
+			}},
+		}},
+		{ID: 5, Name: "Vocabulary"},
+	}, result.Data)
+}
+
+func TestCategoryCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		catName  string
+		parentID int
+		mockErr  error
+		expected *types.Category
+		expErr   error
+	}{
+		{
+			name:     "top level",
+			catName:  " Grammar ",
+			expected: &types.Category{Name: "Grammar"},
+		},
+		{
+			name:     "nested",
+			catName:  "Particles",
+			parentID: 1,
+			expected: &types.Category{Name: "Particles", ParentID: intPtr(1)},
+		},
+		{
+			name:    "empty name",
+			catName: " ",
+			expErr:  types.ErrCategoryNameEmpty,
+		},
+		{
+			name:     "duplicate",
+			catName:  "Grammar",
+			mockErr:  types.ErrCategoryExists,
+			expected: &types.Category{Name: "Grammar"},
+			expErr:   types.ErrCategoryExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockCategoryStorage)
+			service := &CategoryServiceImpl{storage: mockStorage}
+			service.Start(context.Background())
+			if tt.expected != nil {
+				mockStorage.On("Create", tt.expected).Return(tt.mockErr)
+			}
+
+			result := service.Create(tt.catName, tt.parentID)
+
+			if tt.expErr != nil {
+				assert.Equal(t, tt.expErr.Error(), result.Msg)
+			} else {
+				assert.Equal(t, 1, result.Success)
+				assert.Equal(t, tt.expected, result.Data)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCategoryUpdateRejectsSelfParent(t *testing.T) {
+	mockStorage := new(MockCategoryStorage)
+	service := &CategoryServiceImpl{storage: mockStorage}
+	service.Start(context.Background())
+
+	result := service.Update(2, "Verbs", 2)
+	assert.Equal(t, types.ErrCategoryCycle.Error(), result.Msg)
+	mockStorage.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"langlearner1/backend/csvfile"
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+const (
+	defaultTagSeparator = ";"
+	// categoryPathSeparator joins the names of nested categories in a column
+	categoryPathSeparator = "::"
+)
+
+// csvExportHeader lists the exported columns, front and back come first so
+// an exported file imports again with the default column mapping
+var csvExportHeader = []string{"front", "back", "title", "category", "tags"}
+
+// CSVServiceImpl implements the CSVService interface
+type CSVServiceImpl struct {
+	ctx        context.Context
+	notes      storage.NoteStorageIf
+	decks      storage.DeckStorage
+	categories storage.CategoryStorage
+}
+
+// NewCSVService creates a new instance of CSVService on store
+func NewCSVService(store *storage.Store) types.CSVServiceIf {
+	return &CSVServiceImpl{
+		notes:      storage.NewSQLiteNoteStorage(store),
+		decks:      storage.NewSQLiteDeckStorage(store),
+		categories: storage.NewSQLiteCategoryStorage(store),
+	}
+}
+
+func (s *CSVServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// Import reads notes from a CSV or TSV file. Rows that cannot be mapped to a
+// note are reported and skipped, the others are written in one transaction.
+func (s *CSVServiceImpl) Import(path string, options types.CSVImportOptions) (resp types.JSResp) {
+	report, err := s.importFile(orBackground(s.ctx), path, options, nil)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = report
+	return
+}
+
+// importFile imports a CSV or TSV file, it stops reading when ctx is cancelled.
+// The number of rows is not known in advance, so progress gets a total of 0.
+func (s *CSVServiceImpl) importFile(ctx context.Context, path string, options types.CSVImportOptions, progress jobs.Progress) (*types.ImportReport, error) {
+	delimiter, err := parseDelimiter(options.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := csvfile.Open(path, csvfile.Options{Delimiter: delimiter, Encoding: options.Encoding})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	report := &types.ImportReport{DryRun: options.DryRun, Errors: []types.ImportError{}}
+	var columns *csvColumns
+	if !options.HasHeader {
+		if columns, err = newCSVColumns(options.Columns, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	notes := []*types.Note{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(report.Total, 0)
+		}
+		record, line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			report.Total++
+			report.Skipped++
+			report.Errors = append(report.Errors, types.ImportError{Row: line, Message: types.Localize(parseErr.Err)})
+			continue
+		}
+		if columns == nil {
+			if columns, err = newCSVColumns(options.Columns, record); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		report.Total++
+		note, err := columns.note(record, options)
+		if err != nil {
+			report.Skipped++
+			report.Errors = append(report.Errors, types.ImportError{Row: line, Message: types.Localize(err)})
+			continue
+		}
+		notes = append(notes, note)
+	}
+
+	if err := setImportReadings(s.decks, s.notes, notes, options.DryRun); err != nil {
+		return nil, err
+	}
+	stats, err := s.notes.Import(notes, options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.ImportStats = stats
+	if progress != nil {
+		progress(report.Total, report.Total)
+	}
+	return report, nil
+}
+
+// Export writes the notes matching the filter to a CSV or TSV file
+func (s *CSVServiceImpl) Export(path string, options types.CSVExportOptions) (resp types.JSResp) {
+	delimiter, err := parseDelimiter(options.Delimiter)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	categories, err := s.categories.All()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	paths := categoryPaths(categories)
+	separator := options.TagSeparator
+	if separator == "" {
+		separator = defaultTagSeparator
+	}
+
+	writer, err := csvfile.Create(path, csvfile.Options{Delimiter: delimiter, Encoding: options.Encoding})
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	report := &types.ExportReport{MissingMedia: []string{}}
+	if options.Header {
+		err = writer.Write(csvExportHeader)
+	}
+	if err == nil {
+		err = forEachNote(s.notes, options.Filter, func(note types.Note) error {
+			category := ""
+			if note.CategoryID != nil {
+				category = paths[*note.CategoryID]
+			}
+			tags := make([]string, 0, len(note.Tags))
+			for _, tag := range note.Tags {
+				tags = append(tags, tag.Name)
+			}
+			report.Notes++
+			return writer.Write([]string{note.Front, note.Back, note.Title, category, strings.Join(tags, separator)})
+		})
+	}
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = report
+	return
+}
+
+// parseDelimiter accepts a single character or "tab", empty picks one from the file extension
+func parseDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "":
+		return 0, nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return 0, types.ErrInvalidDelimiter
+	}
+	r, _ := utf8.DecodeRuneInString(delimiter)
+	if r == '"' || r == '\r' || r == '\n' {
+		return 0, types.ErrInvalidDelimiter
+	}
+	return r, nil
+}
+
+// csvColumns holds the 0-based column of each note field, -1 when unmapped
+type csvColumns struct {
+	title, front, back, category, tags int
+}
+
+// newCSVColumns resolves the column mapping against the header, which is nil for files without one
+func newCSVColumns(mapping types.CSVColumns, header []string) (*csvColumns, error) {
+	if mapping.Front == "" {
+		mapping.Front = "1"
+	}
+	if mapping.Back == "" {
+		mapping.Back = "2"
+	}
+	columns := &csvColumns{}
+	for _, field := range []struct {
+		ref    string
+		column *int
+	}{
+		{mapping.Title, &columns.title},
+		{mapping.Front, &columns.front},
+		{mapping.Back, &columns.back},
+		{mapping.Category, &columns.category},
+		{mapping.Tags, &columns.tags},
+	} {
+		column, err := resolveCSVColumn(field.ref, header)
+		if err != nil {
+			return nil, err
+		}
+		*field.column = column
+	}
+	return columns, nil
+}
+
+// resolveCSVColumn finds a column by header name, or by its 1-based number
+func resolveCSVColumn(ref string, header []string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return -1, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), ref) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n > 0 {
+		return n - 1, nil
+	}
+	return -1, fmt.Errorf("%w: %q", types.ErrUnknownColumn, ref)
+}
+
+func (c *csvColumns) field(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[column])
+}
+
+// note maps a record onto a note with its tags and category referenced by name
+func (c *csvColumns) note(record []string, options types.CSVImportOptions) (*types.Note, error) {
+	note := &types.Note{
+		Title:  c.field(record, c.title),
+		Front:  c.field(record, c.front),
+		Back:   c.field(record, c.back),
+		DeckID: options.DeckID,
+		Tags:   []types.Tag{},
+	}
+	if note.Front == "" {
+		return nil, types.ErrNoteFrontEmpty
+	}
+
+	separator := options.TagSeparator
+	if separator == "" {
+		separator = defaultTagSeparator
+	}
+	if tags := c.field(record, c.tags); tags != "" {
+		for _, name := range strings.Split(tags, separator) {
+			if name = strings.TrimSpace(name); name != "" {
+				note.Tags = append(note.Tags, types.Tag{Name: name})
+			}
+		}
+	}
+
+	for _, name := range strings.Split(c.field(record, c.category), categoryPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			note.Category = &types.Category{Name: name, Parent: note.Category}
+		}
+	}
+	if note.Category == nil && options.CategoryID > 0 {
+		categoryID := options.CategoryID
+		note.CategoryID = &categoryID
+	}
+	return note, nil
+}
+
+// categoryPaths returns the full path of every category by id
+func categoryPaths(categories []types.Category) map[int]string {
+	byID := make(map[int]types.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	paths := make(map[int]string, len(categories))
+	var pathOf func(id int, depth int) string
+	pathOf = func(id int, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		category := byID[id]
+		path := category.Name
+		// The depth limit guards against a corrupted parent cycle
+		if category.ParentID != nil && depth < len(categories) {
+			path = pathOf(*category.ParentID, depth+1) + categoryPathSeparator + path
+		}
+		paths[id] = path
+		return path
+	}
+	for _, category := range categories {
+		pathOf(category.ID, 0)
+	}
+	return paths
+}
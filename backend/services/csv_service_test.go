@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func newTestCSVService() (*CSVServiceImpl, *MockNoteStorage, *MockCategoryStorage) {
+	noteStorage := new(MockNoteStorage)
+	categoryStorage := new(MockCategoryStorage)
+	service := &CSVServiceImpl{notes: noteStorage, decks: newJapaneseDeckStorage(), categories: categoryStorage}
+	service.Start(context.Background())
+	return service, noteStorage, categoryStorage
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestCSVImport(t *testing.T) {
+	path := writeFile(t, "notes.csv", "Tags,Japanese,English,Lesson\n"+
+		"n5;greeting,こんにちは,hello,Textbook::Lesson 1\n"+
+		",,missing front,\n"+
+		"\"broken,\"x\",y\n"+
+		"n5,ありがとう,thank you,\n")
+
+	service, noteStorage, _ := newTestCSVService()
+	var imported []*types.Note
+	noteStorage.On("Import", mock.Anything, false).Run(func(args mock.Arguments) {
+		imported = args.Get(0).([]*types.Note)
+	}).Return(types.ImportStats{Created: 2, TagsCreated: 2, CategoriesCreated: 2}, nil)
+
+	resp := service.Import(path, types.CSVImportOptions{
+		HasHeader:  true,
+		Columns:    types.CSVColumns{Front: "japanese", Back: "English", Category: "lesson", Tags: "1"},
+		CategoryID: 9,
+	})
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	report := resp.Data.(*types.ImportReport)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 2, report.Created)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, types.ImportError{Row: 3, Message: types.ErrNoteFrontEmpty.Error()}, report.Errors[0])
+	assert.Equal(t, 4, report.Errors[1].Row)
+
+	require.Len(t, imported, 2)
+	assert.Equal(t, "こんにちは", imported[0].Front)
+	assert.Equal(t, "hello", imported[0].Back)
+	assert.Equal(t, "konnichiwa", imported[0].Romaji)
+	assert.Equal(t, []types.Tag{{Name: "n5"}, {Name: "greeting"}}, imported[0].Tags)
+	assert.Nil(t, imported[0].CategoryID)
+	assert.Equal(t, &types.Category{Name: "Lesson 1", Parent: &types.Category{Name: "Textbook"}}, imported[0].Category)
+	assert.Equal(t, intPtr(9), imported[1].CategoryID)
+	assert.Nil(t, imported[1].Category)
+}
+
+func TestCSVImportOptions(t *testing.T) {
+	t.Run("tsv without header", func(t *testing.T) {
+		// Shift-JIS for "いぬ\tdog\n"
+		path := writeFile(t, "notes.tsv", "\x82\xa2\x82\xca\tdog\n")
+		service, noteStorage, _ := newTestCSVService()
+		noteStorage.On("Import", mock.MatchedBy(func(notes []*types.Note) bool {
+			return len(notes) == 1 && notes[0].Front == "いぬ" && notes[0].Back == "dog"
+		}), true).Return(types.ImportStats{Created: 1}, nil)
+
+		resp := service.Import(path, types.CSVImportOptions{Encoding: "shift_jis", DryRun: true})
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		assert.True(t, resp.Data.(*types.ImportReport).DryRun)
+		noteStorage.AssertExpectations(t)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		path := writeFile(t, "notes.csv", "front,back\n")
+		service, noteStorage, _ := newTestCSVService()
+
+		resp := service.Import(path, types.CSVImportOptions{HasHeader: true, Columns: types.CSVColumns{Tags: "labels"}})
+		assert.Equal(t, 0, resp.Success)
+		assert.Contains(t, resp.Msg, types.ErrUnknownColumn.Error())
+
+		resp = service.Import(path, types.CSVImportOptions{Delimiter: ";;"})
+		assert.Equal(t, types.ErrInvalidDelimiter.Error(), resp.Msg)
+
+		resp = service.Import(path, types.CSVImportOptions{Encoding: "klingon"})
+		assert.Equal(t, 0, resp.Success)
+		noteStorage.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCSVExport(t *testing.T) {
+	service, noteStorage, categoryStorage := newTestCSVService()
+	categoryStorage.On("All").Return([]types.Category{
+		{ID: 2, Name: "Lesson 1", ParentID: intPtr(1)},
+		{ID: 1, Name: "Textbook"},
+	}, nil)
+	noteStorage.On("List", types.NoteFilter{}, 0, exportBatchSize).Return([]types.Note{
+		{ID: 1, Front: "你好", Back: "hello, friend", CategoryID: intPtr(2), Tags: []types.Tag{{Name: "hsk1"}, {Name: "greeting"}}},
+		{ID: 2, Title: "thanks", Front: "谢谢", Back: "thank you"},
+	}, int64(2), nil)
+
+	path := filepath.Join(t.TempDir(), "notes.csv")
+	resp := service.Export(path, types.CSVExportOptions{Header: true, Encoding: "gbk", TagSeparator: " "})
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.Equal(t, 2, resp.Data.(*types.ExportReport).Notes)
+
+	// The exported file imports again with the header names as the mapping
+	var imported []*types.Note
+	noteStorage.On("Import", mock.Anything, false).Run(func(args mock.Arguments) {
+		imported = args.Get(0).([]*types.Note)
+	}).Return(types.ImportStats{}, nil)
+	resp = service.Import(path, types.CSVImportOptions{
+		Encoding:     "gbk",
+		HasHeader:    true,
+		Columns:      types.CSVColumns{Title: "title", Category: "category", Tags: "tags"},
+		TagSeparator: " ",
+	})
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	require.Len(t, imported, 2)
+	assert.Equal(t, "hello, friend", imported[0].Back)
+	assert.Equal(t, &types.Category{Name: "Lesson 1", Parent: &types.Category{Name: "Textbook"}}, imported[0].Category)
+	assert.Equal(t, []types.Tag{{Name: "hsk1"}, {Name: "greeting"}}, imported[0].Tags)
+	assert.Equal(t, "thanks", imported[1].Title)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// jobListLimit is the number of jobs List returns
+const jobListLimit = 100
+
+// JobServiceImpl implements the JobService interface
+type JobServiceImpl struct {
+	ctx     context.Context
+	storage storage.JobStorage
+	runner  *jobs.Runner
+}
+
+// JobServiceOption configures a JobServiceImpl
+type JobServiceOption func(*JobServiceImpl)
+
+// WithJobRunner replaces the runner, so tests can set the workers and event emitter
+func WithJobRunner(runner *jobs.Runner) JobServiceOption {
+	return func(s *JobServiceImpl) {
+		s.runner = runner
+	}
+}
+
+// NewJobService creates a new instance of JobService keeping its jobs in store
+// and running the batch operations of the given services in the background
+func NewJobService(store *storage.Store, noteSvc types.NoteServiceIf, csvSvc types.CSVServiceIf, ankiSvc types.AnkiServiceIf, speechSvc types.SpeechServiceIf, options ...JobServiceOption) types.JobServiceIf {
+	s := &JobServiceImpl{
+		storage: storage.NewSQLiteJobStorage(store),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.runner == nil {
+		s.runner = jobs.NewRunner(s.storage)
+	}
+	registerJobs(s.runner, noteSvc.(*NoteServiceImpl), csvSvc.(*CSVServiceImpl), ankiSvc.(*AnkiServiceImpl), speechSvc.(*SpeechServiceImpl))
+	return s
+}
+
+// Start resumes the queued jobs and starts the workers
+func (s *JobServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+	if err := s.runner.Start(ctx); err != nil {
+		println("Error:", err.Error())
+	}
+}
+
+// StopJobs interrupts the running jobs of svc, they are resumed on the next start.
+// It is not a method so it is not bound to the frontend.
+func StopJobs(svc types.JobServiceIf) {
+	svc.(*JobServiceImpl).runner.Stop()
+}
+
+// pauseJobs stops the workers while fn runs and starts them again afterwards,
+// so no job uses the database while it is switched. Interrupted jobs are queued
+// again in the database they came from.
+func pauseJobs(svc types.JobServiceIf, fn func() error) error {
+	s := svc.(*JobServiceImpl)
+	if s.ctx == nil {
+		return fn()
+	}
+	s.runner.Stop()
+	err := fn()
+	if startErr := s.runner.Start(s.ctx); err == nil {
+		err = startErr
+	}
+	return err
+}
+
+// List returns the most recent jobs, optionally only those in a status
+func (s *JobServiceImpl) List(status string) (resp types.JSResp) {
+	list, err := s.storage.List(status, jobListLimit)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = list
+	return
+}
+
+// Get returns a job
+func (s *JobServiceImpl) Get(id int) (resp types.JSResp) {
+	job, err := s.storage.Get(id)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = job
+	return
+}
+
+// Cancel stops a queued or running job
+func (s *JobServiceImpl) Cancel(id int) (resp types.JSResp) {
+	if err := s.runner.Cancel(id); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	return
+}
+
+// ClearFinished deletes the jobs that are done, failed or cancelled
+func (s *JobServiceImpl) ClearFinished() (resp types.JSResp) {
+	count, err := s.storage.DeleteFinished()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = count
+	return
+}
+
+// ImportCSV queues a CSV or TSV import
+func (s *JobServiceImpl) ImportCSV(path string, options types.CSVImportOptions) types.JSResp {
+	return s.submit(types.JobCSVImport, csvImportParams{Path: path, Options: options})
+}
+
+// ImportAnki queues an .apkg import
+func (s *JobServiceImpl) ImportAnki(path string, options types.AnkiImportOptions) types.JSResp {
+	return s.submit(types.JobAnkiImport, ankiImportParams{Path: path, Options: options})
+}
+
+// FillBacks queues translating the front into the empty back of every note with the tag
+func (s *JobServiceImpl) FillBacks(tagID int, source string, target string) types.JSResp {
+	return s.submit(types.JobFillBacks, fillBacksParams{TagID: tagID, Source: source, Target: target})
+}
+
+// FillSpeech queues generating the missing audio of a side for every note with the tag
+func (s *JobServiceImpl) FillSpeech(tagID int, side string, voice string) types.JSResp {
+	return s.submit(types.JobFillSpeech, fillSpeechParams{TagID: tagID, Side: side, Voice: voice})
+}
+
+// FillReadings queues regenerating the furigana and romaji of the notes in a deck, or of all notes for deck 0
+func (s *JobServiceImpl) FillReadings(deckID int) types.JSResp {
+	return s.submit(types.JobFillReadings, fillReadingsParams{DeckID: deckID})
+}
+
+// RebuildSearchIndex queues regenerating the full-text index
+func (s *JobServiceImpl) RebuildSearchIndex() types.JSResp {
+	return s.submit(types.JobRebuildSearch, struct{}{})
+}
+
+func (s *JobServiceImpl) submit(kind string, params interface{}) (resp types.JSResp) {
+	job, err := s.runner.Submit(kind, params)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = job
+	return
+}
+
+// Parameters of the job kinds, stored with each job as JSON
+type (
+	csvImportParams struct {
+		Path    string                 `json:"path"`
+		Options types.CSVImportOptions `json:"options"`
+	}
+	ankiImportParams struct {
+		Path    string                  `json:"path"`
+		Options types.AnkiImportOptions `json:"options"`
+	}
+	fillBacksParams struct {
+		TagID  int    `json:"tag_id"`
+		Source string `json:"source"`
+		Target string `json:"target"`
+	}
+	fillSpeechParams struct {
+		TagID int    `json:"tag_id"`
+		Side  string `json:"side"`
+		Voice string `json:"voice"`
+	}
+	fillReadingsParams struct {
+		DeckID int `json:"deck_id"`
+	}
+)
+
+// registerJobs registers the handler of every job kind with the runner
+func registerJobs(runner *jobs.Runner, noteSvc *NoteServiceImpl, csvSvc *CSVServiceImpl, ankiSvc *AnkiServiceImpl, speechSvc *SpeechServiceImpl) {
+	// CSV rows carry no id to match them on, an import that ran twice would create the notes twice
+	runner.HandleOnce(types.JobCSVImport, func(ctx context.Context, data json.RawMessage, progress jobs.Progress) (interface{}, error) {
+		var params csvImportParams
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, err
+		}
+		return csvSvc.importFile(ctx, params.Path, params.Options, progress)
+	})
+	runner.Handle(types.JobAnkiImport, func(ctx context.Context, data json.RawMessage, progress jobs.Progress) (interface{}, error) {
+		var params ankiImportParams
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, err
+		}
+		return ankiSvc.importPackage(ctx, params.Path, params.Options, progress)
+	})
+	runner.Handle(types.JobFillBacks, func(ctx context.Context, data json.RawMessage, progress jobs.Progress) (interface{}, error) {
+		var params fillBacksParams
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, err
+		}
+		return noteSvc.fillBacks(ctx, params.TagID, params.Source, params.Target, progress)
+	})
+	runner.Handle(types.JobFillSpeech, func(ctx context.Context, data json.RawMessage, progress jobs.Progress) (interface{}, error) {
+		var params fillSpeechParams
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, err
+		}
+		return speechSvc.fillTag(ctx, params.TagID, params.Side, params.Voice, progress)
+	})
+	runner.Handle(types.JobFillReadings, func(ctx context.Context, data json.RawMessage, progress jobs.Progress) (interface{}, error) {
+		var params fillReadingsParams
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, err
+		}
+		return noteSvc.fillReadings(ctx, params.DeckID, progress)
+	})
+	runner.Handle(types.JobRebuildSearch, func(ctx context.Context, data json.RawMessage, progress jobs.Progress) (interface{}, error) {
+		return nil, noteSvc.storage.RebuildSearchIndex()
+	})
+}
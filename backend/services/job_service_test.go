@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// jobEvents collects the job events the runner emits
+type jobEvents struct {
+	mu     sync.Mutex
+	events []types.Job
+}
+
+func (e *jobEvents) emit(ctx context.Context, name string, data ...interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, *data[0].(*types.Job))
+}
+
+func (e *jobEvents) of(id int) []types.Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var events []types.Job
+	for _, event := range e.events {
+		if event.ID == id {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// newTestJobService creates a job service on an in-memory database. The jobs
+// in queued are stored before the workers start, as if a previous run left them.
+func newTestJobService(t *testing.T, queued ...*types.Job) (types.JobServiceIf, *storage.Store, *jobEvents) {
+	store, err := storage.OpenMemory()
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	jobStorage := storage.NewSQLiteJobStorage(store)
+	for _, job := range queued {
+		require.NoError(t, jobStorage.Create(job))
+	}
+	events := &jobEvents{}
+	noteSvc := NewNoteServiceImpl(store)
+	assetSvc := NewAssetService(store, t.TempDir())
+	runner := jobs.NewRunner(jobStorage, jobs.WithEmitter(events.emit))
+	jobSvc := NewJobService(store, noteSvc, NewCSVService(store), NewAnkiService(store, assetSvc), NewSpeechService(store, assetSvc), WithJobRunner(runner))
+	jobSvc.(*JobServiceImpl).Start(context.Background())
+	t.Cleanup(func() { StopJobs(jobSvc) })
+	return jobSvc, store, events
+}
+
+// waitForJob waits until a job is finished and returns it
+func waitForJob(t *testing.T, jobSvc types.JobServiceIf, id int) *types.Job {
+	var job *types.Job
+	require.Eventually(t, func() bool {
+		resp := jobSvc.Get(id)
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		job = resp.Data.(*types.Job)
+		return job.Finished()
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestJobServiceImportCSV(t *testing.T) {
+	jobSvc, store, events := newTestJobService(t)
+	path := writeFile(t, "notes.csv", "Japanese,English\n犬,dog\n猫,cat\n")
+
+	resp := jobSvc.ImportCSV(path, types.CSVImportOptions{
+		HasHeader: true,
+		Columns:   types.CSVColumns{Front: "japanese", Back: "english"},
+	})
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	submitted := resp.Data.(*types.Job)
+	assert.Equal(t, types.JobCSVImport, submitted.Kind)
+	var params csvImportParams
+	require.NoError(t, json.Unmarshal(submitted.Params, &params))
+	assert.Equal(t, path, params.Path)
+	assert.Equal(t, "english", params.Options.Columns.Back)
+
+	job := waitForJob(t, jobSvc, submitted.ID)
+	require.Equal(t, types.JobDone, job.Status, job.Error)
+	assert.Equal(t, 2, job.Progress)
+	assert.Equal(t, 2, job.Total)
+	var report types.ImportReport
+	require.NoError(t, json.Unmarshal(job.Result, &report))
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Created)
+
+	var count int64
+	require.NoError(t, store.DB().Model(&types.Note{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// The frontend sees the job queued, running and finished with its progress
+	emitted := events.of(job.ID)
+	require.Greater(t, len(emitted), 2)
+	assert.Equal(t, types.JobQueued, emitted[0].Status)
+	assert.Equal(t, types.JobRunning, emitted[1].Status)
+	last := emitted[len(emitted)-1]
+	assert.Equal(t, types.JobDone, last.Status)
+	assert.Equal(t, 2, last.Progress)
+	assert.Equal(t, 2, last.Total)
+}
+
+func TestJobServiceFailsInvalidParams(t *testing.T) {
+	job := &types.Job{Kind: types.JobFillReadings, Status: types.JobQueued, Params: json.RawMessage(`{"deck_id":"first"}`)}
+	jobSvc, _, _ := newTestJobService(t, job)
+
+	failed := waitForJob(t, jobSvc, job.ID)
+	assert.Equal(t, types.JobFailed, failed.Status)
+	assert.Contains(t, failed.Error, "deck_id")
+}
+
+func TestJobServiceFailsInterruptedCSVImport(t *testing.T) {
+	params, err := json.Marshal(csvImportParams{Path: "notes.csv"})
+	require.NoError(t, err)
+	csvJob := &types.Job{Kind: types.JobCSVImport, Status: types.JobRunning, Params: params, StartedAt: 1}
+	rebuildJob := &types.Job{Kind: types.JobRebuildSearch, Status: types.JobRunning, Params: json.RawMessage(`{}`), StartedAt: 1}
+	jobSvc, _, _ := newTestJobService(t, csvJob, rebuildJob)
+
+	// An import may have created part of its notes, it is not run again
+	failed := waitForJob(t, jobSvc, csvJob.ID)
+	assert.Equal(t, types.JobFailed, failed.Status)
+	assert.Equal(t, types.Localize(types.ErrJobInterrupted), failed.Error)
+	assert.NotZero(t, failed.FinishedAt)
+
+	resumed := waitForJob(t, jobSvc, rebuildJob.ID)
+	assert.Equal(t, types.JobDone, resumed.Status, resumed.Error)
+}
+
+func TestPauseJobsRestartsAfterError(t *testing.T) {
+	jobSvc, _, _ := newTestJobService(t)
+
+	switchErr := errors.New("switch failed")
+	var paused int
+	err := pauseJobs(jobSvc, func() error {
+		// No job runs while the workers are paused
+		resp := jobSvc.RebuildSearchIndex()
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		paused = resp.Data.(*types.Job).ID
+		time.Sleep(20 * time.Millisecond)
+		resp = jobSvc.Get(paused)
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		assert.Equal(t, types.JobQueued, resp.Data.(*types.Job).Status)
+		return switchErr
+	})
+	assert.Equal(t, switchErr, err)
+
+	// The workers were started again although fn failed
+	job := waitForJob(t, jobSvc, paused)
+	assert.Equal(t, types.JobDone, job.Status, job.Error)
+	resp := jobSvc.RebuildSearchIndex()
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	job = waitForJob(t, jobSvc, resp.Data.(*types.Job).ID)
+	assert.Equal(t, types.JobDone, job.Status, job.Error)
+}
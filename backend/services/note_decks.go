@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"langlearner1/backend/translate"
+	"langlearner1/backend/types"
+)
+
+// ListDecks returns all decks ordered by name
+func (s *NoteServiceImpl) ListDecks() (resp types.JSResp) {
+	decks, err := s.decks.All()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = decks
+	return
+}
+
+// CreateDeck creates a deck for a language pair
+func (s *NoteServiceImpl) CreateDeck(payload types.DeckPayload) (resp types.JSResp) {
+	deck, err := deckFromPayload(payload)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if err := s.decks.Create(deck); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = deck
+	return
+}
+
+// UpdateDeck renames a deck or changes its languages, the notes keep their text
+func (s *NoteServiceImpl) UpdateDeck(id int, payload types.DeckPayload) (resp types.JSResp) {
+	deck, err := deckFromPayload(payload)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	deck.ID = id
+	if err := s.decks.Update(deck); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = deck
+	return
+}
+
+// DeleteDeck deletes a deck that has no notes
+func (s *NoteServiceImpl) DeleteDeck(id int) (resp types.JSResp) {
+	if err := s.decks.Delete(id); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	return
+}
+
+// deckFromPayload validates a payload and stores its languages in canonical form
+func deckFromPayload(payload types.DeckPayload) (*types.Deck, error) {
+	var invalid []error
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		invalid = append(invalid, types.NewFieldError("name", types.ErrDeckNameEmpty))
+	}
+	source, err := translate.NormalizeLanguage(payload.Source)
+	if err != nil {
+		invalid = append(invalid, types.NewFieldError("source", err))
+	}
+	target, err := translate.NormalizeLanguage(payload.Target)
+	if err != nil {
+		invalid = append(invalid, types.NewFieldError("target", err))
+	}
+	if len(invalid) > 0 {
+		return nil, errors.Join(invalid...)
+	}
+	return &types.Deck{Name: name, Source: source, Target: target}, nil
+}
+
+// deckLanguages returns source and target, taking the languages that are
+// empty from the deck of note
+func deckLanguages(note *types.Note, source string, target string) (string, string) {
+	if note.Deck == nil {
+		return source, target
+	}
+	if source == "" {
+		source = note.Deck.Source
+	}
+	if target == "" {
+		target = note.Deck.Target
+	}
+	return source, target
+}
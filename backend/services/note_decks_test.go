@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+// MockDeckStorage is a mock implementation of DeckStorage interface
+type MockDeckStorage struct {
+	mock.Mock
+}
+
+func (m *MockDeckStorage) All() ([]types.Deck, error) {
+	args := m.Called()
+	return args.Get(0).([]types.Deck), args.Error(1)
+}
+
+func (m *MockDeckStorage) Get(id int) (*types.Deck, error) {
+	args := m.Called(id)
+	return args.Get(0).(*types.Deck), args.Error(1)
+}
+
+func (m *MockDeckStorage) Default() (*types.Deck, error) {
+	args := m.Called()
+	return args.Get(0).(*types.Deck), args.Error(1)
+}
+
+func (m *MockDeckStorage) Create(deck *types.Deck) error {
+	args := m.Called(deck)
+	return args.Error(0)
+}
+
+func (m *MockDeckStorage) Update(deck *types.Deck) error {
+	args := m.Called(deck)
+	return args.Error(0)
+}
+
+func (m *MockDeckStorage) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// japaneseDeck is the default deck of the service tests
+var japaneseDeck = &types.Deck{ID: 1, Name: types.DefaultDeckName, Source: "ja", Target: "zh-CN"}
+
+// newJapaneseDeckStorage returns a deck storage holding only japaneseDeck
+func newJapaneseDeckStorage() *MockDeckStorage {
+	deckStorage := new(MockDeckStorage)
+	deckStorage.On("Default").Return(japaneseDeck, nil)
+	deckStorage.On("Get", japaneseDeck.ID).Return(japaneseDeck, nil)
+	return deckStorage
+}
+
+func TestNoteDecks(t *testing.T) {
+	deckStorage := new(MockDeckStorage)
+	service := NewNoteServiceImpl(nil, WithDeckStorage(deckStorage)).(*NoteServiceImpl)
+	service.Start(context.Background())
+
+	deckStorage.On("Create", &types.Deck{Name: "Korean", Source: "ko", Target: "en-US"}).Return(nil).Once()
+	result := service.CreateDeck(types.DeckPayload{Name: " Korean ", Source: "ko", Target: "en_us"})
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, "en-US", result.Data.(*types.Deck).Target, "languages are stored in canonical form")
+
+	assert.Equal(t, types.ErrDeckNameEmpty.Error(), service.CreateDeck(types.DeckPayload{Source: "ko", Target: "en"}).Msg)
+	assert.Contains(t, service.CreateDeck(types.DeckPayload{Name: "Korean", Source: "korean?", Target: "en"}).Msg,
+		types.ErrInvalidLanguage.Error())
+
+	deckStorage.On("Update", &types.Deck{ID: 2, Name: "Spanish", Source: "es", Target: "ja"}).Return(nil).Once()
+	result = service.UpdateDeck(2, types.DeckPayload{Name: "Spanish", Source: "es", Target: "ja"})
+	require.Equal(t, 1, result.Success, result.Msg)
+
+	deckStorage.On("Delete", 2).Return(types.ErrDeckInUse).Once()
+	assert.Equal(t, types.ErrDeckInUse.Error(), service.DeleteDeck(2).Msg)
+
+	deckStorage.On("All").Return([]types.Deck{{ID: 1, Name: "Default"}}, nil).Once()
+	result = service.ListDecks()
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Len(t, result.Data, 1)
+	deckStorage.AssertExpectations(t)
+}
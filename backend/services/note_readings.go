@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/reading"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// japanese reports whether notes of a deck with the source language are
+// written in Japanese, ja or a regional variant of it
+func japanese(language string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	return base == "ja"
+}
+
+// rubyTag matches a word of furigana markup with its reading
+var rubyTag = regexp.MustCompile(`<ruby>[^<]*<rt>([^<]*)</rt></ruby>`)
+
+// furiganaReading returns the text of furigana markup with the words in kanji
+// replaced by their reading, which spells the whole text in kana
+func furiganaReading(furigana string) string {
+	return rubyTag.ReplaceAllString(furigana, "$1")
+}
+
+// setReadings generates the furigana and romaji of the front of a note in a
+// Japanese deck, the readings of notes in other decks are cleared
+func setReadings(note *types.Note, deck *types.Deck) error {
+	note.Furigana, note.Romaji = "", ""
+	if deck == nil || !japanese(deck.Source) {
+		return nil
+	}
+	result, err := reading.Read(plainText(note.Front))
+	if err != nil {
+		return err
+	}
+	note.Furigana, note.Romaji = result.Furigana, result.Romaji
+	return nil
+}
+
+// setImportReadings generates the readings of imported notes for the deck each
+// one ends up in: a note whose GUID is already stored stays in its deck, a new
+// note goes to its DeckID or the default deck. A dry run writes nothing, so the
+// readings are not generated.
+func setImportReadings(decks storage.DeckStorage, notes storage.NoteStorageIf, imported []*types.Note, dryRun bool) error {
+	if dryRun || len(imported) == 0 {
+		return nil
+	}
+	guids := []string{}
+	for _, note := range imported {
+		if note.GUID != "" {
+			guids = append(guids, note.GUID)
+		}
+	}
+	existing := map[string]int{}
+	if len(guids) > 0 {
+		var err error
+		if existing, err = notes.DeckIDs(guids); err != nil {
+			return err
+		}
+	}
+
+	loaded := map[int]*types.Deck{}
+	for _, note := range imported {
+		deckID := note.DeckID
+		if id, ok := existing[note.GUID]; ok {
+			deckID = id
+		}
+		deck, ok := loaded[deckID]
+		if !ok {
+			var err error
+			if deckID != 0 {
+				deck, err = decks.Get(deckID)
+			} else {
+				deck, err = decks.Default()
+			}
+			if err != nil {
+				return err
+			}
+			loaded[deckID] = deck
+		}
+		if err := setReadings(note, deck); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setNoteReadings generates the readings of a note that is about to be saved
+func (s *NoteServiceImpl) setNoteReadings(note *types.Note) error {
+	deck, err := s.noteDeck(note)
+	if err != nil {
+		return err
+	}
+	return setReadings(note, deck)
+}
+
+// noteDeck returns the deck a note is saved to: the deck it names, otherwise
+// the deck an existing note is in or the default deck for a new note
+func (s *NoteServiceImpl) noteDeck(note *types.Note) (*types.Deck, error) {
+	if note.DeckID != 0 {
+		return s.decks.Get(note.DeckID)
+	}
+	if note.ID == 0 {
+		return s.decks.Default()
+	}
+	existing, err := s.storage.Get(note.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Deck != nil {
+		return existing.Deck, nil
+	}
+	return s.decks.Get(existing.DeckID)
+}
+
+// fillReadings regenerates the readings of every note in the deck, or of all
+// notes for deck 0, stopping when ctx is cancelled. It is used after a deck
+// changes its language and for the notes that existed before readings.
+func (s *NoteServiceImpl) fillReadings(ctx context.Context, deckID int, progress jobs.Progress) (*types.BatchReport, error) {
+	var notes []types.Note
+	err := forEachNote(s.storage, types.NoteFilter{DeckID: deckID}, func(note types.Note) error {
+		notes = append(notes, note)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.BatchReport{Total: len(notes), Errors: []types.BatchError{}}
+	for i := range notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i, len(notes))
+		}
+		changed, err := s.updateReadings(&notes[i])
+		if err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, types.BatchError{NoteID: notes[i].ID, Message: types.Localize(err)})
+			continue
+		}
+		if changed {
+			report.Updated++
+		} else {
+			report.Skipped++
+		}
+	}
+	if progress != nil {
+		progress(len(notes), len(notes))
+	}
+	return report, nil
+}
+
+// updateReadings regenerates the readings of a stored note and saves them when
+// they changed, reporting whether they did
+func (s *NoteServiceImpl) updateReadings(note *types.Note) (bool, error) {
+	deck := note.Deck
+	if deck == nil {
+		var err error
+		if deck, err = s.decks.Get(note.DeckID); err != nil {
+			return false, err
+		}
+	}
+	furigana, romaji := note.Furigana, note.Romaji
+	if err := setReadings(note, deck); err != nil {
+		return false, err
+	}
+	if note.Furigana == furigana && note.Romaji == romaji {
+		return false, nil
+	}
+	return true, s.storage.UpdateReadings(note)
+}
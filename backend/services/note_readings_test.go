@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestNoteReadings(t *testing.T) {
+	noteStorage := new(MockNoteStorage)
+	deckStorage := newJapaneseDeckStorage()
+	english := &types.Deck{ID: 2, Source: "en", Target: "ja"}
+	deckStorage.On("Get", 2).Return(english, nil)
+	deckStorage.On("Get", 9).Return((*types.Deck)(nil), types.ErrDeckNotFound)
+	service := &NoteServiceImpl{storage: noteStorage, decks: deckStorage}
+	service.Start(context.Background())
+	noteStorage.On("Create", mock.Anything).Return(nil)
+
+	result := service.Create(types.NotePayload{Front: "<b>日本語</b>を話す[sound:a.mp3]"})
+	require.Equal(t, 1, result.Success, result.Msg)
+	note := result.Data.(*types.Note)
+	assert.Equal(t, "<ruby>日本語<rt>にほんご</rt></ruby>を<ruby>話<rt>はな</rt></ruby>す", note.Furigana)
+	assert.Equal(t, "nihongo o hanasu", note.Romaji)
+
+	// Notes of decks in other languages have no readings
+	result = service.Create(types.NotePayload{Front: "to speak", DeckID: 2})
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Empty(t, result.Data.(*types.Note).Romaji)
+
+	result = service.Create(types.NotePayload{Front: "話す", DeckID: 9})
+	assert.Equal(t, types.ErrDeckNotFound.Error(), result.Msg)
+	noteStorage.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestNoteFillReadings(t *testing.T) {
+	noteStorage := new(MockNoteStorage)
+	deckStorage := newJapaneseDeckStorage()
+	english := &types.Deck{ID: 2, Source: "en", Target: "ja"}
+	deckStorage.On("Get", 2).Return(english, nil)
+	service := &NoteServiceImpl{storage: noteStorage, decks: deckStorage}
+	notes := []types.Note{
+		{ID: 1, Front: "犬", DeckID: 1, Deck: japaneseDeck},
+		{ID: 2, Front: "猫", DeckID: 1, Furigana: "<ruby>猫<rt>ねこ</rt></ruby>", Romaji: "neko"},
+		// The deck changed its language, the readings are stale
+		{ID: 3, Front: "dog", DeckID: 2, Deck: english, Romaji: "dogu"},
+	}
+	noteStorage.On("List", types.NoteFilter{}, 0, exportBatchSize).Return(notes, int64(len(notes)), nil)
+	noteStorage.On("UpdateReadings", mock.MatchedBy(func(note *types.Note) bool {
+		return note.ID == 1 && note.Furigana == "<ruby>犬<rt>いぬ</rt></ruby>" && note.Romaji == "inu"
+	})).Return(nil)
+	noteStorage.On("UpdateReadings", mock.MatchedBy(func(note *types.Note) bool {
+		return note.ID == 3 && note.Romaji == ""
+	})).Return(nil)
+
+	report, err := service.fillReadings(context.Background(), 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Updated)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 0, report.Failed)
+	noteStorage.AssertExpectations(t)
+}
+
+func TestSetImportReadings(t *testing.T) {
+	noteStorage := new(MockNoteStorage)
+	deckStorage := newJapaneseDeckStorage()
+	english := &types.Deck{ID: 2, Source: "en", Target: "ja"}
+	deckStorage.On("Get", 2).Return(english, nil)
+	// The note with GUID "moved" was stored before and moved to the English deck since
+	noteStorage.On("DeckIDs", []string{"moved", "new"}).Return(map[string]int{"moved": 2}, nil)
+
+	notes := []*types.Note{
+		{GUID: "moved", Front: "犬", DeckID: japaneseDeck.ID},
+		{GUID: "new", Front: "犬", DeckID: japaneseDeck.ID},
+		{Front: "猫"},
+	}
+	require.NoError(t, setImportReadings(deckStorage, noteStorage, notes, false))
+	assert.Empty(t, notes[0].Romaji, "an existing note keeps its deck")
+	assert.Equal(t, "inu", notes[1].Romaji)
+	assert.Equal(t, "neko", notes[2].Romaji, "a note without a deck goes to the default deck")
+
+	// A dry run writes nothing and leaves the readings alone
+	require.NoError(t, setImportReadings(deckStorage, noteStorage, []*types.Note{{GUID: "x", Front: "犬"}}, true))
+	noteStorage.AssertNumberOfCalls(t, "DeckIDs", 1)
+}
@@ -2,31 +2,79 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"langlearner1/backend/jobs"
 	"langlearner1/backend/storage"
+	"langlearner1/backend/translate"
 	"langlearner1/backend/types"
-	"math"
+	"regexp"
 	"strings"
 )
 
 // NoteServiceImpl implements the NoteService interface
 type NoteServiceImpl struct {
-	ctx     context.Context
-	storage storage.NoteStorageIf
+	ctx          context.Context
+	storage      storage.NoteStorageIf
+	decks        storage.DeckStorage
+	translations storage.TranslationStorage
+	translators  *translate.Pairs
 }
 
-// NewNoteServiceImpl creates a new instance of NoteService
-func NewNoteServiceImpl() types.NoteServiceIf {
-	return &NoteServiceImpl{
-		storage: storage.NewSQLiteNoteStorage(),
+// NoteServiceOption configures a NoteServiceImpl
+type NoteServiceOption func(*NoteServiceImpl)
+
+// WithTranslators sets the translation providers used by Translate and FillBack
+func WithTranslators(pairs *translate.Pairs) NoteServiceOption {
+	return func(s *NoteServiceImpl) {
+		s.translators = pairs
+	}
+}
+
+// WithNoteStorage replaces the SQLite note storage
+func WithNoteStorage(notes storage.NoteStorageIf) NoteServiceOption {
+	return func(s *NoteServiceImpl) {
+		s.storage = notes
+	}
+}
+
+// WithDeckStorage replaces the SQLite deck storage
+func WithDeckStorage(decks storage.DeckStorage) NoteServiceOption {
+	return func(s *NoteServiceImpl) {
+		s.decks = decks
+	}
+}
+
+// WithTranslationStorage replaces the SQLite translation cache
+func WithTranslationStorage(translations storage.TranslationStorage) NoteServiceOption {
+	return func(s *NoteServiceImpl) {
+		s.translations = translations
 	}
 }
 
+// NewNoteServiceImpl creates a new instance of NoteService on store, without
+// WithTranslators only cached translations are available
+func NewNoteServiceImpl(store *storage.Store, options ...NoteServiceOption) types.NoteServiceIf {
+	s := &NoteServiceImpl{
+		storage:      storage.NewSQLiteNoteStorage(store),
+		decks:        storage.NewSQLiteDeckStorage(store),
+		translations: storage.NewSQLiteTranslationStorage(store),
+		translators:  &translate.Pairs{},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
 func (s *NoteServiceImpl) Start(ctx context.Context) {
 	s.ctx = ctx
 }
 
-// List returns a paginated list of tags
-func (s *NoteServiceImpl) List(page, pageSize int, keyword string) (resp types.JSResp) {
+// List returns a paginated list of notes
+func (s *NoteServiceImpl) List(page, pageSize int, filter types.NoteFilter) (resp types.JSResp) {
 	if page < 1 {
 		page = 1
 	}
@@ -34,65 +82,87 @@ func (s *NoteServiceImpl) List(page, pageSize int, keyword string) (resp types.J
 		pageSize = 10
 	}
 	offset := (page - 1) * pageSize
-	// Get all list from storage
-	list, err := s.storage.List(0, keyword, offset, pageSize)
+	list, total, err := s.storage.List(filter, offset, pageSize)
 	if err != nil {
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
-
-	// Filter list by keyword
-	filteredNotes := make([]types.Note, 0)
-	for _, item := range list {
-		if keyword == "" || strings.Contains(strings.ToLower(item.Front), strings.ToLower(keyword)) {
-			filteredNotes = append(filteredNotes, item)
-		}
+	if list == nil {
+		list = []types.Note{}
 	}
 
-	total := len(filteredNotes)
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+	resp.Success = 1
+	resp.Data = &types.NoteList{
+		Total:       int(total),
+		TotalPages:  pageCount(total, pageSize),
+		CurrentPage: page,
+		PageSize:    pageSize,
+		Data:        list,
+	}
+	return
+}
 
-	// Calculate pagination bounds
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if end > total {
-		end = total
+// ListByCursor returns the notes after cursor, pass an empty cursor for the first page.
+// Unlike List it does not count matches, which keeps paging through large decks fast.
+func (s *NoteServiceImpl) ListByCursor(cursor string, pageSize int, filter types.NoteFilter) (resp types.JSResp) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	after, err := decodeNoteCursor(cursor)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	// Fetch one extra note to learn whether another page follows
+	list, err := s.storage.ListAfter(filter, after, pageSize+1)
+	if err != nil {
+		resp.SetError(err)
+		return
 	}
 
-	// Get paginated data
-	var data []types.Note
-	if start < total {
-		data = filteredNotes[start:end]
-	} else {
-		data = []types.Note{}
+	page := &types.NotePage{Data: list}
+	if page.Data == nil {
+		page.Data = []types.Note{}
+	}
+	if len(page.Data) > pageSize {
+		page.Data = page.Data[:pageSize]
+		last := page.Data[pageSize-1]
+		page.HasMore = true
+		page.NextCursor = encodeNoteCursor(types.NoteCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
 	}
 	resp.Success = 1
-	resp.Data = &types.NoteList{
-		Total:       total,
-		TotalPages:  totalPages,
-		CurrentPage: page,
-		PageSize:    pageSize,
-		Data:        data,
+	resp.Data = page
+	return
+}
+
+// Get returns a single note with its tags
+func (s *NoteServiceImpl) Get(id int) (resp types.JSResp) {
+	note, err := s.storage.Get(id)
+	if err != nil {
+		resp.SetError(err)
+		return
 	}
+	resp.Success = 1
+	resp.Data = note
 	return
 }
 
-// Create creates a new tag
-func (s *NoteServiceImpl) Create(name string) (resp types.JSResp) {
-	if name == "" {
-		resp.Msg = "tag name cannot be empty"
+// Create creates a new note from the given payload
+func (s *NoteServiceImpl) Create(payload types.NotePayload) (resp types.JSResp) {
+	if strings.TrimSpace(payload.Front) == "" {
+		resp.SetError(types.NewFieldError("front", types.ErrNoteFrontEmpty))
 		return
 	}
 
-	// Create new tag
-	newNote := &types.Note{Front: name}
+	// Create new note
+	newNote := noteFromPayload(payload)
+	if err := s.setNoteReadings(newNote); err != nil {
+		resp.SetError(err)
+		return
+	}
 	err := s.storage.Create(newNote)
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint") {
-			resp.Msg = "标签已存在"
-			return
-		}
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
 	resp.Success = 1
@@ -100,21 +170,23 @@ func (s *NoteServiceImpl) Create(name string) (resp types.JSResp) {
 	return
 }
 
-// Update updates an existing tag
-func (s *NoteServiceImpl) Update(id int, frontCont, backCont string) (resp types.JSResp) {
-	if frontCont == "" {
-		resp.Msg = "tag frontCont cannot be empty"
+// Update replaces the editable fields of an existing note
+func (s *NoteServiceImpl) Update(id int, payload types.NotePayload) (resp types.JSResp) {
+	if strings.TrimSpace(payload.Front) == "" {
+		resp.SetError(types.NewFieldError("front", types.ErrNoteFrontEmpty))
 		return
 	}
 
-	// Update tag
-	updatedNote := &types.Note{ID: id, Front: frontCont}
-	if backCont != "" {
-		updatedNote.Back = backCont
+	// Update note
+	updatedNote := noteFromPayload(payload)
+	updatedNote.ID = id
+	if err := s.setNoteReadings(updatedNote); err != nil {
+		resp.SetError(err)
+		return
 	}
 	err := s.storage.Update(updatedNote)
 	if err != nil {
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
 	resp.Success = 1
@@ -122,14 +194,288 @@ func (s *NoteServiceImpl) Update(id int, frontCont, backCont string) (resp types
 	return
 }
 
-// Delete deletes a tag
+// Delete deletes a note
 func (s *NoteServiceImpl) Delete(id int) (resp types.JSResp) {
 	err := s.storage.Delete(id)
 	if err != nil {
-		resp.Msg = err.Error()
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	return
+}
+
+// AddTags links additional tags to a note
+func (s *NoteServiceImpl) AddTags(id int, tagIDs []int) types.JSResp {
+	return s.changeTags(id, tagIDs, s.storage.AddTags)
+}
+
+// RemoveTags unlinks tags from a note
+func (s *NoteServiceImpl) RemoveTags(id int, tagIDs []int) types.JSResp {
+	return s.changeTags(id, tagIDs, s.storage.RemoveTags)
+}
+
+// SetTags replaces all tags of a note
+func (s *NoteServiceImpl) SetTags(id int, tagIDs []int) types.JSResp {
+	return s.changeTags(id, tagIDs, s.storage.ReplaceTags)
+}
+
+// Search returns notes ranked by relevance to the query with highlighted snippets
+func (s *NoteServiceImpl) Search(query string, limit int) (resp types.JSResp) {
+	if limit < 1 {
+		limit = 20
+	}
+	results, err := s.storage.Search(query, limit)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = results
+	return
+}
+
+// RebuildSearchIndex regenerates the full-text search index
+func (s *NoteServiceImpl) RebuildSearchIndex() (resp types.JSResp) {
+	if err := s.storage.RebuildSearchIndex(); err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	return
+}
+
+// changeTags applies a tag change through storage and returns the updated note
+func (s *NoteServiceImpl) changeTags(id int, tagIDs []int, apply func(noteID int, tagIDs []int) error) (resp types.JSResp) {
+	if err := apply(id, tagIDs); err != nil {
+		resp.SetError(err)
+		return
+	}
+	return s.Get(id)
+}
+
+// noteFromPayload builds a note whose tags reference the payload tag IDs
+func noteFromPayload(payload types.NotePayload) *types.Note {
+	note := &types.Note{
+		Title:  strings.TrimSpace(payload.Title),
+		Front:  payload.Front,
+		Back:   payload.Back,
+		DeckID: payload.DeckID,
+		Tags:   make([]types.Tag, 0, len(payload.TagIDs)),
+	}
+	if payload.CategoryID > 0 {
+		categoryID := payload.CategoryID
+		note.CategoryID = &categoryID
+	}
+	for _, tagID := range payload.TagIDs {
+		note.Tags = append(note.Tags, types.Tag{ID: tagID})
+	}
+	return note
+}
+
+// Translate translates text from source to target using the configured providers and the cache
+func (s *NoteServiceImpl) Translate(text string, source string, target string) (resp types.JSResp) {
+	translation, err := s.translate(orBackground(s.ctx), text, source, target)
+	if err != nil {
+		resp.SetError(err)
 		return
 	}
 
 	resp.Success = 1
+	resp.Data = translation
 	return
 }
+
+// FillBack sets the back of a note to the translation of its front, an existing
+// back is only replaced when overwrite is set. Empty languages come from the note's deck.
+func (s *NoteServiceImpl) FillBack(id int, source string, target string, overwrite bool) (resp types.JSResp) {
+	note, err := s.storage.Get(id)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if strings.TrimSpace(note.Back) == "" || overwrite {
+		source, target := deckLanguages(note, source, target)
+		translation, err := s.translate(orBackground(s.ctx), plainText(note.Front), source, target)
+		if err != nil {
+			resp.SetError(err)
+			return
+		}
+		note.Back = translation.Result
+		if err := s.storage.Update(note); err != nil {
+			resp.SetError(err)
+			return
+		}
+	}
+
+	resp.Success = 1
+	resp.Data = note
+	return
+}
+
+// fillBacks translates the front into the empty back of every note with the tag,
+// stopping when ctx is cancelled. Empty languages come from the deck of each note.
+func (s *NoteServiceImpl) fillBacks(ctx context.Context, tagID int, source string, target string, progress jobs.Progress) (*types.BatchReport, error) {
+	// Collect the notes first, updating them while paging would shift the pages
+	var notes []types.Note
+	err := forEachNote(s.storage, types.NoteFilter{TagIDs: []int{tagID}}, func(note types.Note) error {
+		notes = append(notes, note)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.BatchReport{Total: len(notes), Errors: []types.BatchError{}}
+	fixedLanguages := source != "" && target != ""
+	for i := range notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i, len(notes))
+		}
+		note := &notes[i]
+		if strings.TrimSpace(note.Back) != "" {
+			report.Skipped++
+			continue
+		}
+		source, target := deckLanguages(note, source, target)
+		translation, err := s.translate(ctx, plainText(note.Front), source, target)
+		if errors.Is(err, types.ErrTextEmpty) {
+			report.Skipped++
+			continue
+		}
+		if err == nil {
+			note.Back = translation.Result
+			err = s.storage.Update(note)
+		}
+		if err != nil {
+			// With the languages given, a missing provider or a bad language fails
+			// every note the same way. Notes of other decks may still succeed.
+			sameForAll := fixedLanguages && (errors.Is(err, types.ErrNoTranslator) || errors.Is(err, types.ErrInvalidLanguage))
+			if sameForAll || ctx.Err() != nil {
+				return nil, err
+			}
+			report.Failed++
+			report.Errors = append(report.Errors, types.BatchError{NoteID: note.ID, Message: types.Localize(err)})
+			continue
+		}
+		report.Updated++
+	}
+	if progress != nil {
+		progress(len(notes), len(notes))
+	}
+	return report, nil
+}
+
+// translate returns the cached translation of text, asking the provider
+// configured for the language pair on a cache miss
+func (s *NoteServiceImpl) translate(ctx context.Context, text string, source string, target string) (*types.Translation, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, types.ErrTextEmpty
+	}
+	source, err := translate.NormalizeLanguage(source)
+	if err != nil {
+		return nil, err
+	}
+	target, err = translate.NormalizeLanguage(target)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := s.translations.Get(text, source, target)
+	if err != nil || cached != nil {
+		return cached, err
+	}
+
+	translator, timeout, err := s.translators.For(source, target)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := translator.Translate(ctx, text, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &types.Translation{
+		Source:   source,
+		Target:   target,
+		Text:     text,
+		Result:   result,
+		Provider: translator.Name(),
+	}
+	if err := s.translations.Save(translation); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+var (
+	soundTag = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	// lineTag matches the tags that separate words, other tags are removed without a gap
+	lineTag = regexp.MustCompile(`(?i)<(br|/p|/div|/li)\b[^>]*>`)
+	htmlTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+// orBackground returns ctx, or a background context when a service was not started
+func orBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// plainText strips media references and markup from a note field before it is translated
+func plainText(field string) string {
+	field = soundTag.ReplaceAllString(field, "")
+	field = lineTag.ReplaceAllString(field, " ")
+	field = htmlTag.ReplaceAllString(field, "")
+	return strings.Join(strings.Fields(html.UnescapeString(field)), " ")
+}
+
+// exportBatchSize is the number of notes read per query during an export
+const exportBatchSize = 500
+
+// forEachNote calls fn for every note matching the filter, reading them in batches
+func forEachNote(notes storage.NoteStorageIf, filter types.NoteFilter, fn func(note types.Note) error) error {
+	for offset := 0; ; offset += exportBatchSize {
+		batch, total, err := notes.List(filter, offset, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, note := range batch {
+			if err := fn(note); err != nil {
+				return err
+			}
+		}
+		if len(batch) == 0 || int64(offset+len(batch)) >= total {
+			return nil
+		}
+	}
+}
+
+// encodeNoteCursor turns a list position into an opaque cursor string
+func encodeNoteCursor(cursor types.NoteCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.UpdatedAt, cursor.ID)))
+}
+
+// decodeNoteCursor parses a cursor produced by encodeNoteCursor, empty means the first page
+func decodeNoteCursor(cursor string) (types.NoteCursor, error) {
+	var after types.NoteCursor
+	if cursor == "" {
+		return after, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return after, types.ErrInvalidCursor
+	}
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &after.UpdatedAt, &after.ID); err != nil || after.ID < 1 {
+		return after, types.ErrInvalidCursor
+	}
+	return after, nil
+}
@@ -0,0 +1,462 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/translate"
+	"langlearner1/backend/translate/translatetest"
+	"langlearner1/backend/types"
+)
+
+// MockNoteStorage is a mock implementation of NoteStorageIf interface
+type MockNoteStorage struct {
+	mock.Mock
+}
+
+func (m *MockNoteStorage) List(filter types.NoteFilter, offset int, limit int) ([]types.Note, int64, error) {
+	args := m.Called(filter, offset, limit)
+	return args.Get(0).([]types.Note), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockNoteStorage) ListAfter(filter types.NoteFilter, cursor types.NoteCursor, limit int) ([]types.Note, error) {
+	args := m.Called(filter, cursor, limit)
+	return args.Get(0).([]types.Note), args.Error(1)
+}
+
+func (m *MockNoteStorage) Get(id int) (*types.Note, error) {
+	args := m.Called(id)
+	return args.Get(0).(*types.Note), args.Error(1)
+}
+
+func (m *MockNoteStorage) Create(note *types.Note) error {
+	args := m.Called(note)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) Update(note *types.Note) error {
+	args := m.Called(note)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) UpdateReadings(note *types.Note) error {
+	args := m.Called(note)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) AddTags(noteID int, tagIDs []int) error {
+	args := m.Called(noteID, tagIDs)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) RemoveTags(noteID int, tagIDs []int) error {
+	args := m.Called(noteID, tagIDs)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) ReplaceTags(noteID int, tagIDs []int) error {
+	args := m.Called(noteID, tagIDs)
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) Search(keyword string, limit int) ([]types.NoteSearchResult, error) {
+	args := m.Called(keyword, limit)
+	return args.Get(0).([]types.NoteSearchResult), args.Error(1)
+}
+
+func (m *MockNoteStorage) RebuildSearchIndex() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockNoteStorage) Import(notes []*types.Note, dryRun bool) (types.ImportStats, error) {
+	args := m.Called(notes, dryRun)
+	return args.Get(0).(types.ImportStats), args.Error(1)
+}
+
+func (m *MockNoteStorage) DeckIDs(guids []string) (map[string]int, error) {
+	args := m.Called(guids)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// MockTranslationStorage is a mock implementation of TranslationStorage interface
+type MockTranslationStorage struct {
+	mock.Mock
+}
+
+func (m *MockTranslationStorage) Get(text string, source string, target string) (*types.Translation, error) {
+	args := m.Called(text, source, target)
+	return args.Get(0).(*types.Translation), args.Error(1)
+}
+
+func (m *MockTranslationStorage) Save(translation *types.Translation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
+
+func TestNoteCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  types.NotePayload
+		mockErr  error
+		expected *types.Note
+		expErr   error
+	}{
+		{
+			name: "success",
+			payload: types.NotePayload{
+				Title:      " greeting ",
+				Front:      "こんにちは",
+				Back:       "你好",
+				CategoryID: 4,
+				TagIDs:     []int{1, 2},
+			},
+			expected: &types.Note{
+				Title:      "greeting",
+				Front:      "こんにちは",
+				Back:       "你好",
+				Furigana:   "こんにちは",
+				Romaji:     "konnichiwa",
+				CategoryID: intPtr(4),
+				Tags:       []types.Tag{{ID: 1}, {ID: 2}},
+			},
+		},
+		{
+			name:    "empty front",
+			payload: types.NotePayload{Front: "  ", Back: "你好"},
+			expErr:  types.ErrNoteFrontEmpty,
+		},
+		{
+			name:    "unknown tag",
+			payload: types.NotePayload{Front: "こんにちは", TagIDs: []int{9}},
+			mockErr: types.ErrTagNotFound,
+			expErr:  types.ErrTagNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockNoteStorage)
+			service := &NoteServiceImpl{storage: mockStorage, decks: newJapaneseDeckStorage()}
+			service.Start(context.Background())
+			if tt.expected != nil || tt.mockErr != nil {
+				mockStorage.On("Create", mock.AnythingOfType("*types.Note")).Return(tt.mockErr)
+			}
+
+			result := service.Create(tt.payload)
+
+			if tt.expErr != nil {
+				assert.Equal(t, 0, result.Success)
+				assert.Equal(t, tt.expErr.Error(), result.Msg)
+			} else {
+				assert.Equal(t, 1, result.Success)
+				assert.Equal(t, tt.expected, result.Data)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNoteUpdate(t *testing.T) {
+	mockStorage := new(MockNoteStorage)
+	service := &NoteServiceImpl{storage: mockStorage, decks: newJapaneseDeckStorage()}
+	service.Start(context.Background())
+
+	// The readings follow the deck the note is already in
+	mockStorage.On("Get", 3).Return(&types.Note{ID: 3, DeckID: 1, Deck: japaneseDeck}, nil)
+	mockStorage.On("Get", 4).Return((*types.Note)(nil), types.ErrNoteNotFound)
+	mockStorage.On("Update", mock.MatchedBy(func(note *types.Note) bool {
+		return note.ID == 3
+	})).Return(nil)
+
+	result := service.Update(3, types.NotePayload{Front: "ありがとう", Back: "谢谢", TagIDs: []int{5}})
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, &types.Note{
+		ID: 3, Front: "ありがとう", Back: "谢谢", Furigana: "ありがとう", Romaji: "arigatou", Tags: []types.Tag{{ID: 5}},
+	}, result.Data)
+
+	result = service.Update(4, types.NotePayload{Front: "ありがとう"})
+	assert.Equal(t, types.ErrNoteNotFound.Error(), result.Msg)
+
+	result = service.Update(3, types.NotePayload{})
+	assert.Equal(t, types.ErrNoteFrontEmpty.Error(), result.Msg)
+	mockStorage.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestNoteGet(t *testing.T) {
+	mockStorage := new(MockNoteStorage)
+	service := &NoteServiceImpl{storage: mockStorage}
+	service.Start(context.Background())
+
+	mockStorage.On("Get", 1).Return(&types.Note{ID: 1, Front: "はい"}, nil)
+	mockStorage.On("Get", 2).Return((*types.Note)(nil), types.ErrNoteNotFound)
+	mockStorage.On("Get", 3).Return((*types.Note)(nil), errors.New("storage error"))
+
+	result := service.Get(1)
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, &types.Note{ID: 1, Front: "はい"}, result.Data)
+
+	result = service.Get(2)
+	assert.Equal(t, types.ErrNoteNotFound.Error(), result.Msg)
+
+	result = service.Get(3)
+	assert.Equal(t, "storage error", result.Msg)
+}
+
+func TestNoteTags(t *testing.T) {
+	mockStorage := new(MockNoteStorage)
+	service := &NoteServiceImpl{storage: mockStorage}
+	service.Start(context.Background())
+
+	tagged := types.Note{ID: 1, Front: "はい", Tags: []types.Tag{{ID: 2, Name: "n5"}}}
+	mockStorage.On("AddTags", 1, []int{2}).Return(nil)
+	mockStorage.On("RemoveTags", 1, []int{3}).Return(nil)
+	mockStorage.On("ReplaceTags", 1, []int{2}).Return(nil)
+	mockStorage.On("AddTags", 2, []int{2}).Return(types.ErrNoteNotFound)
+	mockStorage.On("Get", 1).Return(&tagged, nil)
+
+	for _, result := range []types.JSResp{
+		service.AddTags(1, []int{2}),
+		service.RemoveTags(1, []int{3}),
+		service.SetTags(1, []int{2}),
+	} {
+		assert.Equal(t, 1, result.Success)
+		assert.Equal(t, &tagged, result.Data)
+	}
+
+	result := service.AddTags(2, []int{2})
+	assert.Equal(t, types.ErrNoteNotFound.Error(), result.Msg)
+	mockStorage.AssertExpectations(t)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestNoteList(t *testing.T) {
+	notes := []types.Note{{ID: 5}, {ID: 4}, {ID: 3}, {ID: 2}, {ID: 1}}
+	filter := types.NoteFilter{TagIDs: []int{1}}
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		mockData []types.Note
+		expected *types.NoteList
+	}{
+		{
+			name:     "first page",
+			page:     1,
+			pageSize: 2,
+			mockData: notes[0:2],
+			expected: &types.NoteList{Total: 5, TotalPages: 3, CurrentPage: 1, PageSize: 2, Data: notes[0:2]},
+		},
+		{
+			name:     "second page",
+			page:     2,
+			pageSize: 2,
+			mockData: notes[2:4],
+			expected: &types.NoteList{Total: 5, TotalPages: 3, CurrentPage: 2, PageSize: 2, Data: notes[2:4]},
+		},
+		{
+			name:     "last page",
+			page:     3,
+			pageSize: 2,
+			mockData: notes[4:],
+			expected: &types.NoteList{Total: 5, TotalPages: 3, CurrentPage: 3, PageSize: 2, Data: notes[4:]},
+		},
+		{
+			name:     "past the end",
+			page:     4,
+			pageSize: 2,
+			mockData: nil,
+			expected: &types.NoteList{Total: 5, TotalPages: 3, CurrentPage: 4, PageSize: 2, Data: []types.Note{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockNoteStorage)
+			service := &NoteServiceImpl{storage: mockStorage}
+			service.Start(context.Background())
+			mockStorage.On("List", filter, (tt.page-1)*tt.pageSize, tt.pageSize).Return(tt.mockData, int64(5), nil)
+
+			result := service.List(tt.page, tt.pageSize, filter)
+			assert.Equal(t, 1, result.Success)
+			assert.Equal(t, tt.expected, result.Data)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNoteListByCursor(t *testing.T) {
+	mockStorage := new(MockNoteStorage)
+	service := &NoteServiceImpl{storage: mockStorage}
+	service.Start(context.Background())
+
+	first := []types.Note{{ID: 9, UpdatedAt: 300}, {ID: 8, UpdatedAt: 200}, {ID: 7, UpdatedAt: 200}}
+	mockStorage.On("ListAfter", types.NoteFilter{}, types.NoteCursor{}, 3).Return(first, nil)
+	mockStorage.On("ListAfter", types.NoteFilter{}, types.NoteCursor{UpdatedAt: 200, ID: 8}, 3).Return(first[2:], nil)
+
+	result := service.ListByCursor("", 2, types.NoteFilter{})
+	page := result.Data.(*types.NotePage)
+	assert.Equal(t, first[:2], page.Data)
+	assert.True(t, page.HasMore)
+	assert.NotEmpty(t, page.NextCursor)
+
+	result = service.ListByCursor(page.NextCursor, 2, types.NoteFilter{})
+	page = result.Data.(*types.NotePage)
+	assert.Equal(t, first[2:], page.Data)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+
+	result = service.ListByCursor("not a cursor", 2, types.NoteFilter{})
+	assert.Equal(t, types.ErrInvalidCursor.Error(), result.Msg)
+}
+
+func newTestTranslatingNoteService(t *testing.T) (*NoteServiceImpl, *MockNoteStorage, *MockTranslationStorage, *translatetest.Server) {
+	server := translatetest.NewServer(map[string]string{"ja:zh:犬が走る": "狗在跑"})
+	t.Cleanup(server.Close)
+	pairs, err := translate.NewPairs([]translate.PairConfig{
+		{Source: "ja", Target: translate.Any, Config: translate.Config{Provider: "libretranslate", Endpoint: server.URL}},
+	})
+	require.NoError(t, err)
+
+	noteStorage := new(MockNoteStorage)
+	translationStorage := new(MockTranslationStorage)
+	service := NewNoteServiceImpl(nil,
+		WithTranslators(pairs),
+		WithNoteStorage(noteStorage),
+		WithTranslationStorage(translationStorage),
+	).(*NoteServiceImpl)
+	service.Start(context.Background())
+	return service, noteStorage, translationStorage, server
+}
+
+func TestNoteTranslate(t *testing.T) {
+	service, _, translationStorage, server := newTestTranslatingNoteService(t)
+	translationStorage.On("Get", "犬が走る", "ja", "zh-CN").Return((*types.Translation)(nil), nil).Once()
+	translationStorage.On("Save", mock.MatchedBy(func(tr *types.Translation) bool {
+		return tr.Result == "狗在跑" && tr.Provider == "libretranslate"
+	})).Return(nil).Once()
+
+	result := service.Translate(" 犬が走る ", "ja", "zh_cn")
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, "狗在跑", result.Data.(*types.Translation).Result)
+
+	// A cached translation does not reach the provider
+	translationStorage.On("Get", "犬が走る", "ja", "zh-CN").Return(&types.Translation{Result: "狗在跑"}, nil)
+	result = service.Translate("犬が走る", "ja", "zh-CN")
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, 1, server.Requests())
+	translationStorage.AssertExpectations(t)
+
+	translationStorage.On("Get", "run", "en", "ja").Return((*types.Translation)(nil), nil)
+	assert.Equal(t, types.ErrNoTranslator.Error(), service.Translate("run", "en", "ja").Msg)
+	assert.Equal(t, types.ErrTextEmpty.Error(), service.Translate("  ", "ja", "en").Msg)
+	assert.Contains(t, service.Translate("run", "english?", "ja").Msg, types.ErrInvalidLanguage.Error())
+}
+
+func TestNoteFillBack(t *testing.T) {
+	service, noteStorage, translationStorage, _ := newTestTranslatingNoteService(t)
+	translationStorage.On("Get", "犬が走る", "ja", "zh-CN").Return((*types.Translation)(nil), nil)
+	translationStorage.On("Save", mock.Anything).Return(nil)
+	noteStorage.On("Get", 1).Return(&types.Note{ID: 1, Front: "<b>犬が</b>走る[sound:inu.mp3]"}, nil)
+	noteStorage.On("Get", 2).Return(&types.Note{ID: 2, Front: "犬が走る", Back: "dog runs"}, nil)
+	noteStorage.On("Update", mock.MatchedBy(func(note *types.Note) bool {
+		return note.Back == "狗在跑"
+	})).Return(nil)
+
+	result := service.FillBack(1, "ja", "zh-CN", false)
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, "狗在跑", result.Data.(*types.Note).Back)
+
+	// An existing back is kept unless overwrite is set
+	result = service.FillBack(2, "ja", "zh-CN", false)
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, "dog runs", result.Data.(*types.Note).Back)
+	noteStorage.AssertNumberOfCalls(t, "Update", 1)
+
+	result = service.FillBack(2, "ja", "zh-CN", true)
+	require.Equal(t, 1, result.Success, result.Msg)
+	noteStorage.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestNoteFillBacks(t *testing.T) {
+	service, noteStorage, translationStorage, _ := newTestTranslatingNoteService(t)
+	translationStorage.On("Get", "犬が走る", "ja", "zh-CN").Return((*types.Translation)(nil), nil)
+	translationStorage.On("Get", "猫", "ja", "zh-CN").Return((*types.Translation)(nil), nil)
+	translationStorage.On("Save", mock.Anything).Return(nil)
+	notes := []types.Note{
+		{ID: 1, Front: "犬が走る"},
+		{ID: 2, Front: "犬が走る", Back: "dog runs"},
+		{ID: 3, Front: "<br>"},
+		{ID: 4, Front: "猫"},
+	}
+	filter := types.NoteFilter{TagIDs: []int{5}}
+	noteStorage.On("List", filter, 0, exportBatchSize).Return(notes, int64(len(notes)), nil)
+	noteStorage.On("Update", mock.MatchedBy(func(note *types.Note) bool {
+		return note.ID == 1 && note.Back == "狗在跑"
+	})).Return(nil)
+	noteStorage.On("Update", mock.MatchedBy(func(note *types.Note) bool {
+		return note.ID == 4
+	})).Return(errors.New("database is locked"))
+
+	var progress [][2]int
+	report, err := service.fillBacks(context.Background(), 5, "ja", "zh-CN", func(done int, total int) {
+		progress = append(progress, [2]int{done, total})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, types.BatchError{NoteID: 4, Message: "database is locked"}, report.Errors[0])
+	assert.Equal(t, [2]int{4, 4}, progress[len(progress)-1])
+	noteStorage.AssertNumberOfCalls(t, "Update", 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = service.fillBacks(ctx, 5, "ja", "zh-CN", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = service.fillBacks(context.Background(), 5, "ja", "xx-invalid-", nil)
+	assert.ErrorIs(t, err, types.ErrInvalidLanguage)
+}
+
+func TestNoteFillBackFromDeck(t *testing.T) {
+	service, noteStorage, translationStorage, _ := newTestTranslatingNoteService(t)
+	translationStorage.On("Get", "犬が走る", "ja", "zh-CN").Return((*types.Translation)(nil), nil)
+	translationStorage.On("Get", "개", "ko", "es").Return((*types.Translation)(nil), nil)
+	translationStorage.On("Save", mock.Anything).Return(nil)
+	japanese := &types.Deck{ID: 1, Source: "ja", Target: "zh-CN"}
+	korean := &types.Deck{ID: 2, Source: "ko", Target: "es"}
+	noteStorage.On("Get", 1).Return(&types.Note{ID: 1, Front: "犬が走る", DeckID: 1, Deck: japanese}, nil)
+	noteStorage.On("Update", mock.Anything).Return(nil)
+
+	result := service.FillBack(1, "", "", false)
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, "狗在跑", result.Data.(*types.Note).Back)
+
+	// Without a provider for one deck's pair the notes of other decks are still filled
+	notes := []types.Note{
+		{ID: 2, Front: "개", DeckID: 2, Deck: korean},
+		{ID: 3, Front: "犬が走る", DeckID: 1, Deck: japanese},
+	}
+	filter := types.NoteFilter{TagIDs: []int{5}}
+	noteStorage.On("List", filter, 0, exportBatchSize).Return(notes, int64(len(notes)), nil)
+	report, err := service.fillBacks(context.Background(), 5, "", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.Errors[0].NoteID)
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// Default slideshow timings in milliseconds, until the settings set others
+const (
+	defaultQuestionTimeout = 3000
+	defaultAnswerTimeout   = 4000
+)
+
+// PracticeServiceImpl implements the PracticeService interface
+type PracticeServiceImpl struct {
+	ctx     context.Context
+	storage storage.PracticeStorage
+	notes   storage.NoteStorageIf
+	now     func() time.Time
+
+	mu              sync.RWMutex
+	questionTimeout int
+	answerTimeout   int
+}
+
+// NewPracticeService creates a new instance of PracticeService on store
+func NewPracticeService(store *storage.Store) types.PracticeServiceIf {
+	return &PracticeServiceImpl{
+		storage: storage.NewSQLitePracticeStorage(store),
+		notes:   storage.NewSQLiteNoteStorage(store),
+		now:     time.Now,
+
+		questionTimeout: defaultQuestionTimeout,
+		answerTimeout:   defaultAnswerTimeout,
+	}
+}
+
+func (s *PracticeServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// setTimeouts changes the timings of sessions whose filter sets none
+func (s *PracticeServiceImpl) setTimeouts(question int, answer int) {
+	s.mu.Lock()
+	s.questionTimeout, s.answerTimeout = question, answer
+	s.mu.Unlock()
+}
+
+// StartSession selects notes with the filter and starts a new session
+func (s *PracticeServiceImpl) StartSession(filter types.PracticeFilter) (resp types.JSResp) {
+	s.mu.RLock()
+	if filter.QuestionTimeout <= 0 {
+		filter.QuestionTimeout = s.questionTimeout
+	}
+	if filter.AnswerTimeout <= 0 {
+		filter.AnswerTimeout = s.answerTimeout
+	}
+	s.mu.RUnlock()
+
+	noteIDs, err := s.storage.SelectNotes(filter, s.now().Unix())
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if len(noteIDs) == 0 {
+		resp.SetError(types.ErrSessionEmpty)
+		return
+	}
+
+	filterJSON, err := json.Marshal(filter)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	idsJSON, err := json.Marshal(noteIDs)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	session := &types.PracticeSession{
+		Filter:          string(filterJSON),
+		NoteIDs:         string(idsJSON),
+		QuestionTimeout: filter.QuestionTimeout,
+		AnswerTimeout:   filter.AnswerTimeout,
+		Total:           len(noteIDs),
+	}
+	if err := s.storage.CreateSession(session); err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = session
+	return
+}
+
+// NextCard returns the current card of a session, Data is empty once all cards are done.
+// Cards whose note was deleted since the session started are skipped, the
+// session finishes when no card is left.
+func (s *PracticeServiceImpl) NextCard(sessionID int) (resp types.JSResp) {
+	session, noteIDs, err := s.loadSession(sessionID)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	for session.FinishedAt == 0 && session.Position < len(noteIDs) {
+		note, err := s.notes.Get(noteIDs[session.Position])
+		if err != nil && !errors.Is(err, types.ErrNoteNotFound) {
+			resp.SetError(err)
+			return
+		}
+		if note != nil {
+			resp.Success = 1
+			resp.Data = &types.PracticeCard{
+				SessionID:       session.ID,
+				Position:        session.Position + 1,
+				Total:           session.Total,
+				QuestionTimeout: session.QuestionTimeout,
+				AnswerTimeout:   session.AnswerTimeout,
+				Note:            *note,
+			}
+			return
+		}
+		session.Position++
+		if session.Position == len(noteIDs) {
+			session.FinishedAt = s.now().Unix()
+		}
+		if err := s.storage.UpdateSession(session); err != nil {
+			resp.SetError(err)
+			return
+		}
+	}
+	resp.Success = 1
+	return
+}
+
+// RecordOutcome stores the outcome of the current card and moves to the next one
+func (s *PracticeServiceImpl) RecordOutcome(sessionID int, noteID int, outcome string, elapsedMs int) (resp types.JSResp) {
+	session, noteIDs, err := s.loadSession(sessionID)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if session.FinishedAt != 0 || session.Position >= len(noteIDs) {
+		resp.SetError(types.ErrSessionFinished)
+		return
+	}
+	if noteIDs[session.Position] != noteID {
+		resp.SetError(types.ErrUnexpectedCard)
+		return
+	}
+
+	switch outcome {
+	case types.OutcomeCorrect:
+		session.Correct++
+	case types.OutcomeIncorrect:
+		session.Incorrect++
+	case types.OutcomeSkipped:
+		session.Skipped++
+	case types.OutcomeTimedOut:
+		session.TimedOut++
+	default:
+		resp.SetError(types.NewFieldError("outcome", types.ErrInvalidOutcome))
+		return
+	}
+	session.Position++
+	if session.Position == len(noteIDs) {
+		session.FinishedAt = s.now().Unix()
+	}
+
+	result := &types.PracticeResult{
+		SessionID: session.ID,
+		NoteID:    noteID,
+		Outcome:   outcome,
+		ElapsedMs: elapsedMs,
+	}
+	if err := s.storage.SaveResult(session, result); err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = session
+	return
+}
+
+// FinishSession ends a session early and returns its summary
+func (s *PracticeServiceImpl) FinishSession(sessionID int) (resp types.JSResp) {
+	session, err := s.storage.GetSession(sessionID)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if session.FinishedAt == 0 {
+		session.FinishedAt = s.now().Unix()
+		if err := s.storage.UpdateSession(session); err != nil {
+			resp.SetError(err)
+			return
+		}
+	}
+	resp.Success = 1
+	resp.Data = session
+	return
+}
+
+// Sessions returns a paginated list of past sessions, most recent first
+func (s *PracticeServiceImpl) Sessions(page, pageSize int) (resp types.JSResp) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	sessions, total, err := s.storage.ListSessions((page-1)*pageSize, pageSize)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if sessions == nil {
+		sessions = []types.PracticeSession{}
+	}
+
+	resp.Success = 1
+	resp.Data = &types.PracticeSessionList{
+		Total:       int(total),
+		TotalPages:  pageCount(total, pageSize),
+		CurrentPage: page,
+		PageSize:    pageSize,
+		Data:        sessions,
+	}
+	return
+}
+
+// loadSession returns a session together with its decoded note queue
+func (s *PracticeServiceImpl) loadSession(sessionID int) (*types.PracticeSession, []int, error) {
+	session, err := s.storage.GetSession(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var noteIDs []int
+	if err := json.Unmarshal([]byte(session.NoteIDs), &noteIDs); err != nil {
+		return nil, nil, err
+	}
+	return session, noteIDs, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"langlearner1/backend/types"
+)
+
+// MockPracticeStorage is a mock implementation of PracticeStorage interface
+type MockPracticeStorage struct {
+	mock.Mock
+}
+
+func (m *MockPracticeStorage) SelectNotes(filter types.PracticeFilter, now int64) ([]int, error) {
+	args := m.Called(filter, now)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockPracticeStorage) CreateSession(session *types.PracticeSession) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockPracticeStorage) GetSession(id int) (*types.PracticeSession, error) {
+	args := m.Called(id)
+	return args.Get(0).(*types.PracticeSession), args.Error(1)
+}
+
+func (m *MockPracticeStorage) SaveResult(session *types.PracticeSession, result *types.PracticeResult) error {
+	args := m.Called(session, result)
+	return args.Error(0)
+}
+
+func (m *MockPracticeStorage) UpdateSession(session *types.PracticeSession) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockPracticeStorage) ListSessions(offset int, limit int) ([]types.PracticeSession, int64, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]types.PracticeSession), args.Get(1).(int64), args.Error(2)
+}
+
+func newTestPracticeService(now time.Time) (*PracticeServiceImpl, *MockPracticeStorage, *MockNoteStorage) {
+	practiceStorage := new(MockPracticeStorage)
+	noteStorage := new(MockNoteStorage)
+	service := &PracticeServiceImpl{
+		storage: practiceStorage,
+		notes:   noteStorage,
+		now:     func() time.Time { return now },
+
+		questionTimeout: defaultQuestionTimeout,
+		answerTimeout:   defaultAnswerTimeout,
+	}
+	service.Start(context.Background())
+	return service, practiceStorage, noteStorage
+}
+
+func TestStartSession(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, practiceStorage, _ := newTestPracticeService(now)
+
+	filter := types.PracticeFilter{TagIDs: []int{1}, Sample: 2}
+	withDefaults := filter
+	withDefaults.QuestionTimeout = defaultQuestionTimeout
+	withDefaults.AnswerTimeout = defaultAnswerTimeout
+	practiceStorage.On("SelectNotes", withDefaults, now.Unix()).Return([]int{4, 2}, nil).Once()
+	practiceStorage.On("CreateSession", mock.Anything).Return(nil).Once()
+
+	result := service.StartSession(filter)
+	assert.Equal(t, 1, result.Success)
+	session := result.Data.(*types.PracticeSession)
+	assert.Equal(t, "[4,2]", session.NoteIDs)
+	assert.Equal(t, 2, session.Total)
+	assert.Equal(t, defaultQuestionTimeout, session.QuestionTimeout)
+	assert.Equal(t, defaultAnswerTimeout, session.AnswerTimeout)
+
+	practiceStorage.On("SelectNotes", mock.Anything, now.Unix()).Return([]int{}, nil).Once()
+	result = service.StartSession(types.PracticeFilter{DueOnly: true})
+	assert.Equal(t, types.ErrSessionEmpty.Error(), result.Msg)
+	practiceStorage.AssertExpectations(t)
+}
+
+func TestPracticeFlow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, practiceStorage, noteStorage := newTestPracticeService(now)
+
+	session := &types.PracticeSession{ID: 7, NoteIDs: "[4,5,2]", Total: 3, QuestionTimeout: 1000, AnswerTimeout: 2000}
+	practiceStorage.On("GetSession", 7).Return(session, nil)
+	practiceStorage.On("SaveResult", session, mock.Anything).Return(nil)
+	practiceStorage.On("UpdateSession", session).Return(nil)
+	noteStorage.On("Get", 4).Return(&types.Note{ID: 4, Front: "いぬ"}, nil)
+	noteStorage.On("Get", 5).Return((*types.Note)(nil), types.ErrNoteNotFound)
+	noteStorage.On("Get", 2).Return(&types.Note{ID: 2, Front: "ねこ"}, nil)
+
+	result := service.NextCard(7)
+	assert.Equal(t, &types.PracticeCard{
+		SessionID: 7, Position: 1, Total: 3, QuestionTimeout: 1000, AnswerTimeout: 2000,
+		Note: types.Note{ID: 4, Front: "いぬ"},
+	}, result.Data)
+
+	result = service.RecordOutcome(7, 2, types.OutcomeCorrect, 0)
+	assert.Equal(t, types.ErrUnexpectedCard.Error(), result.Msg)
+	result = service.RecordOutcome(7, 4, "maybe", 0)
+	assert.Equal(t, types.ErrInvalidOutcome.Error(), result.Msg)
+
+	result = service.RecordOutcome(7, 4, types.OutcomeCorrect, 1500)
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, 1, session.Correct)
+
+	// Note 5 was deleted after the session started and is skipped
+	result = service.NextCard(7)
+	assert.Equal(t, 3, result.Data.(*types.PracticeCard).Position)
+	assert.Equal(t, 2, result.Data.(*types.PracticeCard).Note.ID)
+
+	result = service.RecordOutcome(7, 2, types.OutcomeTimedOut, 3000)
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, 1, session.TimedOut)
+	assert.Equal(t, now.Unix(), session.FinishedAt)
+
+	result = service.NextCard(7)
+	assert.Equal(t, 1, result.Success)
+	assert.Nil(t, result.Data)
+	result = service.RecordOutcome(7, 2, types.OutcomeSkipped, 0)
+	assert.Equal(t, types.ErrSessionFinished.Error(), result.Msg)
+
+	saved := practiceStorage.Calls[len(practiceStorage.Calls)-3].Arguments.Get(1).(*types.PracticeResult)
+	assert.Equal(t, &types.PracticeResult{SessionID: 7, NoteID: 2, Outcome: types.OutcomeTimedOut, ElapsedMs: 3000}, saved)
+}
+
+func TestNextCardFinishesWhenNotesAreDeleted(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, practiceStorage, noteStorage := newTestPracticeService(now)
+
+	session := &types.PracticeSession{ID: 7, NoteIDs: "[4,5,2]", Total: 3, Position: 1, Correct: 1}
+	practiceStorage.On("GetSession", 7).Return(session, nil)
+	practiceStorage.On("UpdateSession", session).Return(nil)
+	noteStorage.On("Get", 5).Return((*types.Note)(nil), types.ErrNoteNotFound)
+	noteStorage.On("Get", 2).Return((*types.Note)(nil), types.ErrNoteNotFound)
+
+	// The notes of the remaining cards were deleted, the session is over
+	result := service.NextCard(7)
+	assert.Equal(t, 1, result.Success)
+	assert.Nil(t, result.Data)
+	assert.Equal(t, 3, session.Position)
+	assert.Equal(t, now.Unix(), session.FinishedAt)
+	practiceStorage.AssertNumberOfCalls(t, "UpdateSession", 2)
+
+	result = service.RecordOutcome(7, 2, types.OutcomeSkipped, 0)
+	assert.Equal(t, types.ErrSessionFinished.Error(), result.Msg)
+}
+
+func TestFinishSession(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, practiceStorage, _ := newTestPracticeService(now)
+
+	session := &types.PracticeSession{ID: 3, NoteIDs: "[1,2]", Total: 2, Position: 1, Correct: 1}
+	practiceStorage.On("GetSession", 3).Return(session, nil)
+	practiceStorage.On("GetSession", 4).Return((*types.PracticeSession)(nil), types.ErrSessionNotFound)
+	practiceStorage.On("UpdateSession", session).Return(nil).Once()
+
+	result := service.FinishSession(3)
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, now.Unix(), session.FinishedAt)
+	// Finishing twice keeps the original finish time
+	assert.Equal(t, 1, service.FinishSession(3).Success)
+
+	result = service.FinishSession(4)
+	assert.Equal(t, types.ErrSessionNotFound.Error(), result.Msg)
+	practiceStorage.AssertExpectations(t)
+}
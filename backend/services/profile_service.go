@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"langlearner1/backend/profiles"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// ProfileServiceImpl implements the ProfileService interface
+type ProfileServiceImpl struct {
+	ctx      context.Context
+	profiles *profiles.Manager
+	store    *storage.Store
+	assets   *AssetServiceImpl
+	jobs     types.JobServiceIf
+}
+
+// NewProfileService creates a new instance of ProfileService. Switching profiles
+// reopens store and moves the asset store of the asset service.
+func NewProfileService(manager *profiles.Manager, store *storage.Store, assetSvc types.AssetServiceIf, jobSvc types.JobServiceIf) types.ProfileServiceIf {
+	return &ProfileServiceImpl{
+		profiles: manager,
+		store:    store,
+		assets:   assetSvc.(*AssetServiceImpl),
+		jobs:     jobSvc,
+	}
+}
+
+func (s *ProfileServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// List returns all profiles
+func (s *ProfileServiceImpl) List() (resp types.JSResp) {
+	resp.Success = 1
+	resp.Data = s.profiles.List()
+	return
+}
+
+// Active returns the profile in use
+func (s *ProfileServiceImpl) Active() (resp types.JSResp) {
+	resp.Success = 1
+	resp.Data = s.profiles.Active()
+	return
+}
+
+// Create adds an empty profile
+func (s *ProfileServiceImpl) Create(name string) (resp types.JSResp) {
+	profile, err := s.profiles.Create(name)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = profile
+	return
+}
+
+// Rename changes the name of a profile
+func (s *ProfileServiceImpl) Rename(name string, newName string) (resp types.JSResp) {
+	profile, err := s.profiles.Rename(name, newName)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = profile
+	return
+}
+
+// Delete removes a profile that is not active, with its database and files
+func (s *ProfileServiceImpl) Delete(name string) (resp types.JSResp) {
+	if err := s.profiles.Delete(name); err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	return
+}
+
+// Switch makes another profile active without restarting the app. The
+// frontend is told with a ProfileEvent so it can reload its data.
+func (s *ProfileServiceImpl) Switch(name string) (resp types.JSResp) {
+	profile, err := s.profiles.Get(name)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if !profile.Active {
+		if err := pauseJobs(s.jobs, func() error { return s.open(profile) }); err != nil {
+			resp.SetError(err)
+			return
+		}
+		profile.Active = true
+		if s.ctx != nil {
+			runtime.EventsEmit(s.ctx, types.ProfileEvent, profile)
+		}
+	}
+
+	resp.Success = 1
+	resp.Data = profile
+	return
+}
+
+// open makes profile active and points the services at its database and files.
+// The profile is made active first, so a failure never leaves the services on
+// a database other than the active profile's, and it is undone when the
+// database cannot be opened.
+func (s *ProfileServiceImpl) open(profile types.Profile) error {
+	previous := s.profiles.Active()
+	if err := s.profiles.SetActive(profile.Name); err != nil {
+		return err
+	}
+	if err := s.store.Reopen(s.profiles.DatabasePath(profile)); err != nil {
+		if rollbackErr := s.profiles.SetActive(previous.Name); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	s.assets.store.SetDir(s.profiles.AssetDir(profile))
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/profiles"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+func TestProfileSwitch(t *testing.T) {
+	manager, err := profiles.Load(filepath.Join(t.TempDir(), "profiles.json"))
+	require.NoError(t, err)
+	first := manager.Active()
+	store, err := storage.Open(manager.DatabasePath(first))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	noteSvc := NewNoteServiceImpl(store)
+	assetSvc := NewAssetService(store, manager.AssetDir(first))
+	ankiSvc := NewAnkiService(store, assetSvc)
+	runner := jobs.NewRunner(storage.NewSQLiteJobStorage(store), jobs.WithEmitter(func(context.Context, string, ...interface{}) {}))
+	jobSvc := NewJobService(store, noteSvc, NewCSVService(store), ankiSvc, NewSpeechService(store, assetSvc), WithJobRunner(runner))
+	jobSvc.(*JobServiceImpl).Start(context.Background())
+	t.Cleanup(func() { StopJobs(jobSvc) })
+	service := NewProfileService(manager, store, assetSvc, jobSvc)
+
+	require.Equal(t, 1, noteSvc.Create(types.NotePayload{Front: "犬", Back: "dog"}).Success)
+	countNotes := func() int {
+		resp := noteSvc.List(1, 10, types.NoteFilter{})
+		require.Equal(t, 1, resp.Success, resp.Msg)
+		return resp.Data.(*types.NoteList).Total
+	}
+
+	resp := service.Create("Second")
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	second := resp.Data.(types.Profile)
+	assert.False(t, second.Active)
+
+	resp = service.Switch("second")
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.True(t, resp.Data.(types.Profile).Active)
+	assert.Equal(t, "Second", manager.Active().Name)
+	assert.Equal(t, 0, countNotes(), "the new profile starts empty")
+	assert.Equal(t, manager.AssetDir(second), assetSvc.(*AssetServiceImpl).store.Dir())
+	assert.FileExists(t, manager.DatabasePath(second))
+
+	// The job workers run on the database of the new profile
+	resp = jobSvc.RebuildSearchIndex()
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	id := resp.Data.(*types.Job).ID
+	require.Eventually(t, func() bool {
+		resp := jobSvc.Get(id)
+		return resp.Success == 1 && resp.Data.(*types.Job).Status == types.JobDone
+	}, 5*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, types.ErrProfileActive.Error(), service.Delete("Second").Msg)
+	assert.Equal(t, types.ErrProfileNotFound.Error(), service.Switch("missing").Msg)
+
+	require.Equal(t, 1, service.Switch(profiles.DefaultName).Success)
+	assert.Equal(t, 1, countNotes(), "switching back reopens the first database")
+
+	// A database that cannot be opened leaves the active profile as it was
+	resp = service.Create("Broken")
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	require.NoError(t, os.MkdirAll(manager.DatabasePath(resp.Data.(types.Profile)), 0755))
+	assert.Equal(t, 0, service.Switch("Broken").Success)
+	assert.Equal(t, profiles.DefaultName, manager.Active().Name)
+	assert.Equal(t, 1, countNotes())
+
+	resp = service.Delete("Second")
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	_, err = os.Stat(filepath.Dir(manager.DatabasePath(second)))
+	assert.True(t, os.IsNotExist(err), "the deleted profile's files are removed")
+	assert.Len(t, service.List().Data, 2)
+
+	// When the active profile cannot be saved the database is not switched
+	resp = service.Create("Third")
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	config := filepath.Join(filepath.Dir(manager.DatabasePath(first)), "profiles.json")
+	require.NoError(t, os.Remove(config))
+	require.NoError(t, os.MkdirAll(filepath.Join(config, "blocked"), 0755))
+	assert.Equal(t, 0, service.Switch("Third").Success)
+	assert.Equal(t, profiles.DefaultName, manager.Active().Name)
+	assert.Equal(t, 1, countNotes())
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"langlearner1/backend/answer"
+	"langlearner1/backend/srs"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// ReviewServiceImpl implements the ReviewService interface
+type ReviewServiceImpl struct {
+	ctx     context.Context
+	storage storage.ReviewStorage
+	notes   storage.NoteStorageIf
+	checker *answer.Checker
+
+	// mu guards the scheduler and serializes reviews, two reviews of a note
+	// must not both schedule from the same state
+	mu        sync.RWMutex
+	scheduler srs.Scheduler
+	now       func() time.Time
+}
+
+// NewReviewService creates a new instance of ReviewService on store using the SM-2 scheduler
+func NewReviewService(store *storage.Store) types.ReviewServiceIf {
+	return &ReviewServiceImpl{
+		storage:   storage.NewSQLiteReviewStorage(store),
+		notes:     storage.NewSQLiteNoteStorage(store),
+		checker:   answer.NewChecker(),
+		scheduler: srs.NewSM2(),
+		now:       time.Now,
+	}
+}
+
+func (s *ReviewServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// GetDueNotes returns up to limit notes that are due for review
+func (s *ReviewServiceImpl) GetDueNotes(limit int) (resp types.JSResp) {
+	if limit < 1 {
+		limit = 20
+	}
+	notes, err := s.storage.DueNotes(s.now().Unix(), limit)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = notes
+	return
+}
+
+// SubmitReview grades a review of a note and schedules the next one
+func (s *ReviewServiceImpl) SubmitReview(noteID int, grade int) (resp types.JSResp) {
+	if !srs.Grade(grade).Valid() {
+		resp.SetError(types.NewFieldError("grade", types.ErrInvalidGrade))
+		return
+	}
+
+	next, err := s.review(&types.ReviewLog{NoteID: noteID, Grade: grade})
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = next
+	return
+}
+
+// CheckAnswer grades an answer typed for a side of a note and records the
+// review with the grade it earns. The front of a Japanese note may also be
+// answered with its reading in kana or romaji.
+func (s *ReviewServiceImpl) CheckAnswer(noteID int, side string, typed string) (resp types.JSResp) {
+	note, err := s.notes.Get(noteID)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	var expected []string
+	switch side {
+	case types.SideFront:
+		expected = []string{plainText(note.Front), plainText(furiganaReading(note.Furigana)), note.Romaji}
+	case types.SideBack:
+		expected = []string{plainText(note.Back)}
+	default:
+		resp.SetError(types.NewFieldError("side", types.ErrInvalidSide))
+		return
+	}
+	if expected[0] == "" {
+		resp.SetError(types.ErrAnswerTextEmpty)
+		return
+	}
+
+	check := s.checker.Check(expected[0], typed)
+	for _, text := range expected[1:] {
+		if text == "" {
+			continue
+		}
+		if other := s.checker.Check(text, typed); other.Score > check.Score {
+			check = other
+		}
+	}
+	check.State, err = s.review(&types.ReviewLog{NoteID: noteID, Grade: check.Grade, Answer: typed, Score: check.Score})
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = check
+	return
+}
+
+// review schedules the next review of log.NoteID with log.Grade and saves the
+// new state together with log
+func (s *ReviewServiceImpl) review(log *types.ReviewLog) (*types.ReviewState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.storage.GetState(log.NoteID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		current = &types.ReviewState{NoteID: log.NoteID}
+	}
+
+	now := s.now()
+	next := s.scheduler.Schedule(*current, srs.Grade(log.Grade), now)
+	log.Algorithm = s.scheduler.Name()
+	log.Interval = next.Interval
+	log.Ease = next.Ease
+	log.Stability = next.Stability
+	log.Difficulty = next.Difficulty
+	log.ReviewedAt = now.Unix()
+	if err := s.storage.SaveReview(&next, log); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// History returns the most recent reviews of a note
+func (s *ReviewServiceImpl) History(noteID int, limit int) (resp types.JSResp) {
+	if limit < 1 {
+		limit = 50
+	}
+	logs, err := s.storage.History(noteID, limit)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	if logs == nil {
+		logs = []types.ReviewLog{}
+	}
+	resp.Success = 1
+	resp.Data = logs
+	return
+}
+
+// Stats returns the number of new and due notes and of the reviews done since
+// local midnight
+func (s *ReviewServiceImpl) Stats() (resp types.JSResp) {
+	now := s.now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	stats, err := s.storage.Stats(now.Unix(), midnight.Unix())
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	resp.Success = 1
+	resp.Data = stats
+	return
+}
+
+// Algorithm returns the name of the active scheduling algorithm
+func (s *ReviewServiceImpl) Algorithm() (resp types.JSResp) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resp.Success = 1
+	resp.Data = s.scheduler.Name()
+	return
+}
+
+// SetAlgorithm switches the scheduling algorithm, existing states are carried over
+func (s *ReviewServiceImpl) SetAlgorithm(name string) (resp types.JSResp) {
+	scheduler, err := srs.New(name)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	s.mu.Lock()
+	s.scheduler = scheduler
+	s.mu.Unlock()
+	resp.Success = 1
+	resp.Data = scheduler.Name()
+	return
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/answer"
+	"langlearner1/backend/srs"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// MockReviewStorage is a mock implementation of ReviewStorage interface
+type MockReviewStorage struct {
+	mock.Mock
+}
+
+func (m *MockReviewStorage) DueNotes(now int64, limit int) ([]types.DueNote, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]types.DueNote), args.Error(1)
+}
+
+func (m *MockReviewStorage) GetState(noteID int) (*types.ReviewState, error) {
+	args := m.Called(noteID)
+	return args.Get(0).(*types.ReviewState), args.Error(1)
+}
+
+func (m *MockReviewStorage) SaveReview(state *types.ReviewState, log *types.ReviewLog) error {
+	args := m.Called(state, log)
+	return args.Error(0)
+}
+
+func (m *MockReviewStorage) History(noteID int, limit int) ([]types.ReviewLog, error) {
+	args := m.Called(noteID, limit)
+	return args.Get(0).([]types.ReviewLog), args.Error(1)
+}
+
+func (m *MockReviewStorage) Stats(now int64, since int64) (*types.ReviewStats, error) {
+	args := m.Called(now, since)
+	return args.Get(0).(*types.ReviewStats), args.Error(1)
+}
+
+func newTestReviewService(storage *MockReviewStorage, now time.Time) *ReviewServiceImpl {
+	service := &ReviewServiceImpl{
+		storage:   storage,
+		notes:     new(MockNoteStorage),
+		checker:   answer.NewChecker(),
+		scheduler: srs.NewSM2(),
+		now:       func() time.Time { return now },
+	}
+	service.Start(context.Background())
+	return service
+}
+
+func TestGetDueNotes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockStorage := new(MockReviewStorage)
+	service := newTestReviewService(mockStorage, now)
+
+	due := []types.DueNote{{Note: types.Note{ID: 1}, State: types.ReviewState{NoteID: 1}, IsNew: true}}
+	mockStorage.On("DueNotes", now.Unix(), 20).Return(due, nil)
+
+	result := service.GetDueNotes(0)
+	assert.Equal(t, 1, result.Success)
+	assert.Equal(t, due, result.Data)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestSubmitReview(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockStorage := new(MockReviewStorage)
+	service := newTestReviewService(mockStorage, now)
+
+	mockStorage.On("GetState", 1).Return((*types.ReviewState)(nil), nil)
+	mockStorage.On("GetState", 2).Return(&types.ReviewState{NoteID: 2, Ease: 2.5, Interval: 6, Reps: 2}, nil)
+	mockStorage.On("SaveReview", mock.Anything, mock.Anything).Return(nil)
+
+	result := service.SubmitReview(1, int(srs.Good))
+	assert.Equal(t, 1, result.Success)
+	state := result.Data.(*types.ReviewState)
+	assert.Equal(t, 1, state.NoteID)
+	assert.Equal(t, 1, state.Interval)
+	assert.Equal(t, now.Add(srs.Day).Unix(), state.Due)
+
+	assert.Equal(t, 1, service.SetAlgorithm("fsrs").Success)
+	assert.Equal(t, "fsrs", service.Algorithm().Data)
+	result = service.SubmitReview(2, int(srs.Good))
+	assert.Equal(t, 1, result.Success)
+
+	log := mockStorage.Calls[len(mockStorage.Calls)-1].Arguments.Get(1).(*types.ReviewLog)
+	assert.Equal(t, 2, log.NoteID)
+	assert.Equal(t, "fsrs", log.Algorithm)
+	assert.Equal(t, now.Unix(), log.ReviewedAt)
+	assert.Equal(t, result.Data.(*types.ReviewState).Interval, log.Interval)
+
+	result = service.SubmitReview(1, 7)
+	assert.Equal(t, types.ErrInvalidGrade.Error(), result.Msg)
+	result = service.SetAlgorithm("leitner")
+	assert.Equal(t, types.ErrUnknownAlgorithm.Error(), result.Msg)
+	assert.Equal(t, "fsrs", service.Algorithm().Data)
+}
+
+// slowReviewStorage keeps the state of one note and takes a while to read it,
+// so concurrent reviews overlap
+type slowReviewStorage struct {
+	storage.ReviewStorage
+
+	mu    sync.Mutex
+	state *types.ReviewState
+	logs  int
+}
+
+func (s *slowReviewStorage) GetState(noteID int) (*types.ReviewState, error) {
+	s.mu.Lock()
+	state := s.state
+	s.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	return state, nil
+}
+
+func (s *slowReviewStorage) SaveReview(state *types.ReviewState, log *types.ReviewLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *state
+	s.state = &saved
+	s.logs++
+	return nil
+}
+
+func TestConcurrentReviews(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	reviewStorage := &slowReviewStorage{}
+	service := &ReviewServiceImpl{
+		storage:   reviewStorage,
+		scheduler: srs.NewSM2(),
+		now:       func() time.Time { return now },
+	}
+
+	// Every review schedules from the state the previous one saved
+	const reviews = 10
+	var wg sync.WaitGroup
+	for i := 0; i < reviews; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := service.SubmitReview(1, int(srs.Good))
+			assert.Equal(t, 1, resp.Success, resp.Msg)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, reviews, reviewStorage.logs)
+	require.NotNil(t, reviewStorage.state)
+	assert.Equal(t, reviews, reviewStorage.state.Reps)
+}
+
+func TestCheckAnswer(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockStorage := new(MockReviewStorage)
+	service := newTestReviewService(mockStorage, now)
+	noteStorage := service.notes.(*MockNoteStorage)
+	noteStorage.On("Get", 1).Return(&types.Note{
+		ID:       1,
+		Front:    "<b>日本語</b>",
+		Back:     "Japanese (language)",
+		Furigana: "<b><ruby>日本語<rt>にほんご</rt></ruby></b>",
+		Romaji:   "nihongo",
+	}, nil)
+	noteStorage.On("Get", 2).Return(&types.Note{ID: 2, Front: "いぬ"}, nil)
+	mockStorage.On("GetState", mock.Anything).Return((*types.ReviewState)(nil), nil)
+	mockStorage.On("SaveReview", mock.Anything, mock.Anything).Return(nil)
+
+	result := service.CheckAnswer(1, types.SideBack, "japanese language")
+	require.Equal(t, 1, result.Success, result.Msg)
+	check := result.Data.(*types.AnswerCheck)
+	assert.Equal(t, int(srs.Good), check.Grade)
+	assert.Equal(t, 1, check.State.Interval)
+	log := mockStorage.Calls[len(mockStorage.Calls)-1].Arguments.Get(1).(*types.ReviewLog)
+	assert.Equal(t, "japanese language", log.Answer)
+	assert.Equal(t, 1.0, log.Score)
+	assert.Equal(t, int(srs.Good), log.Grade)
+
+	// The front can be answered with its reading
+	for _, typed := range []string{"日本語", "ニホンゴ", "nihongo"} {
+		result = service.CheckAnswer(1, types.SideFront, typed)
+		require.Equal(t, 1, result.Success, result.Msg)
+		assert.True(t, result.Data.(*types.AnswerCheck).Correct, typed)
+	}
+
+	result = service.CheckAnswer(1, types.SideFront, "にほん")
+	require.Equal(t, 1, result.Success, result.Msg)
+	check = result.Data.(*types.AnswerCheck)
+	assert.Equal(t, int(srs.Again), check.Grade)
+	assert.Equal(t, []types.AnswerDiff{{Op: types.DiffEqual, Text: "にほん"}, {Op: types.DiffMissing, Text: "ご"}}, check.Diff)
+	log = mockStorage.Calls[len(mockStorage.Calls)-1].Arguments.Get(1).(*types.ReviewLog)
+	assert.Equal(t, int(srs.Again), log.Grade)
+
+	result = service.CheckAnswer(2, types.SideBack, "dog")
+	assert.Equal(t, types.ErrAnswerTextEmpty.Error(), result.Msg)
+	result = service.CheckAnswer(2, "middle", "dog")
+	assert.Equal(t, types.ErrInvalidSide.Error(), result.Msg)
+	mockStorage.AssertNumberOfCalls(t, "SaveReview", 5)
+}
+
+func TestReviewStats(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	mockStorage := new(MockReviewStorage)
+	service := newTestReviewService(mockStorage, now)
+
+	stats := &types.ReviewStats{Notes: 3, New: 1, Due: 1, Reviews: 4, ReviewsToday: 2}
+	midnight := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mockStorage.On("Stats", now.Unix(), midnight.Unix()).Return(stats, nil)
+
+	result := service.Stats()
+	require.Equal(t, 1, result.Success, result.Msg)
+	assert.Equal(t, stats, result.Data)
+	mockStorage.AssertExpectations(t)
+}
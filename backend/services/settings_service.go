@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/settings"
+	"langlearner1/backend/types"
+)
+
+// SettingsServiceImpl implements the SettingsService interface
+type SettingsServiceImpl struct {
+	ctx      context.Context
+	settings *settings.Manager
+	reviews  *ReviewServiceImpl
+	practice *PracticeServiceImpl
+	emit     jobs.EmitFunc
+}
+
+// SettingsServiceOption configures a SettingsServiceImpl
+type SettingsServiceOption func(*SettingsServiceImpl)
+
+// WithSettingsEmitter replaces runtime.EventsEmit, which only works with the context of a running app
+func WithSettingsEmitter(emit jobs.EmitFunc) SettingsServiceOption {
+	return func(s *SettingsServiceImpl) {
+		s.emit = emit
+	}
+}
+
+// NewSettingsService creates a new instance of SettingsService on the settings
+// of manager. The loaded settings are applied to the locale, the review
+// scheduler and the practice timings right away.
+func NewSettingsService(manager *settings.Manager, reviewSvc types.ReviewServiceIf, practiceSvc types.PracticeServiceIf, options ...SettingsServiceOption) types.SettingsServiceIf {
+	s := &SettingsServiceImpl{
+		settings: manager,
+		reviews:  reviewSvc.(*ReviewServiceImpl),
+		practice: practiceSvc.(*PracticeServiceImpl),
+		emit:     runtime.EventsEmit,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	s.apply(manager.Get())
+	return s
+}
+
+func (s *SettingsServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// Get returns the current settings
+func (s *SettingsServiceImpl) Get() (resp types.JSResp) {
+	resp.Success = 1
+	resp.Data = s.settings.Get()
+	return
+}
+
+// Update validates and saves the settings. The locale, algorithm and practice
+// timings apply right away, the data directory and providers after a restart.
+func (s *SettingsServiceImpl) Update(payload types.Settings) (resp types.JSResp) {
+	previous := s.settings.Get()
+	updated, err := s.settings.Update(payload)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	s.changed(previous, updated)
+
+	resp.Success = 1
+	resp.Data = updated
+	return
+}
+
+// Reset saves and applies the default settings
+func (s *SettingsServiceImpl) Reset() (resp types.JSResp) {
+	previous := s.settings.Get()
+	updated, err := s.settings.Reset()
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	s.changed(previous, updated)
+
+	resp.Success = 1
+	resp.Data = updated
+	return
+}
+
+// Choices returns the values the locale, algorithm and provider settings accept
+func (s *SettingsServiceImpl) Choices() (resp types.JSResp) {
+	resp.Success = 1
+	resp.Data = settings.Choices()
+	return
+}
+
+// changed applies updated and tells the frontend with a SettingsEvent when it
+// differs from previous
+func (s *SettingsServiceImpl) changed(previous types.Settings, updated types.Settings) {
+	if updated == previous {
+		return
+	}
+	s.apply(updated)
+	if s.ctx != nil {
+		s.emit(s.ctx, types.SettingsEvent, updated)
+	}
+}
+
+// apply hands the settings that take effect without a restart to the services
+func (s *SettingsServiceImpl) apply(current types.Settings) {
+	i18n.SetLocale(settings.Locale(current))
+	s.reviews.SetAlgorithm(current.Review.Algorithm)
+	s.practice.setTimeouts(current.Practice.QuestionTimeout, current.Practice.AnswerTimeout)
+}
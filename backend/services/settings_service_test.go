@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/settings"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+func TestSettingsService(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.Open(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	manager, err := settings.Load(filepath.Join(dir, "settings.json"))
+	require.NoError(t, err)
+	t.Cleanup(func() { i18n.SetLocale(i18n.DefaultLocale) })
+
+	var events []types.Settings
+	reviewSvc := NewReviewService(store)
+	practiceSvc := NewPracticeService(store)
+	service := NewSettingsService(manager, reviewSvc, practiceSvc, WithSettingsEmitter(func(_ context.Context, event string, data ...interface{}) {
+		require.Equal(t, types.SettingsEvent, event)
+		events = append(events, data[0].(types.Settings))
+	}))
+	service.(*SettingsServiceImpl).Start(context.Background())
+
+	resp := service.Get()
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	current := resp.Data.(types.Settings)
+	assert.Equal(t, settings.Defaults(), current)
+
+	current.Locale = "zh-CN"
+	current.Review.Algorithm = "fsrs"
+	current.Practice.QuestionTimeout = 2500
+	resp = service.Update(current)
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.Equal(t, current, resp.Data)
+	assert.Equal(t, []types.Settings{current}, events)
+	// The changes apply without a restart
+	assert.Equal(t, "zh-CN", i18n.Locale())
+	assert.Equal(t, "fsrs", reviewSvc.Algorithm().Data)
+	assert.Equal(t, 2500, practiceSvc.(*PracticeServiceImpl).questionTimeout)
+
+	// Saving the same settings again changes nothing
+	resp = service.Update(current)
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.Len(t, events, 1)
+
+	invalid := current
+	invalid.Practice.AnswerTimeout = 0
+	resp = service.Update(invalid)
+	assert.Equal(t, 0, resp.Success)
+	assert.Equal(t, "INVALID_TIMEOUT", resp.Code)
+	require.Len(t, resp.Details, 1)
+	assert.Equal(t, "practice.answer_timeout", resp.Details[0].Field)
+	assert.Equal(t, i18n.Translate("zh-CN", "error.invalid_timeout"), resp.Details[0].Message)
+	assert.Len(t, events, 1)
+
+	resp = service.Reset()
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	assert.Equal(t, settings.Defaults(), resp.Data)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "sm2", reviewSvc.Algorithm().Data)
+	assert.Equal(t, defaultQuestionTimeout, practiceSvc.(*PracticeServiceImpl).questionTimeout)
+
+	resp = service.Choices()
+	require.Equal(t, 1, resp.Success, resp.Msg)
+	choices := resp.Data.(types.SettingsChoices)
+	assert.Contains(t, choices.Locales, "zh-CN")
+	assert.Contains(t, choices.Algorithms, "fsrs")
+}
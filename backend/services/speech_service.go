@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"langlearner1/backend/jobs"
+	"langlearner1/backend/speech"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+)
+
+// SpeechServiceImpl implements the SpeechService interface
+type SpeechServiceImpl struct {
+	ctx         context.Context
+	notes       storage.NoteStorageIf
+	cache       storage.SpeechStorage
+	assets      *AssetServiceImpl
+	synthesizer speech.SpeechSynthesizer
+}
+
+// SpeechServiceOption configures a SpeechServiceImpl
+type SpeechServiceOption func(*SpeechServiceImpl)
+
+// WithSynthesizer sets the text-to-speech backend, without it only cached audio is available
+func WithSynthesizer(synthesizer speech.SpeechSynthesizer) SpeechServiceOption {
+	return func(s *SpeechServiceImpl) {
+		s.synthesizer = synthesizer
+	}
+}
+
+// NewSpeechService creates a new instance of SpeechService on store that stores audio through assetSvc
+func NewSpeechService(store *storage.Store, assetSvc types.AssetServiceIf, options ...SpeechServiceOption) types.SpeechServiceIf {
+	s := &SpeechServiceImpl{
+		notes:  storage.NewSQLiteNoteStorage(store),
+		cache:  storage.NewSQLiteSpeechStorage(store),
+		assets: assetSvc.(*AssetServiceImpl),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+func (s *SpeechServiceImpl) Start(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// Generate synthesizes a side of a note and attaches it as the audio of that side.
+// Existing audio is only replaced when overwrite is set, an empty voice uses the default.
+func (s *SpeechServiceImpl) Generate(noteID int, side string, voice string, overwrite bool) (resp types.JSResp) {
+	note, err := s.notes.Get(noteID)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+	link, _, err := s.generate(orBackground(s.ctx), note, side, voice, overwrite)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = link
+	return
+}
+
+// FillTag generates the missing audio of a side for every note with the tag
+func (s *SpeechServiceImpl) FillTag(tagID int, side string, voice string) (resp types.JSResp) {
+	report, err := s.fillTag(orBackground(s.ctx), tagID, side, voice, nil)
+	if err != nil {
+		resp.SetError(err)
+		return
+	}
+
+	resp.Success = 1
+	resp.Data = report
+	return
+}
+
+// fillTag generates the missing audio of a side for the notes with the tag,
+// stopping when ctx is cancelled
+func (s *SpeechServiceImpl) fillTag(ctx context.Context, tagID int, side string, voice string, progress jobs.Progress) (*types.BatchReport, error) {
+	if _, err := sideRole(side); err != nil {
+		return nil, err
+	}
+	if s.synthesizer == nil {
+		return nil, types.ErrNoSynthesizer
+	}
+
+	// Collect the notes first, generating audio while paging could shift the pages
+	var notes []types.Note
+	err := forEachNote(s.notes, types.NoteFilter{TagIDs: []int{tagID}}, func(note types.Note) error {
+		notes = append(notes, note)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.BatchReport{Total: len(notes), Errors: []types.BatchError{}}
+	for i := range notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i, len(notes))
+		}
+		_, generated, err := s.generate(ctx, &notes[i], side, voice, false)
+		switch {
+		case errors.Is(err, types.ErrSpeechTextEmpty):
+			report.Skipped++
+		case err != nil:
+			report.Failed++
+			report.Errors = append(report.Errors, types.BatchError{NoteID: notes[i].ID, Message: types.Localize(err)})
+		case generated:
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+	}
+	if progress != nil {
+		progress(len(notes), len(notes))
+	}
+	return report, nil
+}
+
+// generate attaches audio for a side of note, reusing cached audio of the
+// same text and voice. It reports whether audio was attached.
+func (s *SpeechServiceImpl) generate(ctx context.Context, note *types.Note, side string, voice string, overwrite bool) (*types.NoteAsset, bool, error) {
+	role, err := sideRole(side)
+	if err != nil {
+		return nil, false, err
+	}
+	if !overwrite {
+		for i := range note.Assets {
+			if note.Assets[i].Role == role {
+				return &note.Assets[i], false, nil
+			}
+		}
+	}
+	text := note.Front
+	if side == types.SideBack {
+		text = note.Back
+	}
+	text = plainText(text)
+	if text == "" {
+		return nil, false, types.ErrSpeechTextEmpty
+	}
+	if s.synthesizer == nil {
+		return nil, false, types.ErrNoSynthesizer
+	}
+	if voice == "" {
+		voice = s.deckVoice(note, side)
+	}
+
+	key := speechKey(s.synthesizer.Name(), voice, text)
+	cached, err := s.cache.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if cached != nil {
+		link, err := s.assets.attachAsset(note.ID, cached.ID, role)
+		return link, err == nil, err
+	}
+
+	audio, mimeType, err := s.synthesizer.Synthesize(ctx, text, voice)
+	if err != nil {
+		return nil, false, err
+	}
+	link, err := s.assets.attachContent(note.ID, "speech-"+key[:16]+audioExtension(mimeType), bytes.NewReader(audio), role)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.cache.Save(key, link.AssetID); err != nil {
+		return nil, false, err
+	}
+	return link, true, nil
+}
+
+// deckVoice returns the voice configured for the language of a note side in
+// its deck, "" leaves the choice to the synthesizer
+func (s *SpeechServiceImpl) deckVoice(note *types.Note, side string) string {
+	selector, ok := s.synthesizer.(speech.VoiceSelector)
+	if !ok || note.Deck == nil {
+		return ""
+	}
+	language := note.Deck.Source
+	if side == types.SideBack {
+		language = note.Deck.Target
+	}
+	return selector.VoiceFor(language)
+}
+
+// sideRole returns the asset role holding the audio of a note side
+func sideRole(side string) (string, error) {
+	switch side {
+	case types.SideFront:
+		return types.AssetRoleFrontAudio, nil
+	case types.SideBack:
+		return types.AssetRoleBackAudio, nil
+	}
+	return "", types.ErrInvalidSide
+}
+
+// speechKey identifies the audio of text spoken by voice on a backend
+func speechKey(backend string, voice string, text string) string {
+	sum := sha256.Sum256([]byte(backend + "\x00" + voice + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+// MockSpeechStorage is a mock implementation of SpeechStorage interface
+type MockSpeechStorage struct {
+	mock.Mock
+}
+
+func (m *MockSpeechStorage) Get(key string) (*types.Asset, error) {
+	args := m.Called(key)
+	return args.Get(0).(*types.Asset), args.Error(1)
+}
+
+func (m *MockSpeechStorage) Save(key string, assetID int) error {
+	args := m.Called(key, assetID)
+	return args.Error(0)
+}
+
+// fakeSynthesizer returns the spoken text as WAV content and counts its calls
+type fakeSynthesizer struct {
+	calls []string
+	err   error
+	// voices maps languages to voices for VoiceFor
+	voices map[string]string
+}
+
+func (f *fakeSynthesizer) VoiceFor(language string) string {
+	return f.voices[language]
+}
+
+func (f *fakeSynthesizer) Name() string {
+	return "fake"
+}
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text string, voice string) ([]byte, string, error) {
+	f.calls = append(f.calls, voice+":"+text)
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	return []byte("RIFF " + voice + " " + text), "audio/wav", nil
+}
+
+func newTestSpeechService(t *testing.T, synth *fakeSynthesizer) (*SpeechServiceImpl, *MockNoteStorage, *MockSpeechStorage, *MockAssetStorage) {
+	assetSvc, assetStorage := newTestAssetService(t)
+	noteStorage := new(MockNoteStorage)
+	speechStorage := new(MockSpeechStorage)
+	service := &SpeechServiceImpl{
+		notes:  noteStorage,
+		cache:  speechStorage,
+		assets: assetSvc,
+	}
+	if synth != nil {
+		service.synthesizer = synth
+	}
+	service.Start(context.Background())
+	return service, noteStorage, speechStorage, assetStorage
+}
+
+func TestSpeechGenerate(t *testing.T) {
+	t.Run("synthesizes and caches", func(t *testing.T) {
+		synth := &fakeSynthesizer{}
+		service, noteStorage, speechStorage, assetStorage := newTestSpeechService(t, synth)
+		noteStorage.On("Get", 1).Return(&types.Note{ID: 1, Front: "<b>いぬ</b>[sound:old.mp3]", Back: "dog"}, nil)
+		key := speechKey("fake", "ja", "いぬ")
+		speechStorage.On("Get", key).Return((*types.Asset)(nil), nil)
+		assetStorage.On("FindByHash", mock.Anything).Return((*types.Asset)(nil), nil)
+		assetStorage.On("Create", mock.MatchedBy(func(asset *types.Asset) bool {
+			return asset.MimeType == "audio/wav" && asset.Name == "speech-"+key[:16]+".wav"
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*types.Asset).ID = 7
+		}).Return(nil)
+		assetStorage.On("Attach", &types.NoteAsset{NoteID: 1, Role: types.AssetRoleFrontAudio, AssetID: 7}).Return(nil)
+		speechStorage.On("Save", key, 7).Return(nil)
+
+		result := service.Generate(1, types.SideFront, "ja", false)
+		require.Equal(t, 1, result.Success, result.Msg)
+		assert.Equal(t, 7, result.Data.(*types.NoteAsset).AssetID)
+		assert.Equal(t, []string{"ja:いぬ"}, synth.calls)
+		assetStorage.AssertExpectations(t)
+		speechStorage.AssertExpectations(t)
+	})
+
+	t.Run("reuses cached audio", func(t *testing.T) {
+		synth := &fakeSynthesizer{}
+		service, noteStorage, speechStorage, assetStorage := newTestSpeechService(t, synth)
+		noteStorage.On("Get", 2).Return(&types.Note{ID: 2, Front: "いぬ", Back: "dog"}, nil)
+		speechStorage.On("Get", speechKey("fake", "", "dog")).Return(&types.Asset{ID: 9}, nil)
+		assetStorage.On("Attach", &types.NoteAsset{NoteID: 2, Role: types.AssetRoleBackAudio, AssetID: 9}).Return(nil)
+
+		result := service.Generate(2, types.SideBack, "", false)
+		require.Equal(t, 1, result.Success, result.Msg)
+		assert.Empty(t, synth.calls)
+		assetStorage.AssertExpectations(t)
+	})
+
+	t.Run("uses the voice of the deck language", func(t *testing.T) {
+		synth := &fakeSynthesizer{voices: map[string]string{"ko": "korean", "es": "spanish"}}
+		service, noteStorage, speechStorage, assetStorage := newTestSpeechService(t, synth)
+		deck := &types.Deck{ID: 2, Source: "ko", Target: "es"}
+		noteStorage.On("Get", 6).Return(&types.Note{ID: 6, Front: "개", Back: "perro", DeckID: 2, Deck: deck}, nil)
+		speechStorage.On("Get", speechKey("fake", "spanish", "perro")).Return(&types.Asset{ID: 9}, nil)
+		assetStorage.On("Attach", &types.NoteAsset{NoteID: 6, Role: types.AssetRoleBackAudio, AssetID: 9}).Return(nil)
+
+		result := service.Generate(6, types.SideBack, "", false)
+		require.Equal(t, 1, result.Success, result.Msg)
+		speechStorage.AssertExpectations(t)
+	})
+
+	t.Run("keeps existing audio", func(t *testing.T) {
+		synth := &fakeSynthesizer{}
+		service, noteStorage, _, _ := newTestSpeechService(t, synth)
+		existing := types.NoteAsset{NoteID: 3, Role: types.AssetRoleFrontAudio, AssetID: 4}
+		noteStorage.On("Get", 3).Return(&types.Note{ID: 3, Front: "いぬ", Assets: []types.NoteAsset{existing}}, nil)
+
+		result := service.Generate(3, types.SideFront, "", false)
+		require.Equal(t, 1, result.Success, result.Msg)
+		assert.Equal(t, existing, *result.Data.(*types.NoteAsset))
+		assert.Empty(t, synth.calls)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		service, noteStorage, _, _ := newTestSpeechService(t, nil)
+		noteStorage.On("Get", 4).Return(&types.Note{ID: 4, Front: "いぬ", Back: "<br>"}, nil)
+		noteStorage.On("Get", 5).Return((*types.Note)(nil), types.ErrNoteNotFound)
+
+		assert.Equal(t, types.ErrInvalidSide.Error(), service.Generate(4, "middle", "", false).Msg)
+		assert.Equal(t, types.ErrSpeechTextEmpty.Error(), service.Generate(4, types.SideBack, "", false).Msg)
+		assert.Equal(t, types.ErrNoSynthesizer.Error(), service.Generate(4, types.SideFront, "", false).Msg)
+		assert.Equal(t, types.ErrNoteNotFound.Error(), service.Generate(5, types.SideFront, "", false).Msg)
+	})
+}
+
+func TestSpeechFillTag(t *testing.T) {
+	synth := &fakeSynthesizer{}
+	service, noteStorage, speechStorage, assetStorage := newTestSpeechService(t, synth)
+	filter := types.NoteFilter{TagIDs: []int{3}}
+	notes := []types.Note{
+		{ID: 1, Front: "いぬ"},
+		{ID: 2, Front: "ねこ", Assets: []types.NoteAsset{{NoteID: 2, Role: types.AssetRoleFrontAudio, AssetID: 5}}},
+		{ID: 3, Front: " "},
+		{ID: 4, Front: "とり"},
+	}
+	noteStorage.On("List", filter, 0, exportBatchSize).Return(notes, int64(len(notes)), nil)
+	speechStorage.On("Get", mock.Anything).Return((*types.Asset)(nil), nil)
+	assetStorage.On("FindByHash", mock.Anything).Return((*types.Asset)(nil), nil)
+	assetStorage.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*types.Asset).ID = 10
+	}).Return(nil)
+	assetStorage.On("Attach", &types.NoteAsset{NoteID: 1, Role: types.AssetRoleFrontAudio, AssetID: 10}).Return(nil)
+	assetStorage.On("Attach", &types.NoteAsset{NoteID: 4, Role: types.AssetRoleFrontAudio, AssetID: 10}).Return(errors.New("disk full"))
+	speechStorage.On("Save", mock.Anything, 10).Return(nil)
+
+	result := service.FillTag(3, types.SideFront, "")
+	require.Equal(t, 1, result.Success, result.Msg)
+	report := result.Data.(*types.BatchReport)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, []types.BatchError{{NoteID: 4, Message: "disk full"}}, report.Errors)
+	assert.Equal(t, []string{":いぬ", ":とり"}, synth.calls)
+
+	service.synthesizer = nil
+	assert.Equal(t, types.ErrNoSynthesizer.Error(), service.FillTag(3, types.SideFront, "").Msg)
+}
@@ -3,7 +3,6 @@ package services
 import (
 	"context"
 	"math"
-	"strings"
 
 	"langlearner1/backend/storage"
 	"langlearner1/backend/types"
@@ -15,11 +14,25 @@ type TagServiceImpl struct {
 	storage storage.TagStorage
 }
 
-// NewTagService creates a new instance of TagService
-func NewTagService() types.TagServiceIf {
-	return &TagServiceImpl{
-		storage: storage.NewSQLiteTagStorage(),
+// TagServiceOption configures a TagServiceImpl
+type TagServiceOption func(*TagServiceImpl)
+
+// WithTagStorage replaces the SQLite tag storage
+func WithTagStorage(tags storage.TagStorage) TagServiceOption {
+	return func(s *TagServiceImpl) {
+		s.storage = tags
+	}
+}
+
+// NewTagService creates a new instance of TagService on store
+func NewTagService(store *storage.Store, options ...TagServiceOption) types.TagServiceIf {
+	s := &TagServiceImpl{
+		storage: storage.NewSQLiteTagStorage(store),
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
 
 func (s *TagServiceImpl) Start(ctx context.Context) {
@@ -35,45 +48,22 @@ func (s *TagServiceImpl) List(page, pageSize int, keyword string) (resp types.JS
 		pageSize = 10
 	}
 	offset := (page - 1) * pageSize
-	// Get all tags from storage
-	tags, err := s.storage.List(0, keyword, offset, pageSize)
+	tags, total, err := s.storage.List(0, keyword, offset, pageSize)
 	if err != nil {
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
-
-	// Filter tags by keyword
-	filteredTags := make([]types.Tag, 0)
-	for _, tag := range tags {
-		if keyword == "" || strings.Contains(strings.ToLower(tag.Name), strings.ToLower(keyword)) {
-			filteredTags = append(filteredTags, tag)
-		}
-	}
-
-	total := len(filteredTags)
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
-
-	// Calculate pagination bounds
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if end > total {
-		end = total
+	if tags == nil {
+		tags = []types.Tag{}
 	}
 
-	// Get paginated data
-	var data []types.Tag
-	if start < total {
-		data = filteredTags[start:end]
-	} else {
-		data = []types.Tag{}
-	}
 	resp.Success = 1
 	resp.Data = &types.TagList{
-		Total:       total,
-		TotalPages:  totalPages,
+		Total:       int(total),
+		TotalPages:  pageCount(total, pageSize),
 		CurrentPage: page,
 		PageSize:    pageSize,
-		Data:        data,
+		Data:        tags,
 	}
 	return
 }
@@ -81,7 +71,7 @@ func (s *TagServiceImpl) List(page, pageSize int, keyword string) (resp types.JS
 // Create creates a new tag
 func (s *TagServiceImpl) Create(name string) (resp types.JSResp) {
 	if name == "" {
-		resp.Msg = "tag name cannot be empty"
+		resp.SetError(types.NewFieldError("name", types.ErrTagNameEmpty))
 		return
 	}
 
@@ -89,11 +79,7 @@ func (s *TagServiceImpl) Create(name string) (resp types.JSResp) {
 	newTag := &types.Tag{Name: name}
 	err := s.storage.Create(newTag)
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint") {
-			resp.Msg = "标签已存在"
-			return
-		}
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
 	resp.Success = 1
@@ -104,7 +90,7 @@ func (s *TagServiceImpl) Create(name string) (resp types.JSResp) {
 // Update updates an existing tag
 func (s *TagServiceImpl) Update(id int, name string) (resp types.JSResp) {
 	if name == "" {
-		resp.Msg = "tag name cannot be empty"
+		resp.SetError(types.NewFieldError("name", types.ErrTagNameEmpty))
 		return
 	}
 
@@ -112,7 +98,7 @@ func (s *TagServiceImpl) Update(id int, name string) (resp types.JSResp) {
 	updatedTag := &types.Tag{ID: id, Name: name}
 	err := s.storage.Update(updatedTag)
 	if err != nil {
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
 	resp.Success = 1
@@ -120,14 +106,19 @@ func (s *TagServiceImpl) Update(id int, name string) (resp types.JSResp) {
 	return
 }
 
-// Delete deletes a tag
+// Delete deletes a tag and removes it from its notes
 func (s *TagServiceImpl) Delete(id int) (resp types.JSResp) {
 	err := s.storage.Delete(id)
 	if err != nil {
-		resp.Msg = err.Error()
+		resp.SetError(err)
 		return
 	}
 
 	resp.Success = 1
 	return
 }
+
+// pageCount returns the number of pages needed to show total items
+func pageCount(total int64, pageSize int) int {
+	return int(math.Ceil(float64(total) / float64(pageSize)))
+}
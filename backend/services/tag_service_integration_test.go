@@ -3,9 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,47 +12,22 @@ import (
 	"langlearner1/backend/types"
 )
 
-func setupTestDB(t *testing.T) func() {
-	// 创建临时测试数据库文件 `~/AppData/Local/Temp/langlearner-test`
-	tmpDir, err := os.MkdirTemp("", "langlearner-test")
+// setupTestDB 为每个测试打开一个独立的内存数据库，测试结束时关闭
+func setupTestDB(t *testing.T) *storage.Store {
+	store, err := storage.OpenMemory()
 	if err != nil {
 		t.Fatal(err)
 	}
-	dbPath := filepath.Join(tmpDir, "test.db")
-
-	// 初始化数据库连接
-	err = storage.InitDB(dbPath)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	// 自动迁移数据库表结构
-	err = storage.DB.AutoMigrate(&types.Tag{})
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	// 返回清理函数
-	return func() {
-		// 关闭数据库连接
-		db, err := storage.DB.DB()
-		if err == nil {
-			db.Close()
-		}
-		// 删除临时数据库文件
-		//os.RemoveAll(tmpDir)
-	}
+	t.Cleanup(func() { store.Close() })
+	return store
 }
 
 func TestListWithSQLite(t *testing.T) {
 	// 设置测试数据库
-	cleanup := setupTestDB(t)
-	defer cleanup()
+	store := setupTestDB(t)
 
 	// 创建服务实例
-	service := &TagServiceImpl{
-		storage: storage.NewSQLiteTagStorage(),
-	}
+	service := NewTagService(store).(*TagServiceImpl)
 	service.Start(context.Background())
 
 	tests := []struct {
@@ -67,7 +41,7 @@ func TestListWithSQLite(t *testing.T) {
 	}{
 		{
 			name:     "hasNoData",
-			setup:    func() { storage.DB.Exec("DELETE FROM tags") },
+			setup:    func() { store.DB().Exec("DELETE FROM tags") },
 			page:     1,
 			pageSize: 2,
 			keyword:  "",
@@ -83,9 +57,9 @@ func TestListWithSQLite(t *testing.T) {
 		{
 			name: "success with no keyword",
 			setup: func() {
-				storage.DB.Exec("DELETE FROM tags")
-				storage.DB.Create(&types.Tag{Name: "tag1"})
-				storage.DB.Create(&types.Tag{Name: "tag2"})
+				store.DB().Exec("DELETE FROM tags")
+				store.DB().Create(&types.Tag{Name: "tag1"})
+				store.DB().Create(&types.Tag{Name: "tag2"})
 			},
 			page:     1,
 			pageSize: 2,
@@ -105,9 +79,9 @@ func TestListWithSQLite(t *testing.T) {
 		{
 			name: "success with keyword",
 			setup: func() {
-				storage.DB.Exec("DELETE FROM tags")
-				storage.DB.Create(&types.Tag{Name: "tag1"})
-				storage.DB.Create(&types.Tag{Name: "tag2"})
+				store.DB().Exec("DELETE FROM tags")
+				store.DB().Create(&types.Tag{Name: "tag1"})
+				store.DB().Create(&types.Tag{Name: "tag2"})
 			},
 			page:     1,
 			pageSize: 2,
@@ -123,6 +97,59 @@ func TestListWithSQLite(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name:     "second page",
+			setup:    createTags(store, 5),
+			page:     2,
+			pageSize: 2,
+			keyword:  "",
+			expected: &types.TagList{
+				Total:       5,
+				TotalPages:  3,
+				CurrentPage: 2,
+				PageSize:    2,
+				Data: []types.Tag{
+					{Name: "tag3"},
+					{Name: "tag4"},
+				},
+			},
+			expErr: nil,
+		},
+		{
+			name:     "last page",
+			setup:    createTags(store, 5),
+			page:     3,
+			pageSize: 2,
+			keyword:  "",
+			expected: &types.TagList{
+				Total:       5,
+				TotalPages:  3,
+				CurrentPage: 3,
+				PageSize:    2,
+				Data: []types.Tag{
+					{Name: "tag5"},
+				},
+			},
+			expErr: nil,
+		},
+		{
+			name:     "second page with keyword",
+			setup:    createTags(store, 12),
+			page:     2,
+			pageSize: 2,
+			keyword:  "tag1",
+			expected: &types.TagList{
+				Total:       4,
+				TotalPages:  2,
+				CurrentPage: 2,
+				PageSize:    2,
+				Data: []types.Tag{
+					{Name: "tag11"},
+					{Name: "tag12"},
+				},
+			},
+			expErr: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -133,25 +160,44 @@ func TestListWithSQLite(t *testing.T) {
 			// 执行测试
 			result := service.List(tt.page, tt.pageSize, tt.keyword)
 
-			// 验证结果
+			// 验证结果，自增 ID 在清表后不会重置，所以只比较名称
 			if result.Success != 1 {
 				assert.Equal(t, tt.expErr.Error(), result.Msg)
 			} else {
-				log.Println(result.Data)
+				list := result.Data.(*types.TagList)
+				assert.Equal(t, tt.expected.Total, list.Total)
+				assert.Equal(t, tt.expected.TotalPages, list.TotalPages)
+				assert.Equal(t, tt.expected.CurrentPage, list.CurrentPage)
+				assert.Equal(t, tagNames(tt.expected.Data), tagNames(list.Data))
 			}
 		})
 	}
 }
 
+// createTags 返回一个清空标签表并按顺序创建 tag1..tagN 的 setup 函数
+func createTags(store *storage.Store, n int) func() {
+	return func() {
+		store.DB().Exec("DELETE FROM tags")
+		for i := 1; i <= n; i++ {
+			store.DB().Create(&types.Tag{Name: fmt.Sprintf("tag%d", i)})
+		}
+	}
+}
+
+func tagNames(tags []types.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
 func TestCreateWithSQLite(t *testing.T) {
 	// 设置测试数据库
-	cleanup := setupTestDB(t)
-	defer cleanup()
+	store := setupTestDB(t)
 
 	// 创建服务实例
-	service := &TagServiceImpl{
-		storage: storage.NewSQLiteTagStorage(),
-	}
+	service := NewTagService(store).(*TagServiceImpl)
 	service.Start(context.Background())
 
 	tests := []struct {
@@ -163,7 +209,7 @@ func TestCreateWithSQLite(t *testing.T) {
 	}{
 		{
 			name:    "success",
-			setup:   func() { storage.DB.Exec("DELETE FROM tags") },
+			setup:   func() { store.DB().Exec("DELETE FROM tags") },
 			tagName: "newtag",
 			expected: &types.Tag{
 				ID:   1,
@@ -173,7 +219,7 @@ func TestCreateWithSQLite(t *testing.T) {
 		},
 		{
 			name:     "empty name",
-			setup:    func() { storage.DB.Exec("DELETE FROM tags") },
+			setup:    func() { store.DB().Exec("DELETE FROM tags") },
 			tagName:  "",
 			expected: nil,
 			expErr:   errors.New("tag name cannot be empty"),
@@ -181,12 +227,12 @@ func TestCreateWithSQLite(t *testing.T) {
 		{
 			name: "duplicate name",
 			setup: func() {
-				storage.DB.Exec("DELETE FROM tags")
-				storage.DB.Create(&types.Tag{Name: "newtag"})
+				store.DB().Exec("DELETE FROM tags")
+				store.DB().Create(&types.Tag{Name: "newtag"})
 			},
 			tagName:  "newtag",
 			expected: nil,
-			expErr:   errors.New("UNIQUE constraint failed: tags.name"),
+			expErr:   types.ErrTagExists,
 		},
 	}
 
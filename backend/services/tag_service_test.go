@@ -16,9 +16,9 @@ type MockTagStorage struct {
 	mock.Mock
 }
 
-func (m *MockTagStorage) List(id int, keyword string, offset int, limit int) ([]types.Tag, error) {
+func (m *MockTagStorage) List(id int, keyword string, offset int, limit int) ([]types.Tag, int64, error) {
 	args := m.Called(id, keyword, offset, limit)
-	return args.Get(0).([]types.Tag), args.Error(1)
+	return args.Get(0).([]types.Tag), args.Get(1).(int64), args.Error(2)
 }
 
 func (m *MockTagStorage) Create(tag *types.Tag) error {
@@ -37,29 +37,25 @@ func (m *MockTagStorage) Delete(id int) error {
 }
 
 func TestList(t *testing.T) {
-	mockStorage := new(MockTagStorage)
-	service := &TagServiceImpl{
-		storage: mockStorage,
-	}
-	service.Start(context.Background())
-
 	tests := []struct {
-		name     string
-		page     int
-		pageSize int
-		keyword  string
-		mockTags []types.Tag
-		mockErr  error
-		expected *types.TagList
-		expErr   error
+		name      string
+		page      int
+		pageSize  int
+		keyword   string
+		mockTags  []types.Tag
+		mockTotal int64
+		mockErr   error
+		expected  *types.TagList
+		expErr    error
 	}{
 		{
-			name:     "hasNoData",
-			page:     1,
-			pageSize: 2,
-			keyword:  "",
-			mockTags: nil,
-			mockErr:  nil,
+			name:      "hasNoData",
+			page:      1,
+			pageSize:  2,
+			keyword:   "",
+			mockTags:  nil,
+			mockTotal: 0,
+			mockErr:   nil,
 			expected: &types.TagList{
 				Total:       0,
 				TotalPages:  0,
@@ -78,7 +74,8 @@ func TestList(t *testing.T) {
 				{ID: 1, Name: "tag1"},
 				{ID: 2, Name: "tag2"},
 			},
-			mockErr: nil,
+			mockTotal: 2,
+			mockErr:   nil,
 			expected: &types.TagList{
 				Total:       2,
 				TotalPages:  1,
@@ -98,9 +95,9 @@ func TestList(t *testing.T) {
 			keyword:  "tag1",
 			mockTags: []types.Tag{
 				{ID: 1, Name: "tag1"},
-				{ID: 2, Name: "tag2"},
 			},
-			mockErr: nil,
+			mockTotal: 1,
+			mockErr:   nil,
 			expected: &types.TagList{
 				Total:       1,
 				TotalPages:  1,
@@ -112,17 +109,101 @@ func TestList(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name:     "first of several pages",
+			page:     1,
+			pageSize: 2,
+			keyword:  "",
+			mockTags: []types.Tag{
+				{ID: 1, Name: "tag1"},
+				{ID: 2, Name: "tag2"},
+			},
+			mockTotal: 5,
+			mockErr:   nil,
+			expected: &types.TagList{
+				Total:       5,
+				TotalPages:  3,
+				CurrentPage: 1,
+				PageSize:    2,
+				Data: []types.Tag{
+					{ID: 1, Name: "tag1"},
+					{ID: 2, Name: "tag2"},
+				},
+			},
+			expErr: nil,
+		},
+		{
+			name:     "second page",
+			page:     2,
+			pageSize: 2,
+			keyword:  "",
+			mockTags: []types.Tag{
+				{ID: 3, Name: "tag3"},
+				{ID: 4, Name: "tag4"},
+			},
+			mockTotal: 5,
+			mockErr:   nil,
+			expected: &types.TagList{
+				Total:       5,
+				TotalPages:  3,
+				CurrentPage: 2,
+				PageSize:    2,
+				Data: []types.Tag{
+					{ID: 3, Name: "tag3"},
+					{ID: 4, Name: "tag4"},
+				},
+			},
+			expErr: nil,
+		},
+		{
+			name:     "last partial page",
+			page:     3,
+			pageSize: 2,
+			keyword:  "",
+			mockTags: []types.Tag{
+				{ID: 5, Name: "tag5"},
+			},
+			mockTotal: 5,
+			mockErr:   nil,
+			expected: &types.TagList{
+				Total:       5,
+				TotalPages:  3,
+				CurrentPage: 3,
+				PageSize:    2,
+				Data: []types.Tag{
+					{ID: 5, Name: "tag5"},
+				},
+			},
+			expErr: nil,
+		},
+		{
+			name:      "storage error",
+			page:      1,
+			pageSize:  2,
+			keyword:   "",
+			mockTags:  nil,
+			mockTotal: 0,
+			mockErr:   errors.New("storage error"),
+			expected:  nil,
+			expErr:    errors.New("storage error"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockStorage.On("List", 0, tt.keyword, (tt.page-1)*tt.pageSize, tt.pageSize).Return(tt.mockTags, tt.mockErr)
+			mockStorage := new(MockTagStorage)
+			service := &TagServiceImpl{
+				storage: mockStorage,
+			}
+			service.Start(context.Background())
+
+			mockStorage.On("List", 0, tt.keyword, (tt.page-1)*tt.pageSize, tt.pageSize).Return(tt.mockTags, tt.mockTotal, tt.mockErr)
 			result := service.List(tt.page, tt.pageSize, tt.keyword)
 
 			if result.Success != 1 {
 				assert.Equal(t, tt.expErr.Error(), result.Msg)
 			} else {
-				assert.Equal(t, tt.expected, result)
+				assert.Equal(t, tt.expected, result.Data)
 			}
 			mockStorage.AssertExpectations(t)
 		})
@@ -130,18 +211,13 @@ func TestList(t *testing.T) {
 }
 
 func TestCreate(t *testing.T) {
-	mockStorage := new(MockTagStorage)
-	service := &TagServiceImpl{
-		storage: mockStorage,
-	}
-	service.Start(context.Background())
-
 	tests := []struct {
 		name     string
 		tagName  string
 		mockErr  error
 		expected *types.Tag
 		expErr   error
+		expCode  string
 	}{
 		{
 			name:    "success",
@@ -158,6 +234,15 @@ func TestCreate(t *testing.T) {
 			mockErr:  nil,
 			expected: nil,
 			expErr:   errors.New("tag name cannot be empty"),
+			expCode:  "TAG_NAME_EMPTY",
+		},
+		{
+			name:     "duplicate name",
+			tagName:  "newtag",
+			mockErr:  types.ErrTagExists,
+			expected: nil,
+			expErr:   types.ErrTagExists,
+			expCode:  "TAG_EXISTS",
 		},
 		{
 			name:     "storage error",
@@ -165,11 +250,17 @@ func TestCreate(t *testing.T) {
 			mockErr:  errors.New("storage error"),
 			expected: nil,
 			expErr:   errors.New("storage error"),
+			expCode:  "INTERNAL",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockTagStorage)
+			service := &TagServiceImpl{
+				storage: mockStorage,
+			}
+			service.Start(context.Background())
 			if tt.tagName != "" {
 				tmpData := &types.Tag{Name: tt.tagName}
 				mockStorage.On("Create", tmpData).Return(tt.mockErr)
@@ -179,8 +270,9 @@ func TestCreate(t *testing.T) {
 
 			if result.Success != 1 {
 				assert.Equal(t, tt.expErr.Error(), result.Msg)
+				assert.Equal(t, tt.expCode, result.Code)
 			} else {
-				assert.Equal(t, tt.expected, result)
+				assert.Equal(t, tt.expected, result.Data)
 			}
 			mockStorage.AssertExpectations(t)
 		})
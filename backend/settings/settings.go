@@ -0,0 +1,317 @@
+// Package settings keeps the app preferences in a JSON file in the user's
+// config directory. The file records the schema version it was written with,
+// older files are migrated when they are loaded and every value is validated
+// before it is saved.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/speech"
+	"langlearner1/backend/srs"
+	"langlearner1/backend/translate"
+	"langlearner1/backend/types"
+)
+
+// SchemaVersion is the version of the settings file this app writes
+const SchemaVersion = 1
+
+// Limits of the practice timings in milliseconds
+const (
+	MinTimeout = 500
+	MaxTimeout = 60000
+)
+
+// migrations upgrade the content of an older settings file, migrations[v]
+// converts version v to version v+1
+var migrations = map[int]func(raw map[string]interface{}) error{}
+
+// DefaultPath returns the location of the settings file in the user's config
+// directory, or under data/ when the system has none
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join("data", "settings.json")
+	}
+	return filepath.Join(dir, "langlearner", "settings.json")
+}
+
+// Defaults returns the settings of a new installation
+func Defaults() types.Settings {
+	return types.Settings{
+		Version: SchemaVersion,
+		DataDir: "data",
+		Practice: types.PracticeSettings{
+			QuestionTimeout: 3000,
+			AnswerTimeout:   4000,
+		},
+		Review: types.ReviewSettings{Algorithm: "sm2"},
+	}
+}
+
+// Choices returns the values accepted by the settings with a fixed set of values
+func Choices() types.SettingsChoices {
+	return types.SettingsChoices{
+		Locales:      i18n.Locales(),
+		Algorithms:   srs.Names(),
+		Translators:  translate.Names(),
+		Synthesizers: speech.Names(),
+	}
+}
+
+// Validate checks every value of settings, the problems are returned joined
+// as field errors
+func Validate(settings types.Settings) error {
+	choices := Choices()
+	var invalid []error
+	if settings.Locale != "" && !slices.Contains(choices.Locales, settings.Locale) {
+		invalid = append(invalid, types.NewFieldError("locale", types.ErrUnknownLocale))
+	}
+	if settings.DataDir == "" {
+		invalid = append(invalid, types.NewFieldError("data_dir", types.ErrDataDirEmpty))
+	}
+	if !validTimeout(settings.Practice.QuestionTimeout) {
+		invalid = append(invalid, types.NewFieldError("practice.question_timeout", types.ErrInvalidTimeout))
+	}
+	if !validTimeout(settings.Practice.AnswerTimeout) {
+		invalid = append(invalid, types.NewFieldError("practice.answer_timeout", types.ErrInvalidTimeout))
+	}
+	if !slices.Contains(choices.Algorithms, settings.Review.Algorithm) {
+		invalid = append(invalid, types.NewFieldError("review.algorithm", types.ErrUnknownAlgorithm))
+	}
+	if settings.Translation.Provider != "" && !slices.Contains(choices.Translators, settings.Translation.Provider) {
+		invalid = append(invalid, types.NewFieldError("translation.provider", types.ErrUnknownTranslator))
+	}
+	if settings.Speech.Provider != "" && !slices.Contains(choices.Synthesizers, settings.Speech.Provider) {
+		invalid = append(invalid, types.NewFieldError("speech.provider", types.ErrUnknownSynthesizer))
+	}
+	return errors.Join(invalid...)
+}
+
+func validTimeout(timeout int) bool {
+	return timeout >= MinTimeout && timeout <= MaxTimeout
+}
+
+// Locale returns the locale backend messages are translated to with settings
+func Locale(settings types.Settings) string {
+	if settings.Locale == "" {
+		return i18n.SystemLocale()
+	}
+	return settings.Locale
+}
+
+// resetFields sets the settings fields named like the field errors of
+// Validate to their value in defaults
+var resetFields = map[string]func(settings *types.Settings, defaults types.Settings){
+	"locale":                    func(s *types.Settings, d types.Settings) { s.Locale = d.Locale },
+	"data_dir":                  func(s *types.Settings, d types.Settings) { s.DataDir = d.DataDir },
+	"practice.question_timeout": func(s *types.Settings, d types.Settings) { s.Practice.QuestionTimeout = d.Practice.QuestionTimeout },
+	"practice.answer_timeout":   func(s *types.Settings, d types.Settings) { s.Practice.AnswerTimeout = d.Practice.AnswerTimeout },
+	"review.algorithm":          func(s *types.Settings, d types.Settings) { s.Review.Algorithm = d.Review.Algorithm },
+	"translation.provider":      func(s *types.Settings, d types.Settings) { s.Translation.Provider = d.Translation.Provider },
+	"speech.provider":           func(s *types.Settings, d types.Settings) { s.Speech.Provider = d.Speech.Provider },
+}
+
+// Manager reads and writes the settings file
+type Manager struct {
+	mu       sync.Mutex
+	path     string
+	settings types.Settings
+	problems []error
+}
+
+// Load reads the settings at path. Values missing from the file take their
+// defaults, and without the file the defaults are written to it. A file the app
+// cannot use does not keep it from starting: invalid values fall back to their
+// defaults, and a file of a newer schema version or that is not JSON is read
+// as far as it can be and left alone. Problems tells what was not used.
+func Load(path string) (*Manager, error) {
+	m := &Manager{path: path, settings: Defaults()}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := m.decode(data); err != nil {
+			// Saving would lose what a newer version wrote or a broken edit by hand
+			m.problems = append(m.problems, fmt.Errorf("settings: %s is left unchanged: %w", path, err))
+			return m, nil
+		}
+	}
+	if err := m.save(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decode migrates the content of a settings file to SchemaVersion and reads it
+// over the defaults, the invalid values fall back to their defaults. The
+// content of a newer file is read as it is and ErrSettingsTooNew is returned.
+func (m *Manager) decode(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	// A file without a version, such as one written by hand, is read as the current version
+	version := SchemaVersion
+	if value, ok := raw["version"].(float64); ok {
+		version = int(value)
+	}
+	var newer error
+	if version > SchemaVersion {
+		newer = types.ErrSettingsTooNew
+	}
+	for ; version < SchemaVersion; version++ {
+		if migrate, ok := migrations[version]; ok {
+			if err := migrate(raw); err != nil {
+				return err
+			}
+		}
+	}
+	raw["version"] = SchemaVersion
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	// A value of the wrong type keeps its default, the other values are still read
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(data, &m.settings); errors.As(err, &typeErr) {
+		m.problems = append(m.problems, fmt.Errorf("settings: %s: %w, the default is used", typeErr.Field, err))
+	} else if err != nil {
+		return err
+	}
+	m.settings = normalize(m.settings)
+	m.fallback(Validate(m.settings))
+	return newer
+}
+
+// fallback sets the values with the field errors in invalid to their defaults
+func (m *Manager) fallback(invalid error) {
+	if invalid == nil {
+		return
+	}
+	defaults := Defaults()
+	for _, err := range invalid.(interface{ Unwrap() []error }).Unwrap() {
+		var field *types.FieldError
+		if !errors.As(err, &field) {
+			continue
+		}
+		resetFields[field.Field](&m.settings, defaults)
+		m.problems = append(m.problems, fmt.Errorf("settings: %s: %w, the default is used", field.Field, field.Err))
+	}
+}
+
+// Problems returns what of the settings file Load could not use
+func (m *Manager) Problems() []error {
+	return m.problems
+}
+
+// Path returns the location of the settings file
+func (m *Manager) Path() string {
+	return m.path
+}
+
+// Get returns the current settings
+func (m *Manager) Get() types.Settings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.settings
+}
+
+// Update validates settings and saves them, the version is always SchemaVersion
+func (m *Manager) Update(settings types.Settings) (types.Settings, error) {
+	settings = normalize(settings)
+	if err := Validate(settings); err != nil {
+		return types.Settings{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous := m.settings
+	m.settings = settings
+	if err := m.save(); err != nil {
+		m.settings = previous
+		return types.Settings{}, err
+	}
+	return settings, nil
+}
+
+// Reset saves the default settings
+func (m *Manager) Reset() (types.Settings, error) {
+	return m.Update(Defaults())
+}
+
+// normalize trims the text values of settings and sets the current version
+func normalize(settings types.Settings) types.Settings {
+	settings.Version = SchemaVersion
+	for _, value := range []*string{
+		&settings.Locale,
+		&settings.DataDir,
+		&settings.Review.Algorithm,
+		&settings.Translation.Provider,
+		&settings.Speech.Provider,
+		&settings.Speech.Voice,
+	} {
+		*value = strings.TrimSpace(*value)
+	}
+	return settings
+}
+
+// save writes the settings, replacing the file only once it is fully written
+func (m *Manager) save() error {
+	data, err := json.MarshalIndent(m.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// DataPath returns the location of the file name in the data directory of settings
+func DataPath(settings types.Settings, name string) string {
+	return filepath.Join(settings.DataDir, name)
+}
+
+// Translators creates the translators of the pair configs at path, only the
+// pairs of the provider chosen in settings are used
+func Translators(path string, settings types.Settings) (*translate.Pairs, error) {
+	configs, err := translate.LoadPairConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	if provider := settings.Translation.Provider; provider != "" {
+		configs = slices.DeleteFunc(configs, func(config translate.PairConfig) bool {
+			return config.Provider != provider
+		})
+	}
+	return translate.NewPairs(configs)
+}
+
+// Synthesizer creates the synthesizer configured at path with the provider
+// and voice of settings, a missing file configures none and returns nil
+func Synthesizer(path string, settings types.Settings) (speech.SpeechSynthesizer, error) {
+	config, err := speech.LoadConfig(path)
+	if config == nil || err != nil {
+		return nil, err
+	}
+	if settings.Speech.Provider != "" {
+		config.Provider = settings.Speech.Provider
+	}
+	if settings.Speech.Voice != "" {
+		config.Voice = settings.Speech.Voice
+	}
+	return speech.New(*config)
+}
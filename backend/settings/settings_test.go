@@ -0,0 +1,204 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestLoadCreatesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "langlearner", "settings.json")
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Defaults(), m.Get())
+	require.NoError(t, Validate(Defaults()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var saved types.Settings
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, Defaults(), saved)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	// Values missing from a file written by hand keep their defaults
+	require.NoError(t, os.WriteFile(path, []byte(`{"locale": " zh-CN ", "practice": {"answer_timeout": 6000}}`), 0644))
+	m, err := Load(path)
+	require.NoError(t, err)
+	want := Defaults()
+	want.Locale = "zh-CN"
+	want.Practice.AnswerTimeout = 6000
+	assert.Equal(t, want, m.Get())
+	assert.Empty(t, m.Problems())
+}
+
+func TestLoadFallsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+
+	// Invalid values fall back to their defaults, the valid ones are kept
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"locale": "zh-CN",
+		"review": {"algorithm": "anki"},
+		"practice": {"question_timeout": 100, "answer_timeout": 6000},
+		"speech": {"provider": "say"}
+	}`), 0644))
+	m, err := Load(path)
+	require.NoError(t, err)
+	want := Defaults()
+	want.Locale = "zh-CN"
+	want.Practice.AnswerTimeout = 6000
+	assert.Equal(t, want, m.Get())
+	require.Len(t, m.Problems(), 3)
+	assert.ErrorIs(t, m.Problems()[0], types.ErrInvalidTimeout)
+	assert.ErrorIs(t, m.Problems()[1], types.ErrUnknownAlgorithm)
+	assert.ErrorIs(t, m.Problems()[2], types.ErrUnknownSynthesizer)
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, loaded.Get(), "the fixed settings are saved")
+	assert.Empty(t, loaded.Problems())
+
+	// A value of the wrong type keeps its default
+	require.NoError(t, os.WriteFile(path, []byte(`{"locale": "zh-CN", "practice": {"answer_timeout": "6s"}}`), 0644))
+	m, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "zh-CN", m.Get().Locale)
+	assert.Equal(t, Defaults().Practice.AnswerTimeout, m.Get().Practice.AnswerTimeout)
+	require.Len(t, m.Problems(), 1)
+	assert.Contains(t, m.Problems()[0].Error(), "practice.answer_timeout")
+
+	// A newer file is read but not overwritten
+	newer := []byte(`{"version": 2, "locale": "zh-CN", "review": {"algorithm": "sm5"}}`)
+	require.NoError(t, os.WriteFile(path, newer, 0644))
+	m, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "zh-CN", m.Get().Locale)
+	assert.Equal(t, "sm2", m.Get().Review.Algorithm)
+	require.Len(t, m.Problems(), 2)
+	assert.ErrorIs(t, m.Problems()[1], types.ErrSettingsTooNew)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, newer, data)
+
+	// So is a file that is not JSON
+	broken := []byte(`{"locale": "zh-CN",`)
+	require.NoError(t, os.WriteFile(path, broken, 0644))
+	m, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Defaults(), m.Get())
+	require.Len(t, m.Problems(), 1)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, broken, data)
+}
+
+func TestLoadMigrates(t *testing.T) {
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = map[int]func(raw map[string]interface{}) error{
+		0: func(raw map[string]interface{}) error {
+			raw["practice"] = map[string]interface{}{"question_timeout": raw["timeout"]}
+			delete(raw, "timeout")
+			return nil
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 0, "timeout": 1500}`), 0644))
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1500, m.Get().Practice.QuestionTimeout)
+	assert.Equal(t, Defaults().Practice.AnswerTimeout, m.Get().Practice.AnswerTimeout)
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, loaded.Get().Version, "the migrated file is saved")
+	assert.Equal(t, m.Get(), loaded.Get())
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	m, err := Load(path)
+	require.NoError(t, err)
+
+	invalid := Defaults()
+	invalid.Locale = "fr"
+	invalid.DataDir = " "
+	invalid.Practice.QuestionTimeout = 100
+	invalid.Speech.Provider = "say"
+	_, err = m.Update(invalid)
+	var resp types.JSResp
+	resp.SetError(err)
+	fields := map[string]string{}
+	for _, detail := range resp.Details {
+		fields[detail.Field] = detail.Code
+	}
+	assert.Equal(t, map[string]string{
+		"locale":                    "UNKNOWN_LOCALE",
+		"data_dir":                  "DATA_DIR_EMPTY",
+		"practice.question_timeout": "INVALID_TIMEOUT",
+		"speech.provider":           "UNKNOWN_SYNTHESIZER",
+	}, fields)
+	assert.Equal(t, Defaults(), m.Get())
+
+	changed := Defaults()
+	changed.Version = 0
+	changed.Review.Algorithm = "fsrs"
+	changed.Translation.Provider = "libretranslate"
+	updated, err := m.Update(changed)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, updated.Version)
+	assert.Equal(t, updated, m.Get())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, updated, loaded.Get())
+
+	reset, err := loaded.Reset()
+	require.NoError(t, err)
+	assert.Equal(t, Defaults(), reset)
+}
+
+func TestProviders(t *testing.T) {
+	dir := t.TempDir()
+	config := Defaults()
+	config.DataDir = dir
+
+	pairs, err := Translators(DataPath(config, "translate.json"), config)
+	require.NoError(t, err)
+	_, _, err = pairs.For("ja", "en")
+	assert.ErrorIs(t, err, types.ErrNoTranslator)
+
+	// The pairs of other providers are left out before they are created
+	require.NoError(t, os.WriteFile(DataPath(config, "translate.json"), []byte(`[
+		{"source": "ja", "target": "en", "provider": "babelfish"},
+		{"source": "*", "target": "*", "provider": "libretranslate", "endpoint": "http://localhost:5000"}
+	]`), 0644))
+	_, err = Translators(DataPath(config, "translate.json"), config)
+	assert.ErrorIs(t, err, types.ErrUnknownTranslator)
+	config.Translation.Provider = "libretranslate"
+	pairs, err = Translators(DataPath(config, "translate.json"), config)
+	require.NoError(t, err)
+	translator, _, err := pairs.For("ja", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "libretranslate", translator.Name())
+
+	synthesizer, err := Synthesizer(DataPath(config, "speech.json"), config)
+	require.NoError(t, err)
+	assert.Nil(t, synthesizer)
+
+	require.NoError(t, os.WriteFile(DataPath(config, "speech.json"),
+		[]byte(`{"provider": "command", "command": ["espeak-ng"], "endpoint": "http://localhost:5002"}`), 0644))
+	synthesizer, err = Synthesizer(DataPath(config, "speech.json"), config)
+	require.NoError(t, err)
+	assert.Equal(t, "command", synthesizer.Name())
+	config.Speech.Provider = "http"
+	synthesizer, err = Synthesizer(DataPath(config, "speech.json"), config)
+	require.NoError(t, err)
+	assert.Equal(t, "http", synthesizer.Name())
+}
@@ -0,0 +1,132 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"langlearner1/backend/types"
+)
+
+// Placeholders replaced in the arguments of the command backend
+const (
+	TextPlaceholder   = "{text}"
+	VoicePlaceholder  = "{voice}"
+	OutputPlaceholder = "{output}"
+)
+
+// Command runs a local text-to-speech program such as espeak-ng or piper.
+//
+// The text is passed as the {text} argument, or on stdin when no argument
+// contains {text}. The audio is read from the {output} file, or from stdout
+// when no argument contains {output}. For example:
+//
+//	["espeak-ng", "-v", "{voice}", "-w", "{output}", "{text}"]
+//	["piper", "--model", "{voice}", "--output_file", "{output}"]
+type Command struct {
+	config  Config
+	format  string
+	useFile bool
+	stdin   bool
+}
+
+// NewCommand creates a command backend from config.Command
+func NewCommand(config Config) (*Command, error) {
+	if len(config.Command) == 0 {
+		return nil, fmt.Errorf("%w: command", types.ErrProviderCommand)
+	}
+	c := &Command{
+		config: config,
+		format: strings.TrimPrefix(config.Format, "."),
+		stdin:  true,
+	}
+	if c.format == "" {
+		c.format = "wav"
+	}
+	for _, arg := range config.Command {
+		if strings.Contains(arg, OutputPlaceholder) {
+			c.useFile = true
+		}
+		if strings.Contains(arg, TextPlaceholder) {
+			c.stdin = false
+		}
+	}
+	return c, nil
+}
+
+func (c *Command) Name() string {
+	return "command"
+}
+
+// VoiceFor returns the voice configured for language
+func (c *Command) VoiceFor(language string) string {
+	return c.config.VoiceFor(language)
+}
+
+// Synthesize runs the command and returns the audio it produced
+func (c *Command) Synthesize(ctx context.Context, text string, voice string) ([]byte, string, error) {
+	if voice == "" {
+		voice = c.config.Voice
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.timeout())
+	defer cancel()
+
+	output := ""
+	if c.useFile {
+		dir, err := os.MkdirTemp("", "langlearner-tts-*")
+		if err != nil {
+			return nil, "", err
+		}
+		defer os.RemoveAll(dir)
+		output = filepath.Join(dir, "speech."+c.format)
+	}
+	replacer := strings.NewReplacer(TextPlaceholder, text, VoicePlaceholder, voice, OutputPlaceholder, output)
+	args := make([]string, len(c.config.Command))
+	for i, arg := range c.config.Command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if c.stdin {
+		cmd.Stdin = strings.NewReader(text)
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, "", fmt.Errorf("%w: %s: %w: %s", types.ErrSpeechFailed, filepath.Base(args[0]), err, strings.TrimSpace(stderr.String()))
+	}
+
+	audio := stdout.Bytes()
+	if c.useFile {
+		var err error
+		if audio, err = os.ReadFile(output); err != nil {
+			return nil, "", err
+		}
+	}
+	if len(audio) == 0 {
+		return nil, "", fmt.Errorf("%w: %s produced no audio", types.ErrSpeechFailed, filepath.Base(args[0]))
+	}
+	return audio, formatMimeType(c.format), nil
+}
+
+// formatMimeType returns the MIME type of an audio file extension
+func formatMimeType(format string) string {
+	switch format {
+	case "wav":
+		return "audio/wav"
+	case "mp3":
+		return "audio/mpeg"
+	case "ogg", "opus":
+		return "audio/ogg"
+	}
+	if mimeType := mime.TypeByExtension("." + format); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
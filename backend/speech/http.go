@@ -0,0 +1,87 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"langlearner1/backend/types"
+)
+
+// maxAudioSize limits the audio accepted from an HTTP backend
+const maxAudioSize = 20 << 20
+
+// HTTP posts {"text": ..., "voice": ...} as JSON to an endpoint that responds
+// with the audio, its Content-Type header giving the format
+type HTTP struct {
+	config Config
+	client *http.Client
+}
+
+// NewHTTP creates an HTTP backend for config.Endpoint, config.APIKey is sent as a bearer token
+func NewHTTP(config Config) (*HTTP, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("%w: http", types.ErrProviderEndpoint)
+	}
+	return &HTTP{
+		config: config,
+		client: http.DefaultClient,
+	}, nil
+}
+
+func (h *HTTP) Name() string {
+	return "http"
+}
+
+// VoiceFor returns the voice configured for language
+func (h *HTTP) VoiceFor(language string) string {
+	return h.config.VoiceFor(language)
+}
+
+// Synthesize requests the audio of text from the endpoint
+func (h *HTTP) Synthesize(ctx context.Context, text string, voice string) ([]byte, string, error) {
+	if voice == "" {
+		voice = h.config.Voice
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.config.timeout())
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{"text": text, "voice": voice})
+	if err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.config.APIKey)
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	audio, err := io.ReadAll(io.LimitReader(res.Body, maxAudioSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%w: http backend returned %s: %s", types.ErrSpeechFailed, res.Status, strings.TrimSpace(string(audio[:min(len(audio), 200)])))
+	}
+	if len(audio) > maxAudioSize {
+		return nil, "", fmt.Errorf("%w: audio from http backend is too large", types.ErrSpeechFailed)
+	}
+	mimeType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mimeType, "audio/") {
+		return nil, "", fmt.Errorf("%w: http backend returned %q instead of audio", types.ErrSpeechFailed, res.Header.Get("Content-Type"))
+	}
+	return audio, mimeType, nil
+}
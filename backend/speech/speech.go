@@ -0,0 +1,134 @@
+// Package speech generates sentence audio through pluggable text-to-speech backends.
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"langlearner1/backend/types"
+)
+
+// SpeechSynthesizer turns text into audio
+type SpeechSynthesizer interface {
+	// Name returns the registry name of the backend
+	Name() string
+	// Synthesize returns the audio of text spoken by voice and its MIME type.
+	// An empty voice uses the default voice of the backend.
+	Synthesize(ctx context.Context, text string, voice string) ([]byte, string, error)
+}
+
+// VoiceSelector is implemented by synthesizers that choose a voice by language
+type VoiceSelector interface {
+	// VoiceFor returns the voice for text in a BCP 47 language, "" for the default voice
+	VoiceFor(language string) string
+}
+
+// Config configures a backend
+type Config struct {
+	// Provider is the registry name of the backend
+	Provider string `json:"provider"`
+	// Voice is used when a request names no voice
+	Voice string `json:"voice"`
+	// Voices maps BCP 47 language codes to the voice used for text in that
+	// language, such as {"ja": "ja", "ko": "ko"} for espeak-ng
+	Voices map[string]string `json:"voices"`
+	// Command is the program and arguments of the command backend, see NewCommand
+	Command []string `json:"command"`
+	// Format is the audio file extension the command writes, "wav" when empty
+	Format string `json:"format"`
+	// Endpoint and APIKey configure the HTTP backend
+	Endpoint string `json:"endpoint"`
+	APIKey   string `json:"api_key"`
+	// Timeout limits a single synthesis in seconds, 0 means DefaultTimeout
+	Timeout int `json:"timeout"`
+}
+
+// VoiceFor returns the voice configured for language. A regional code such as
+// "es-MX" falls back to the voice of "es", "" means the default voice.
+func (c Config) VoiceFor(language string) string {
+	for language != "" {
+		for code, voice := range c.Voices {
+			if strings.EqualFold(code, language) {
+				return voice
+			}
+		}
+		i := strings.LastIndex(language, "-")
+		if i < 0 {
+			break
+		}
+		language = language[:i]
+	}
+	return ""
+}
+
+// DefaultTimeout limits a synthesis when the config sets no timeout
+const DefaultTimeout = 30 * time.Second
+
+// timeout returns the configured synthesis timeout
+func (c Config) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// registry maps backend names to synthesizer constructors
+var registry = map[string]func(Config) (SpeechSynthesizer, error){
+	"command": func(config Config) (SpeechSynthesizer, error) { return NewCommand(config) },
+	"http":    func(config Config) (SpeechSynthesizer, error) { return NewHTTP(config) },
+}
+
+// Register makes a backend available under the given name
+func Register(name string, factory func(Config) (SpeechSynthesizer, error)) {
+	registry[name] = factory
+}
+
+// New creates the synthesizer configured by config
+func New(config Config) (SpeechSynthesizer, error) {
+	factory, ok := registry[config.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", types.ErrUnknownSynthesizer, config.Provider)
+	}
+	return factory(config)
+}
+
+// Names returns the registered backend names in sorted order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load creates the synthesizer configured in a JSON file, a missing file configures none and returns nil
+func Load(path string) (SpeechSynthesizer, error) {
+	config, err := LoadConfig(path)
+	if config == nil || err != nil {
+		return nil, err
+	}
+	return New(*config)
+}
+
+// LoadConfig reads a synthesizer config from a JSON file, a missing file returns nil
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", types.ErrProviderConfig, path, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,135 @@
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestCommandOutputFile(t *testing.T) {
+	synth, err := New(Config{
+		Provider: "command",
+		Command:  []string{"sh", "-c", `printf 'RIFF %s %s' "$1" "$2" > "$0"`, "{output}", "{voice}", "{text}"},
+		Voice:    "ja",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "command", synth.Name())
+
+	audio, mimeType, err := synth.Synthesize(context.Background(), "いぬ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF ja いぬ", string(audio))
+	assert.Equal(t, "audio/wav", mimeType)
+
+	audio, _, err = synth.Synthesize(context.Background(), "いぬ", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF en いぬ", string(audio))
+}
+
+func TestCommandStdio(t *testing.T) {
+	synth, err := New(Config{Provider: "command", Command: []string{"sh", "-c", `printf 'ID3 '; cat`}, Format: "mp3"})
+	require.NoError(t, err)
+
+	audio, mimeType, err := synth.Synthesize(context.Background(), "いぬ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "ID3 いぬ", string(audio))
+	assert.Equal(t, "audio/mpeg", mimeType)
+}
+
+func TestCommandErrors(t *testing.T) {
+	_, err := New(Config{Provider: "command"})
+	assert.ErrorIs(t, err, types.ErrProviderCommand)
+
+	synth, err := New(Config{Provider: "command", Command: []string{"sh", "-c", "echo broken voice >&2; exit 1"}})
+	require.NoError(t, err)
+	_, _, err = synth.Synthesize(context.Background(), "いぬ", "")
+	assert.ErrorIs(t, err, types.ErrSpeechFailed)
+	assert.ErrorContains(t, err, "broken voice")
+
+	synth, err = New(Config{Provider: "command", Command: []string{"true"}})
+	require.NoError(t, err)
+	_, _, err = synth.Synthesize(context.Background(), "いぬ", "")
+	assert.ErrorContains(t, err, "no audio")
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.Header.Get("Authorization") != "Bearer secret":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case req["text"] == "html":
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, "<p>oops</p>")
+		default:
+			w.Header().Set("Content-Type", "audio/ogg; codecs=opus")
+			io.WriteString(w, "OggS "+req["voice"]+" "+req["text"])
+		}
+	}))
+	defer server.Close()
+
+	synth, err := New(Config{Provider: "http", Endpoint: server.URL, APIKey: "secret", Voice: "default"})
+	require.NoError(t, err)
+	audio, mimeType, err := synth.Synthesize(context.Background(), "いぬ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "OggS default いぬ", string(audio))
+	assert.Equal(t, "audio/ogg", mimeType)
+
+	_, _, err = synth.Synthesize(context.Background(), "html", "")
+	assert.ErrorIs(t, err, types.ErrSpeechFailed)
+	assert.ErrorContains(t, err, "instead of audio")
+
+	synth, err = New(Config{Provider: "http", Endpoint: server.URL})
+	require.NoError(t, err)
+	_, _, err = synth.Synthesize(context.Background(), "いぬ", "")
+	assert.ErrorContains(t, err, "401")
+
+	_, err = New(Config{Provider: "http"})
+	assert.ErrorIs(t, err, types.ErrProviderEndpoint)
+}
+
+func TestNewUnknown(t *testing.T) {
+	_, err := New(Config{Provider: "nope"})
+	assert.ErrorIs(t, err, types.ErrUnknownSynthesizer)
+	assert.Equal(t, []string{"command", "http"}, Names())
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	synth, err := Load(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Nil(t, synth)
+
+	path := filepath.Join(dir, "speech.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"provider": "command", "command": ["espeak-ng", "{text}"]}`), 0o644))
+	synth, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "command", synth.Name())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
+
+func TestVoiceFor(t *testing.T) {
+	config := Config{Voice: "default", Voices: map[string]string{"ja": "jp", "es-MX": "mexican", "zh-cn": "mandarin"}}
+	assert.Equal(t, "jp", config.VoiceFor("ja"))
+	assert.Equal(t, "jp", config.VoiceFor("ja-JP"), "a regional code falls back to its language")
+	assert.Equal(t, "mexican", config.VoiceFor("es-MX"))
+	assert.Equal(t, "", config.VoiceFor("es"))
+	assert.Equal(t, "mandarin", config.VoiceFor("zh-CN"), "codes are matched ignoring case")
+	assert.Equal(t, "", config.VoiceFor(""))
+}
@@ -0,0 +1,101 @@
+package srs
+
+import (
+	"math"
+	"time"
+
+	"langlearner1/backend/types"
+)
+
+const (
+	fsrsDecay       = -0.5
+	fsrsFactor      = 19.0 / 81.0
+	fsrsMaxInterval = 36500
+)
+
+// fsrsDefaultWeights are the published FSRS-4.5 default parameters
+var fsrsDefaultWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// FSRS implements the Free Spaced Repetition Scheduler (version 4.5)
+type FSRS struct {
+	Weights [17]float64
+	// RequestRetention is the probability of recall targeted at the due date
+	RequestRetention float64
+}
+
+// NewFSRS creates a new FSRS scheduler with the default parameters
+func NewFSRS() *FSRS {
+	return &FSRS{
+		Weights:          fsrsDefaultWeights,
+		RequestRetention: 0.9,
+	}
+}
+
+// Name returns the registry name of the algorithm
+func (f *FSRS) Name() string {
+	return "fsrs"
+}
+
+// Schedule returns the state after reviewing at now with the given grade
+func (f *FSRS) Schedule(state types.ReviewState, grade Grade, now time.Time) types.ReviewState {
+	w := f.Weights
+	g := float64(grade)
+
+	if state.Stability == 0 && state.Reps > 0 {
+		// The note was scheduled by another algorithm, derive a memory state from it
+		state.Stability = math.Max(float64(state.Interval), 1)
+		state.Difficulty = f.clampDifficulty(11 - 4*math.Max(state.Ease-1.3, 0))
+	}
+
+	if state.Stability == 0 {
+		state.Stability = w[grade-1]
+		state.Difficulty = f.clampDifficulty(w[4] - (g-3)*w[5])
+	} else {
+		elapsed := math.Max(float64(now.Unix()-state.LastReview)/Day.Seconds(), 0)
+		r := f.retrievability(elapsed, state.Stability)
+		d := state.Difficulty
+		s := state.Stability
+		if grade == Again {
+			state.Stability = w[11] * math.Pow(d, -w[12]) * (math.Pow(s+1, w[13]) - 1) * math.Exp(w[14]*(1-r))
+			state.Lapses++
+		} else {
+			bonus := 1.0
+			if grade == Hard {
+				bonus = w[15]
+			} else if grade == Easy {
+				bonus = w[16]
+			}
+			state.Stability = s * (1 + math.Exp(w[8])*(11-d)*math.Pow(s, -w[9])*(math.Exp(w[10]*(1-r))-1)*bonus)
+		}
+		next := d - w[6]*(g-3)
+		state.Difficulty = f.clampDifficulty(w[7]*w[4] + (1-w[7])*next)
+	}
+
+	if grade == Again {
+		state.Reps = 0
+	} else {
+		state.Reps++
+	}
+	state.Interval = f.interval(state.Stability)
+	state.LastReview = now.Unix()
+	state.Due = due(now, state.Interval)
+	return state
+}
+
+// retrievability returns the probability of recall after elapsed days
+func (f *FSRS) retrievability(elapsed, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsed/stability, fsrsDecay)
+}
+
+// interval returns the number of days until recall drops to the requested retention
+func (f *FSRS) interval(stability float64) int {
+	days := stability / fsrsFactor * (math.Pow(f.RequestRetention, 1/fsrsDecay) - 1)
+	return int(math.Min(math.Max(math.Round(days), 1), fsrsMaxInterval))
+}
+
+func (f *FSRS) clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
@@ -0,0 +1,74 @@
+// Package srs implements the spaced repetition algorithms used to schedule note reviews.
+package srs
+
+import (
+	"sort"
+	"time"
+
+	"langlearner1/backend/types"
+)
+
+// Grade is the recall quality reported for a review
+type Grade int
+
+const (
+	// Again means the note was forgotten
+	Again Grade = iota + 1
+	// Hard means the note was recalled with serious difficulty
+	Hard
+	// Good means the note was recalled after some hesitation
+	Good
+	// Easy means the note was recalled effortlessly
+	Easy
+)
+
+// Valid reports whether g is one of the known grades
+func (g Grade) Valid() bool {
+	return g >= Again && g <= Easy
+}
+
+// Day is the length of a scheduling day
+const Day = 24 * time.Hour
+
+// Scheduler computes the next review state of a note from its current state and a grade
+type Scheduler interface {
+	// Name returns the registry name of the algorithm
+	Name() string
+	// Schedule returns the state after reviewing at now with the given grade
+	Schedule(state types.ReviewState, grade Grade, now time.Time) types.ReviewState
+}
+
+// registry maps algorithm names to scheduler constructors
+var registry = map[string]func() Scheduler{
+	"sm2":  func() Scheduler { return NewSM2() },
+	"fsrs": func() Scheduler { return NewFSRS() },
+}
+
+// Register makes a scheduler available under the given name
+func Register(name string, factory func() Scheduler) {
+	registry[name] = factory
+}
+
+// New returns the scheduler registered under name
+func New(name string) (Scheduler, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, types.ErrUnknownAlgorithm
+	}
+	return factory(), nil
+}
+
+// Names returns the registered algorithm names in sorted order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// due returns the unix time of a review interval days after now
+func due(now time.Time, interval int) int64 {
+	return now.Add(time.Duration(interval) * Day).Unix()
+}
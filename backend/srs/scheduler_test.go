@@ -0,0 +1,101 @@
+package srs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+var start = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func TestSM2Schedule(t *testing.T) {
+	s := NewSM2()
+	state := types.ReviewState{NoteID: 1}
+
+	state = s.Schedule(state, Good, start)
+	assert.Equal(t, 1, state.Interval)
+	assert.Equal(t, 1, state.Reps)
+	assert.Equal(t, start.Add(Day).Unix(), state.Due)
+
+	state = s.Schedule(state, Good, start.Add(Day))
+	assert.Equal(t, 6, state.Interval)
+
+	state = s.Schedule(state, Good, start.Add(7*Day))
+	assert.Equal(t, 15, state.Interval)
+	assert.InDelta(t, 2.5, state.Ease, 1e-9)
+
+	state = s.Schedule(state, Easy, start.Add(22*Day))
+	assert.Equal(t, 38, state.Interval)
+	assert.InDelta(t, 2.6, state.Ease, 1e-9)
+
+	state = s.Schedule(state, Again, start.Add(60*Day))
+	assert.Equal(t, 1, state.Interval)
+	assert.Equal(t, 0, state.Reps)
+	assert.Equal(t, 1, state.Lapses)
+	assert.InDelta(t, 2.06, state.Ease, 1e-9)
+	assert.Equal(t, start.Add(60*Day).Unix(), state.LastReview)
+}
+
+func TestSM2EaseFloor(t *testing.T) {
+	s := NewSM2()
+	state := types.ReviewState{NoteID: 1}
+	for i := 0; i < 10; i++ {
+		state = s.Schedule(state, Again, start)
+	}
+	assert.Equal(t, sm2MinimumEase, state.Ease)
+	assert.Equal(t, 0, state.Lapses, "failing a note that was never learned is not a lapse")
+}
+
+func TestFSRSSchedule(t *testing.T) {
+	f := NewFSRS()
+
+	first := map[Grade]int{Again: 0, Hard: 1, Good: 4, Easy: 14}
+	for grade, interval := range first {
+		state := f.Schedule(types.ReviewState{NoteID: 1}, grade, start)
+		assert.Equal(t, f.Weights[grade-1], state.Stability)
+		assert.Equal(t, max(interval, 1), state.Interval, "grade %d", grade)
+	}
+
+	state := f.Schedule(types.ReviewState{NoteID: 1}, Good, start)
+	difficulty := state.Difficulty
+	now := start.Add(time.Duration(state.Interval) * Day)
+	good := f.Schedule(state, Good, now)
+	assert.Greater(t, good.Stability, state.Stability)
+	assert.Greater(t, good.Interval, state.Interval)
+	assert.InDelta(t, difficulty, good.Difficulty, 1e-9)
+
+	easy := f.Schedule(state, Easy, now)
+	assert.Greater(t, easy.Interval, good.Interval)
+	assert.Less(t, easy.Difficulty, good.Difficulty)
+
+	lapse := f.Schedule(good, Again, now.Add(time.Duration(good.Interval)*Day))
+	assert.Less(t, lapse.Stability, good.Stability)
+	assert.Equal(t, 1, lapse.Lapses)
+	assert.Equal(t, 0, lapse.Reps)
+	assert.Greater(t, lapse.Difficulty, good.Difficulty)
+}
+
+func TestFSRSContinuesSM2State(t *testing.T) {
+	state := types.ReviewState{NoteID: 1}
+	for i := 0; i < 3; i++ {
+		state = NewSM2().Schedule(state, Good, start)
+	}
+	next := NewFSRS().Schedule(state, Good, start.Add(time.Duration(state.Interval)*Day))
+	assert.GreaterOrEqual(t, next.Interval, state.Interval)
+	assert.GreaterOrEqual(t, next.Difficulty, 1.0)
+	assert.LessOrEqual(t, next.Difficulty, 10.0)
+}
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{"fsrs", "sm2"}, Names())
+	for _, name := range Names() {
+		s, err := New(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, s.Name())
+	}
+	_, err := New("leitner")
+	assert.Equal(t, types.ErrUnknownAlgorithm, err)
+}
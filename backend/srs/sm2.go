@@ -0,0 +1,66 @@
+package srs
+
+import (
+	"math"
+	"time"
+
+	"langlearner1/backend/types"
+)
+
+const (
+	sm2InitialEase = 2.5
+	sm2MinimumEase = 1.3
+)
+
+// SM2 implements the SuperMemo 2 algorithm
+type SM2 struct{}
+
+// NewSM2 creates a new SM-2 scheduler
+func NewSM2() *SM2 {
+	return &SM2{}
+}
+
+// Name returns the registry name of the algorithm
+func (s *SM2) Name() string {
+	return "sm2"
+}
+
+// Schedule returns the state after reviewing at now with the given grade
+func (s *SM2) Schedule(state types.ReviewState, grade Grade, now time.Time) types.ReviewState {
+	if state.Ease == 0 {
+		state.Ease = sm2InitialEase
+	}
+
+	// SM-2 grades recall from 0 to 5, anything below 3 is a failure
+	q := float64(grade) + 1
+	if grade == Again {
+		q = 1
+	}
+
+	if q < 3 {
+		if state.Reps > 0 {
+			state.Lapses++
+		}
+		state.Reps = 0
+		state.Interval = 1
+	} else {
+		switch state.Reps {
+		case 0:
+			state.Interval = 1
+		case 1:
+			state.Interval = 6
+		default:
+			state.Interval = int(math.Round(float64(state.Interval) * state.Ease))
+		}
+		state.Reps++
+	}
+
+	state.Ease += 0.1 - (5-q)*(0.08+(5-q)*0.02)
+	if state.Ease < sm2MinimumEase {
+		state.Ease = sm2MinimumEase
+	}
+
+	state.LastReview = now.Unix()
+	state.Due = due(now, state.Interval)
+	return state
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// AssetStorage defines the interface for asset data persistence
+type AssetStorage interface {
+	// FindByHash returns the asset with the given content hash, or nil if there is none
+	FindByHash(hash string) (*types.Asset, error)
+	// Create creates a new asset
+	Create(asset *types.Asset) error
+	// Attach links an asset to a note, replacing the asset of an audio role
+	Attach(link *types.NoteAsset) error
+	// Detach unlinks an asset from a note
+	Detach(noteID int, assetID int, role string) error
+	// NoteAssets returns the assets linked to a note
+	NoteAssets(noteID int) ([]types.NoteAsset, error)
+	// Orphans returns the assets that are not linked to any note
+	Orphans() ([]types.Asset, error)
+	// Delete deletes assets that are still not linked to any note, and returns the deleted ones
+	Delete(ids []int) ([]types.Asset, error)
+	// Hashes returns the content hashes of all assets
+	Hashes() ([]string, error)
+}
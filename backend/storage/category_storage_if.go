@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// CategoryStorage defines the interface for category data persistence
+type CategoryStorage interface {
+	// List returns one page of categories with optional id and keyword filters, and the total number of matches
+	List(id int, keyword string, offset int, limit int) ([]types.Category, int64, error)
+	// All returns every category ordered by name
+	All() ([]types.Category, error)
+	// Create creates a new category
+	Create(category *types.Category) error
+	// Update updates an existing category
+	Update(category *types.Category) error
+	// Delete deletes a category
+	Delete(id int) error
+}
@@ -1,45 +1,115 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"langlearner1/backend/types"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 )
 
-// DB is the global database instance
-var DB *gorm.DB
-
-// once ensures InitDB is called only once
-var once sync.Once
-
-// InitDB initializes the database connection
-func InitDB(dbPath string) error {
-	var initErr error
-	once.Do(func() {
-		// Ensure the directory exists
-		dir := filepath.Dir(dbPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			initErr = err
-			return
-		}
-
-		// Open database connection
-		db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
-		if err != nil {
-			initErr = err
-			return
-		}
-
-		DB = db
-
-		// Auto migrate database schemas
-		if err := DB.AutoMigrate(&types.Tag{}, &types.Note{}); err != nil {
-			initErr = err
-			return
-		}
-	})
-	return initErr
+// Store is an open database shared by the SQLite storages. Reopen switches it
+// to another database file without recreating the storages.
+type Store struct {
+	mu sync.RWMutex
+	db *gorm.DB
+	// fts reports whether the notes_fts index can be used. It is false
+	// when the SQLite driver was built without FTS5 (the sqlite_fts5 build tag).
+	fts bool
+}
+
+// Open opens the database file at path, creating it when missing, and brings its schema up to date
+func Open(path string) (*Store, error) {
+	// Ensure the directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return open(path+"?_foreign_keys=on", path)
+}
+
+// memoryDBs numbers the in-memory databases so each one is separate
+var memoryDBs atomic.Int64
+
+// OpenMemory opens a new empty in-memory database, it is gone once closed
+func OpenMemory() (*Store, error) {
+	// The shared cache lets every pooled connection see the same database
+	dsn := fmt.Sprintf("file:memory%d?mode=memory&cache=shared&_foreign_keys=on", memoryDBs.Add(1))
+	return open(dsn, "")
+}
+
+// open opens the database at dsn, path is the file backed up before migrating
+func open(dsn string, path string) (*Store, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{db: db}
+
+	// Bring the schema up to date, see migrations
+	if err := migrate(db, path); err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	// Full-text index over notes
+	if store.fts, err = setupNoteSearch(db); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reopen opens the database file at path like Open and closes the current one.
+// The current database stays open when path cannot be opened.
+func (s *Store) Reopen(path string) error {
+	next, err := Open(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	old := s.db
+	s.db, s.fts = next.db, next.fts
+	s.mu.Unlock()
+	return closeDB(old)
+}
+
+// DB returns the database connection
+func (s *Store) DB() *gorm.DB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db
+}
+
+// searchable reports whether the notes_fts index can be used
+func (s *Store) searchable() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fts
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return closeDB(s.DB())
+}
+
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// isUniqueViolation reports whether err is a failed UNIQUE or primary key constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
 }
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"langlearner1/backend/types"
+)
+
+func TestMigrateNoteCategories(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "legacy.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	// Legacy schema where the category was a free varchar on notes
+	require.NoError(t, db.Exec(`CREATE TABLE notes (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		front text NOT NULL,
+		back text,
+		category varchar(100),
+		created_at integer,
+		updated_at integer
+	)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO notes (front, category) VALUES
+		('a', 'Grammar'), ('b', ' Grammar '), ('c', 'Travel'), ('d', ''), ('e', NULL)`).Error)
+
+	require.NoError(t, migrateBaseline(db))
+	require.NoError(t, migrateNoteCategories(db))
+
+	assert.False(t, db.Migrator().HasColumn("notes", "category"))
+
+	var categories []types.Category
+	require.NoError(t, db.Order("name").Find(&categories).Error)
+	require.Len(t, categories, 2)
+	assert.Equal(t, "Grammar", categories[0].Name)
+	assert.Equal(t, "Travel", categories[1].Name)
+
+	var notes []types.Note
+	require.NoError(t, db.Order("front").Find(&notes).Error)
+	require.Len(t, notes, 5)
+	assert.Equal(t, categories[0].ID, *notes[0].CategoryID)
+	assert.Equal(t, categories[0].ID, *notes[1].CategoryID)
+	assert.Equal(t, categories[1].ID, *notes[2].CategoryID)
+	assert.Nil(t, notes[3].CategoryID)
+	assert.Nil(t, notes[4].CategoryID)
+
+	// Running again is a no-op once the legacy column is gone
+	require.NoError(t, migrateNoteCategories(db))
+}
+
+func TestOpenStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "test.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, store.DB().Create(&types.Tag{Name: "kept"}).Error)
+	require.NoError(t, store.Close())
+
+	// Reopening the file keeps the data
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+	var count int64
+	require.NoError(t, store.DB().Model(&types.Tag{}).Count(&count).Error)
+	assert.EqualValues(t, 1, count)
+
+	// In-memory stores do not share their data
+	first, err := OpenMemory()
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := OpenMemory()
+	require.NoError(t, err)
+	defer second.Close()
+	require.NoError(t, first.DB().Create(&types.Tag{Name: "only here"}).Error)
+	require.NoError(t, second.DB().Model(&types.Tag{}).Count(&count).Error)
+	assert.EqualValues(t, 0, count)
+}
+
+func TestOpenEnforcesForeignKeys(t *testing.T) {
+	file, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer file.Close()
+	memory, err := OpenMemory()
+	require.NoError(t, err)
+	defer memory.Close()
+
+	for _, store := range []*Store{file, memory} {
+		err := store.DB().Exec("INSERT INTO notes (front, deck_id) VALUES ('x', 999)").Error
+		assert.ErrorContains(t, err, "FOREIGN KEY constraint failed")
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(filepath.Join(dir, "first.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	tags := NewSQLiteTagStorage(store)
+	require.NoError(t, tags.Create(&types.Tag{Name: "first"}))
+
+	require.NoError(t, store.Reopen(filepath.Join(dir, "second", "second.db")))
+	_, total, err := tags.List(0, "", 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, total, "storages use the reopened database")
+
+	// A database that cannot be opened leaves the current one in use
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.db"), []byte("not a database"), 0644))
+	assert.Error(t, store.Reopen(filepath.Join(dir, "broken.db")))
+	require.NoError(t, store.Reopen(filepath.Join(dir, "first.db")))
+	_, total, err = tags.List(0, "", 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+}
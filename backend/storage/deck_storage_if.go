@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// DeckStorage defines the interface for deck data persistence
+type DeckStorage interface {
+	// All returns every deck ordered by name
+	All() ([]types.Deck, error)
+	// Get returns a deck by id
+	Get(id int) (*types.Deck, error)
+	// Default returns the deck new notes go to when none is given, the oldest
+	// deck. The default deck is created when there are no decks.
+	Default() (*types.Deck, error)
+	// Create creates a new deck
+	Create(deck *types.Deck) error
+	// Update updates an existing deck
+	Update(deck *types.Deck) error
+	// Delete deletes a deck that has no notes
+	Delete(id int) error
+}
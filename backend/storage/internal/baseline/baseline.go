@@ -0,0 +1,193 @@
+// Package baseline holds the models as they were when versioned migrations
+// started, which the baseline migration creates. They are frozen: a change to
+// the schema is a new migration, never an edit here. The names of the models
+// and their fields are kept too, since GORM derives the names of join columns
+// and constraints from them.
+package baseline
+
+import "encoding/json"
+
+type Tag struct {
+	ID   int    `gorm:"primaryKey"`
+	Name string `gorm:"type:varchar(100);uniqueIndex;not null"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+type Category struct {
+	ID       int       `gorm:"primaryKey"`
+	Name     string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_categories_parent_name"`
+	ParentID *int      `gorm:"uniqueIndex:idx_categories_parent_name"`
+	Parent   *Category `gorm:"constraint:OnDelete:RESTRICT"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
+
+type Note struct {
+	ID         int       `gorm:"primaryKey"`
+	GUID       string    `gorm:"type:varchar(64);index"`
+	Title      string    `gorm:"type:varchar(255)"`
+	Front      string    `gorm:"type:text;not null"`
+	Back       string    `gorm:"type:text"`
+	CategoryID *int      `gorm:"index"`
+	Category   *Category `gorm:"constraint:OnDelete:SET NULL"`
+	Tags       []Tag     `gorm:"many2many:note_tags"`
+	Assets     []NoteAsset
+	CreatedAt  int64 `gorm:"autoCreateTime"`
+	UpdatedAt  int64 `gorm:"autoUpdateTime"`
+}
+
+func (Note) TableName() string {
+	return "notes"
+}
+
+type ReviewState struct {
+	NoteID     int `gorm:"primaryKey;autoIncrement:false"`
+	Ease       float64
+	Interval   int
+	Due        int64 `gorm:"index"`
+	Reps       int
+	Lapses     int
+	Stability  float64
+	Difficulty float64
+	LastReview int64
+}
+
+func (ReviewState) TableName() string {
+	return "review_states"
+}
+
+type ReviewLog struct {
+	ID         int `gorm:"primaryKey"`
+	NoteID     int `gorm:"index;not null"`
+	Grade      int
+	Algorithm  string `gorm:"type:varchar(20)"`
+	Interval   int
+	Ease       float64
+	Stability  float64
+	Difficulty float64
+	ReviewedAt int64 `gorm:"index"`
+}
+
+func (ReviewLog) TableName() string {
+	return "review_logs"
+}
+
+type PracticeSession struct {
+	ID              int    `gorm:"primaryKey"`
+	Filter          string `gorm:"type:text"`
+	NoteIDs         string `gorm:"type:text"`
+	QuestionTimeout int
+	AnswerTimeout   int
+	Position        int
+	Total           int
+	Correct         int
+	Incorrect       int
+	Skipped         int
+	TimedOut        int
+	StartedAt       int64 `gorm:"autoCreateTime"`
+	FinishedAt      int64
+}
+
+func (PracticeSession) TableName() string {
+	return "practice_sessions"
+}
+
+type PracticeResult struct {
+	ID        int    `gorm:"primaryKey"`
+	SessionID int    `gorm:"index;not null"`
+	NoteID    int    `gorm:"index;not null"`
+	Outcome   string `gorm:"type:varchar(20)"`
+	ElapsedMs int
+	CreatedAt int64 `gorm:"autoCreateTime"`
+}
+
+func (PracticeResult) TableName() string {
+	return "practice_results"
+}
+
+type Asset struct {
+	ID        int    `gorm:"primaryKey"`
+	Hash      string `gorm:"type:char(64);uniqueIndex"`
+	Name      string `gorm:"type:varchar(255)"`
+	MimeType  string `gorm:"type:varchar(100)"`
+	Size      int64
+	CreatedAt int64 `gorm:"autoCreateTime"`
+}
+
+func (Asset) TableName() string {
+	return "assets"
+}
+
+type NoteAsset struct {
+	NoteID    int    `gorm:"primaryKey"`
+	Role      string `gorm:"primaryKey;type:varchar(20)"`
+	AssetID   int    `gorm:"primaryKey;index"`
+	Asset     Asset
+	CreatedAt int64 `gorm:"autoCreateTime"`
+}
+
+func (NoteAsset) TableName() string {
+	return "note_assets"
+}
+
+type Translation struct {
+	ID        int    `gorm:"primaryKey"`
+	Source    string `gorm:"type:varchar(35);uniqueIndex:idx_translations_key"`
+	Target    string `gorm:"type:varchar(35);uniqueIndex:idx_translations_key"`
+	TextHash  string `gorm:"type:char(64);uniqueIndex:idx_translations_key"`
+	Text      string `gorm:"type:text"`
+	Result    string `gorm:"type:text"`
+	Provider  string `gorm:"type:varchar(50)"`
+	CreatedAt int64  `gorm:"autoCreateTime"`
+}
+
+func (Translation) TableName() string {
+	return "translations"
+}
+
+type SpeechCache struct {
+	Key       string `gorm:"primaryKey;type:char(64)"`
+	AssetID   int    `gorm:"index;not null"`
+	Asset     Asset
+	CreatedAt int64 `gorm:"autoCreateTime"`
+}
+
+func (SpeechCache) TableName() string {
+	return "speech_cache"
+}
+
+type Job struct {
+	ID         int             `gorm:"primaryKey"`
+	Kind       string          `gorm:"type:varchar(50);not null"`
+	Status     string          `gorm:"type:varchar(20);index;not null"`
+	Params     json.RawMessage `gorm:"type:text"`
+	Progress   int
+	Total      int
+	Result     json.RawMessage `gorm:"type:text"`
+	Error      string          `gorm:"type:text"`
+	CreatedAt  int64           `gorm:"autoCreateTime"`
+	UpdatedAt  int64           `gorm:"autoUpdateTime"`
+	StartedAt  int64
+	FinishedAt int64
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Models returns the models of the baseline in the order they are migrated
+func Models() []interface{} {
+	return []interface{}{
+		&Tag{}, &Category{}, &Note{},
+		&ReviewState{}, &ReviewLog{},
+		&PracticeSession{}, &PracticeResult{},
+		&Asset{}, &NoteAsset{},
+		&Translation{}, &SpeechCache{},
+		&Job{},
+	}
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// JobStorage defines the interface for persisted background jobs
+type JobStorage interface {
+	// Create stores a new job
+	Create(job *types.Job) error
+	// Get returns a job by id
+	Get(id int) (*types.Job, error)
+	// List returns the most recent jobs, only those in status unless it is empty
+	List(status string, limit int) ([]types.Job, error)
+	// Claim marks the oldest queued job as running and returns it, or nil if none is queued
+	Claim() (*types.Job, error)
+	// Update saves the state, progress and outcome of a job
+	Update(job *types.Job) error
+	// CancelQueued marks a job cancelled if it is still queued, reporting whether it was
+	CancelQueued(id int) (bool, error)
+	// Requeue puts jobs left running by a previous run back in the queue
+	Requeue() (int64, error)
+	// Interrupt marks the jobs of kinds left running by a previous run failed with message
+	Interrupt(kinds []string, message string) (int64, error)
+	// DeleteFinished deletes the jobs in a final state
+	DeleteFinished() (int64, error)
+}
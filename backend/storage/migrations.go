@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"langlearner1/backend/storage/internal/baseline"
+	"langlearner1/backend/types"
+)
+
+// migration is one versioned schema change, applied in a transaction together
+// with its schema_migrations row
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// migrations are applied in order to bring a database up to date. Released
+// migrations are never edited or reordered, schema changes are appended.
+//
+// Databases created by earlier builds may already have the columns of later
+// migrations, so migrations check the schema before they change it.
+var migrations = []migration{
+	{1, "baseline", migrateBaseline},
+	{2, "note categories", migrateNoteCategories},
+	{3, "decks", migrateDecks},
+	{4, "note readings", migrateNoteReadings},
+	{5, "review answers", migrateReviewAnswers},
+}
+
+// schemaMigration records a migration applied to the database
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"type:varchar(100);not null"`
+	AppliedAt int64  `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for schemaMigration model
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// SchemaVersion returns the latest version the migrations bring a database to
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// migrate applies the pending migrations to db. The database file at path is
+// backed up first unless it is new, a database with migrations this version of
+// the app does not know is refused.
+//
+// Foreign keys are switched off while migrating, as SQLite needs for the
+// table rebuilds that change constraints, and checked once all migrations ran.
+func migrate(db *gorm.DB, path string) error {
+	// The pragma applies to one connection, so every migration runs on the same one
+	return db.Connection(func(conn *gorm.DB) error {
+		// A new session lets each statement start fresh on the pinned connection
+		conn = conn.Session(&gorm.Session{})
+		var enabled int
+		if err := conn.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+			return err
+		}
+		if enabled == 1 {
+			if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+				return err
+			}
+			defer conn.Exec("PRAGMA foreign_keys = ON")
+		}
+		if err := migrateConn(conn, path); err != nil {
+			return err
+		}
+		return checkForeignKeys(conn)
+	})
+}
+
+// migrateConn applies the pending migrations on a single connection
+func migrateConn(db *gorm.DB, path string) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+	var current int
+	err := db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&current).Error
+	if err != nil {
+		return err
+	}
+	if current > SchemaVersion() {
+		return fmt.Errorf("%w: schema version %d, this app supports up to %d", types.ErrSchemaTooNew, current, SchemaVersion())
+	}
+	if current == SchemaVersion() {
+		return nil
+	}
+
+	if err := backupDB(db, path, current); err != nil {
+		return fmt.Errorf("backup before migrating: %w", err)
+	}
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkForeignKeys fails when a row references a row that does not exist
+func checkForeignKeys(db *gorm.DB) error {
+	var violations []struct {
+		Table  string
+		Rowid  int64
+		Parent string
+	}
+	if err := db.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		v := violations[0]
+		return fmt.Errorf("migrated database has %d rows with missing references, such as row %d of %s referencing %s",
+			len(violations), v.Rowid, v.Table, v.Parent)
+	}
+	return nil
+}
+
+// backupDB copies the database at path next to it before it is migrated from
+// version. New databases, which have no tables besides schema_migrations, are not copied.
+func backupDB(db *gorm.DB, path string, version int) error {
+	if path == "" || path == ":memory:" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	var tables int64
+	err := db.Table("sqlite_master").
+		Where("type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> ?", schemaMigration{}.TableName()).
+		Count(&tables).Error
+	if err != nil || tables == 0 {
+		return err
+	}
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, version, time.Now().Format("20060102-150405"))
+	// VACUUM INTO writes a consistent copy, including changes still in the WAL
+	return db.Exec("VACUUM INTO ?", backup).Error
+}
+
+// migrateBaseline creates the tables of the schema that was maintained with
+// AutoMigrate before versioned migrations, and adds what older databases miss.
+// It migrates the frozen baseline models, the current models may have changed since.
+func migrateBaseline(tx *gorm.DB) error {
+	return tx.AutoMigrate(baseline.Models()...)
+}
+
+// migrateNoteCategories converts the legacy notes.category varchar column into
+// category rows referenced through notes.category_id, then drops the old column
+func migrateNoteCategories(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("notes", "category") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var names []string
+		err := tx.Table("notes").
+			Distinct("TRIM(category)").
+			Where("category IS NOT NULL AND TRIM(category) <> ''").
+			Pluck("TRIM(category)", &names).Error
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			category := types.Category{}
+			err := tx.Where("name = ? AND parent_id IS NULL", name).
+				FirstOrCreate(&category, types.Category{Name: name}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Table("notes").
+				Where("TRIM(category) = ? AND category_id IS NULL", name).
+				Update("category_id", category.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Exec("ALTER TABLE notes DROP COLUMN category").Error
+	})
+}
+
+// migrateDecks creates the decks table with the default deck and moves the
+// notes that have no deck into it. Databases created by earlier builds may
+// already have notes.deck_id.
+func migrateDecks(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&types.Deck{}); err != nil {
+		return err
+	}
+	deck, err := defaultDeck(tx)
+	if err != nil {
+		return err
+	}
+	if !tx.Migrator().HasColumn("notes", "deck_id") {
+		// SQLite adds a NOT NULL column with a default and a foreign key in place,
+		// a table rebuild to add the constraint could drop columns of older tables
+		err := tx.Exec(fmt.Sprintf("ALTER TABLE notes ADD COLUMN deck_id integer NOT NULL DEFAULT %d "+
+			"CONSTRAINT fk_notes_deck REFERENCES decks(id) ON DELETE RESTRICT", deck.ID)).Error
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_notes_deck_id ON notes(deck_id)").Error; err != nil {
+		return err
+	}
+	return tx.Table("notes").Where("deck_id NOT IN (SELECT id FROM decks)").Update("deck_id", deck.ID).Error
+}
+
+// migrateNoteReadings adds the columns holding the furigana and romaji of notes.
+// Existing notes get their readings from the fill readings job.
+func migrateNoteReadings(tx *gorm.DB) error {
+	return addMissingColumns(tx, &types.Note{}, "Furigana", "Romaji")
+}
+
+// migrateReviewAnswers adds the columns holding the typed answer of a review and its score
+func migrateReviewAnswers(tx *gorm.DB) error {
+	return addMissingColumns(tx, &types.ReviewLog{}, "Answer", "Score")
+}
+
+// addMissingColumns adds the columns of the model fields that its table does not have yet
+func addMissingColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
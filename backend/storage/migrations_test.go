@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"langlearner1/backend/types"
+)
+
+func openTestDB(t *testing.T, path string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(path+"?_foreign_keys=on"), &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func appliedVersions(t *testing.T, db *gorm.DB) []int {
+	var versions []int
+	require.NoError(t, db.Model(&schemaMigration{}).Order("version").Pluck("version", &versions).Error)
+	return versions
+}
+
+func backups(t *testing.T, path string) []string {
+	matches, err := filepath.Glob(path + ".v*.bak")
+	require.NoError(t, err)
+	return matches
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.db")
+	db := openTestDB(t, path)
+
+	require.NoError(t, migrate(db, path))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, appliedVersions(t, db))
+	assert.True(t, db.Migrator().HasTable(&types.Note{}))
+	assert.True(t, db.Migrator().HasTable(&types.Job{}))
+	var decks []types.Deck
+	require.NoError(t, db.Find(&decks).Error)
+	require.Len(t, decks, 1, "a new database starts with the default deck")
+	assert.Equal(t, types.DefaultDeckName, decks[0].Name)
+	assert.Empty(t, backups(t, path), "a new database is not backed up")
+
+	// Running again applies nothing
+	require.NoError(t, migrate(db, path))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, appliedVersions(t, db))
+	assert.Equal(t, 5, SchemaVersion())
+}
+
+func TestMigrateDecksAfterBaseline(t *testing.T) {
+	previous := migrations
+	t.Cleanup(func() { migrations = previous })
+	path := filepath.Join(t.TempDir(), "v1.db")
+	db := openTestDB(t, path)
+
+	// A database of the first versioned release has no decks
+	migrations = previous[:1]
+	require.NoError(t, migrate(db, path))
+	assert.False(t, db.Migrator().HasTable("decks"))
+	assert.False(t, db.Migrator().HasColumn("notes", "deck_id"))
+	require.NoError(t, db.Exec(`INSERT INTO notes (front) VALUES ('a')`).Error)
+
+	migrations = previous[:3]
+	require.NoError(t, migrate(db, path))
+	assert.Equal(t, []int{1, 2, 3}, appliedVersions(t, db))
+	assert.True(t, db.Migrator().HasConstraint(&types.Note{}, "Deck"))
+	var note types.Note
+	require.NoError(t, db.Preload("Deck").Where("front = ?", "a").Take(&note).Error)
+	require.NotNil(t, note.Deck)
+	assert.Equal(t, types.DefaultDeckName, note.Deck.Name)
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db := openTestDB(t, path)
+
+	// Database of a version that only ran AutoMigrate on tags and notes
+	require.NoError(t, db.Exec(`CREATE TABLE tags (id integer PRIMARY KEY AUTOINCREMENT, name varchar(100) NOT NULL UNIQUE)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE notes (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		front text NOT NULL,
+		back text,
+		category varchar(100),
+		created_at integer,
+		updated_at integer
+	)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO notes (front, category) VALUES ('a', 'Grammar'), ('b', NULL)`).Error)
+
+	require.NoError(t, migrate(db, path))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, appliedVersions(t, db))
+	assert.False(t, db.Migrator().HasColumn("notes", "category"))
+	var note types.Note
+	require.NoError(t, db.Where("front = ?", "a").Take(&note).Error)
+	require.NotNil(t, note.CategoryID)
+	var deck types.Deck
+	require.NoError(t, db.Take(&deck, note.DeckID).Error, "existing notes are put into the default deck")
+	assert.Equal(t, types.DefaultDeckName, deck.Name)
+
+	// The backup holds the database as it was before migrating
+	files := backups(t, path)
+	require.Len(t, files, 1)
+	assert.Contains(t, files[0], "legacy.db.v0-")
+	backup := openTestDB(t, files[0])
+	assert.True(t, backup.Migrator().HasColumn("notes", "category"))
+	assert.False(t, backup.Migrator().HasTable("categories"))
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "newer.db")
+	db := openTestDB(t, path)
+	require.NoError(t, migrate(db, path))
+	require.NoError(t, db.Create(&schemaMigration{Version: SchemaVersion() + 1, Name: "from the future"}).Error)
+
+	err := migrate(db, path)
+	assert.ErrorIs(t, err, types.ErrSchemaTooNew)
+}
+
+func TestMigrateRollsBackFailedMigration(t *testing.T) {
+	previous := migrations
+	t.Cleanup(func() { migrations = previous })
+	migrations = append(append([]migration{}, previous...), migration{
+		Version: SchemaVersion() + 1,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE half_done (id integer)").Error; err != nil {
+				return err
+			}
+			return errors.New("broken")
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "broken.db")
+	db := openTestDB(t, path)
+	err := migrate(db, path)
+	assert.ErrorContains(t, err, "broken")
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, appliedVersions(t, db))
+	assert.False(t, db.Migrator().HasTable("half_done"))
+}
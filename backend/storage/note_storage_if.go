@@ -6,12 +6,40 @@ import (
 
 // NoteStorageIf defines the interface for note data persistence
 type NoteStorageIf interface {
-	// List returns notes with optional id and keyword filters, supports pagination
-	List(id int, keyword string, offset int, limit int) ([]types.Note, error)
-	// Create creates a new note
+	// List returns one page of notes matching the filter, and the total number of matches
+	List(filter types.NoteFilter, offset int, limit int) ([]types.Note, int64, error)
+	// ListAfter returns up to limit notes matching the filter that come after cursor
+	// in the list order, a zero cursor starts from the beginning
+	ListAfter(filter types.NoteFilter, cursor types.NoteCursor, limit int) ([]types.Note, error)
+	// Get returns a note by id
+	Get(id int) (*types.Note, error)
+	// Create creates a new note, a note without a deck goes to the default deck
 	Create(note *types.Note) error
-	// Update updates an existing note
+	// Update updates an existing note, a note without a deck stays in its deck
 	Update(note *types.Note) error
+	// UpdateReadings saves the furigana and romaji of a note, it keeps the update
+	// time since the text of the note did not change
+	UpdateReadings(note *types.Note) error
 	// Delete deletes a note
 	Delete(id int) error
+	// AddTags links the given tags to a note, ignoring links that already exist
+	AddTags(noteID int, tagIDs []int) error
+	// RemoveTags unlinks the given tags from a note
+	RemoveTags(noteID int, tagIDs []int) error
+	// ReplaceTags makes the given tags the only tags of a note
+	ReplaceTags(noteID int, tagIDs []int) error
+	// Search returns notes matching keyword ordered by relevance, with highlighted snippets
+	Search(keyword string, limit int) ([]types.NoteSearchResult, error)
+	// RebuildSearchIndex regenerates the full-text index from the notes table
+	RebuildSearchIndex() error
+	// Import writes notes in a single transaction. Tags are referenced by name and
+	// created when missing, notes with a GUID that already exists are updated and
+	// keep their deck, their title unless the import has one and the tags they
+	// were given since. New notes without a DeckID go to the default deck.
+	// A note without a CategoryID may reference its category by name through
+	// Category and its Parent chain, missing categories are created.
+	// In dry-run mode the transaction is rolled back and only the stats are returned.
+	Import(notes []*types.Note, dryRun bool) (types.ImportStats, error)
+	// DeckIDs returns the deck of the stored notes with one of the GUIDs, by GUID
+	DeckIDs(guids []string) (map[string]int, error)
 }
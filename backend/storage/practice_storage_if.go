@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// PracticeStorage defines the interface for practice session persistence
+type PracticeStorage interface {
+	// SelectNotes returns the ids of the notes matching a practice filter
+	SelectNotes(filter types.PracticeFilter, now int64) ([]int, error)
+	// CreateSession creates a new session
+	CreateSession(session *types.PracticeSession) error
+	// GetSession returns a session by id
+	GetSession(id int) (*types.PracticeSession, error)
+	// SaveResult stores a card outcome together with the updated session summary
+	SaveResult(session *types.PracticeSession, result *types.PracticeResult) error
+	// UpdateSession stores the summary fields of a session
+	UpdateSession(session *types.PracticeSession) error
+	// ListSessions returns one page of sessions ordered from the most recent, and the total number of sessions
+	ListSessions(offset int, limit int) ([]types.PracticeSession, int64, error)
+}
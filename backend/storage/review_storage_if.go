@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// ReviewStorage defines the interface for review scheduling persistence
+type ReviewStorage interface {
+	// DueNotes returns up to limit notes due at or before now, including never reviewed notes
+	DueNotes(now int64, limit int) ([]types.DueNote, error)
+	// GetState returns the scheduling state of a note, nil if the note was never reviewed
+	GetState(noteID int) (*types.ReviewState, error)
+	// SaveReview stores the new state of a note together with the review log entry
+	SaveReview(state *types.ReviewState, log *types.ReviewLog) error
+	// History returns the most recent review logs of a note
+	History(noteID int, limit int) ([]types.ReviewLog, error)
+	// Stats counts the notes, the new and due notes at now and the reviews since since
+	Stats(now int64, since int64) (*types.ReviewStats, error)
+}
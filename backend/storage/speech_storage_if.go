@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// SpeechStorage defines the interface for the generated speech cache
+type SpeechStorage interface {
+	// Get returns the asset cached under key, or nil if there is none or its asset was removed
+	Get(key string) (*types.Asset, error)
+	// Save caches an asset under key
+	Save(key string, assetID int) error
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+)
+
+// SQLiteAssetStorage implements AssetStorage interface with SQLite storage
+type SQLiteAssetStorage struct {
+	store *Store
+}
+
+// NewSQLiteAssetStorage creates a new instance of SQLiteAssetStorage
+func NewSQLiteAssetStorage(store *Store) AssetStorage {
+	return &SQLiteAssetStorage{store: store}
+}
+
+// FindByHash returns the asset with the given content hash, or nil if there is none
+func (s *SQLiteAssetStorage) FindByHash(hash string) (*types.Asset, error) {
+	asset := &types.Asset{}
+	result := s.store.DB().Where("hash = ?", hash).Limit(1).Find(asset)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return asset, nil
+}
+
+// Create creates a new asset
+func (s *SQLiteAssetStorage) Create(asset *types.Asset) error {
+	if err := s.store.DB().Create(asset).Error; err != nil {
+		return err
+	}
+	asset.URL = types.AssetURLPrefix + asset.Hash
+	return nil
+}
+
+// Attach links an asset to a note, replacing the asset of an audio role
+func (s *SQLiteAssetStorage) Attach(link *types.NoteAsset) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.Note{}).Where("id = ?", link.NoteID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return types.ErrNoteNotFound
+		}
+		if err := tx.First(&link.Asset, link.AssetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return types.ErrAssetNotFound
+			}
+			return err
+		}
+
+		if link.Role != types.AssetRoleImage {
+			err := tx.Where("note_id = ? AND role = ?", link.NoteID, link.Role).Delete(&types.NoteAsset{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		// Attaching the same image twice keeps the first link
+		return tx.Omit("Asset").
+			Where(types.NoteAsset{NoteID: link.NoteID, Role: link.Role, AssetID: link.AssetID}).
+			FirstOrCreate(link).Error
+	})
+}
+
+// Detach unlinks an asset from a note
+func (s *SQLiteAssetStorage) Detach(noteID int, assetID int, role string) error {
+	result := s.store.DB().Where("note_id = ? AND asset_id = ? AND role = ?", noteID, assetID, role).Delete(&types.NoteAsset{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return types.ErrAssetNotFound
+	}
+	return nil
+}
+
+// NoteAssets returns the assets linked to a note, ordered by role and then by when they were added
+func (s *SQLiteAssetStorage) NoteAssets(noteID int) ([]types.NoteAsset, error) {
+	links := []types.NoteAsset{}
+	err := s.store.DB().Preload("Asset").Where("note_id = ?", noteID).Order("role, created_at, asset_id").Find(&links).Error
+	return links, err
+}
+
+// Orphans returns the assets that are not linked to any note
+func (s *SQLiteAssetStorage) Orphans() ([]types.Asset, error) {
+	assets := []types.Asset{}
+	err := s.store.DB().Where("id NOT IN (?)", s.store.DB().Table("note_assets").Select("asset_id")).Order("id").Find(&assets).Error
+	return assets, err
+}
+
+// Delete deletes assets that are still not linked to any note, and returns the deleted ones.
+// Linked assets are skipped so a concurrent attach never loses its file.
+func (s *SQLiteAssetStorage) Delete(ids []int) ([]types.Asset, error) {
+	deleted := []types.Asset{}
+	if len(ids) == 0 {
+		return deleted, nil
+	}
+	err := s.store.DB().Transaction(func(tx *gorm.DB) error {
+		linked := tx.Table("note_assets").Select("asset_id")
+		if err := tx.Where("id IN ? AND id NOT IN (?)", ids, linked).Find(&deleted).Error; err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return nil
+		}
+		deletedIDs := make([]int, 0, len(deleted))
+		for _, asset := range deleted {
+			deletedIDs = append(deletedIDs, asset.ID)
+		}
+		if err := tx.Exec("DELETE FROM speech_cache WHERE asset_id IN ?", deletedIDs).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&types.Asset{}, deletedIDs).Error
+	})
+	return deleted, err
+}
+
+// Hashes returns the content hashes of all assets
+func (s *SQLiteAssetStorage) Hashes() ([]string, error) {
+	hashes := []string{}
+	err := s.store.DB().Model(&types.Asset{}).Pluck("hash", &hashes).Error
+	return hashes, err
+}
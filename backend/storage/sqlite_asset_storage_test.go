@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestAssetStorage(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteAssetStorage(store)
+	notes := NewSQLiteNoteStorage(store)
+
+	note := &types.Note{Front: "いぬ"}
+	require.NoError(t, notes.Create(note))
+	other := &types.Note{Front: "ねこ"}
+	require.NoError(t, notes.Create(other))
+
+	newAsset := func(hash string, mimeType string) *types.Asset {
+		asset := &types.Asset{Hash: hash, Name: hash + ".bin", MimeType: mimeType}
+		require.NoError(t, st.Create(asset))
+		return asset
+	}
+	audio1 := newAsset("a1", "audio/mpeg")
+	audio2 := newAsset("a2", "audio/mpeg")
+	image := newAsset("i1", "image/png")
+	assert.Equal(t, types.AssetURLPrefix+"a1", audio1.URL)
+
+	found, err := st.FindByHash("a1")
+	require.NoError(t, err)
+	assert.Equal(t, audio1.ID, found.ID)
+	assert.Equal(t, types.AssetURLPrefix+"a1", found.URL)
+	found, err = st.FindByHash("missing")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	attach := func(noteID int, asset *types.Asset, role string) error {
+		return st.Attach(&types.NoteAsset{NoteID: noteID, AssetID: asset.ID, Role: role})
+	}
+	require.NoError(t, attach(note.ID, audio1, types.AssetRoleFrontAudio))
+	// A second front audio replaces the first, images accumulate and are not duplicated
+	require.NoError(t, attach(note.ID, audio2, types.AssetRoleFrontAudio))
+	require.NoError(t, attach(note.ID, image, types.AssetRoleImage))
+	require.NoError(t, attach(note.ID, image, types.AssetRoleImage))
+	require.NoError(t, attach(note.ID, audio1, types.AssetRoleBackAudio))
+	require.NoError(t, attach(other.ID, image, types.AssetRoleImage))
+	assert.Equal(t, types.ErrNoteNotFound, attach(999, image, types.AssetRoleImage))
+	assert.Equal(t, types.ErrAssetNotFound, attach(note.ID, &types.Asset{ID: 999}, types.AssetRoleImage))
+
+	links, err := st.NoteAssets(note.ID)
+	require.NoError(t, err)
+	require.Len(t, links, 3)
+	assert.Equal(t, types.AssetRoleBackAudio, links[0].Role)
+	assert.Equal(t, audio1.ID, links[0].Asset.ID)
+	assert.Equal(t, types.AssetRoleFrontAudio, links[1].Role)
+	assert.Equal(t, audio2.ID, links[1].AssetID)
+	assert.Equal(t, types.AssetRoleImage, links[2].Role)
+
+	// Notes are loaded with their assets
+	loaded, err := notes.Get(note.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Assets, 3)
+	assert.Equal(t, types.AssetURLPrefix+"a1", loaded.Assets[0].Asset.URL)
+
+	orphans, err := st.Orphans()
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	require.NoError(t, st.Detach(note.ID, audio1.ID, types.AssetRoleBackAudio))
+	assert.Equal(t, types.ErrAssetNotFound, st.Detach(note.ID, audio1.ID, types.AssetRoleBackAudio))
+	require.NoError(t, notes.Delete(note.ID))
+
+	orphans, err = st.Orphans()
+	require.NoError(t, err)
+	require.Len(t, orphans, 2)
+	assert.Equal(t, []int{audio1.ID, audio2.ID}, []int{orphans[0].ID, orphans[1].ID})
+
+	// The image is still used by the other note and survives
+	deleted, err := st.Delete([]int{audio1.ID, audio2.ID, image.ID})
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+	hashes, err := st.Hashes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i1"}, hashes)
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"errors"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+)
+
+// SQLiteCategoryStorage implements CategoryStorage interface with SQLite storage
+type SQLiteCategoryStorage struct {
+	store *Store
+}
+
+// NewSQLiteCategoryStorage creates a new instance of SQLiteCategoryStorage
+func NewSQLiteCategoryStorage(store *Store) CategoryStorage {
+	return &SQLiteCategoryStorage{store: store}
+}
+
+// List returns one page of categories with optional id and keyword filters, and the total number of matches
+func (s *SQLiteCategoryStorage) List(id int, keyword string, offset int, limit int) ([]types.Category, int64, error) {
+	var categories []types.Category
+	var total int64
+	db := s.store.DB().Model(&types.Category{})
+	if id > 0 {
+		db = db.Where("id = ?", id)
+	}
+	if keyword != "" {
+		db = db.Where("name LIKE ?", "%"+keyword+"%")
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	result := db.Order("name, id").Offset(offset).Limit(limit).Find(&categories)
+	return categories, total, result.Error
+}
+
+// All returns every category ordered by name
+func (s *SQLiteCategoryStorage) All() ([]types.Category, error) {
+	var categories []types.Category
+	result := s.store.DB().Order("name").Find(&categories)
+	return categories, result.Error
+}
+
+// Create creates a new category
+func (s *SQLiteCategoryStorage) Create(category *types.Category) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryParent(tx, category); err != nil {
+			return err
+		}
+		return tx.Create(category).Error
+	})
+}
+
+// Update updates an existing category
+func (s *SQLiteCategoryStorage) Update(category *types.Category) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryParent(tx, category); err != nil {
+			return err
+		}
+		result := tx.Model(&types.Category{ID: category.ID}).
+			Select("name", "parent_id").
+			Updates(category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrCategoryNotFound
+		}
+		return nil
+	})
+}
+
+// Delete deletes a category that has neither subcategories nor notes
+func (s *SQLiteCategoryStorage) Delete(id int) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		var children, notes int64
+		if err := tx.Model(&types.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&types.Note{}).Where("category_id = ?", id).Count(&notes).Error; err != nil {
+			return err
+		}
+		if children > 0 || notes > 0 {
+			return types.ErrCategoryInUse
+		}
+		result := tx.Delete(&types.Category{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrCategoryNotFound
+		}
+		return nil
+	})
+}
+
+// checkCategoryParent validates the parent of a category and the uniqueness of its name among siblings
+func checkCategoryParent(tx *gorm.DB, category *types.Category) error {
+	if category.ParentID != nil {
+		// Walk up from the new parent, reaching the category itself means a cycle
+		parentID := *category.ParentID
+		for parentID != 0 {
+			if category.ID != 0 && parentID == category.ID {
+				return types.ErrCategoryCycle
+			}
+			parent := &types.Category{}
+			if err := tx.Take(parent, parentID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return types.ErrCategoryNotFound
+				}
+				return err
+			}
+			if parent.ParentID == nil {
+				break
+			}
+			parentID = *parent.ParentID
+		}
+	}
+
+	// SQLite treats NULL parents as distinct, so siblings are checked explicitly
+	db := tx.Model(&types.Category{}).Where("name = ? AND id <> ?", category.Name, category.ID)
+	if category.ParentID == nil {
+		db = db.Where("parent_id IS NULL")
+	} else {
+		db = db.Where("parent_id = ?", *category.ParentID)
+	}
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return types.ErrCategoryExists
+	}
+	return nil
+}
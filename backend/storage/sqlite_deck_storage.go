@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"errors"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+)
+
+// SQLiteDeckStorage implements DeckStorage interface with SQLite storage
+type SQLiteDeckStorage struct {
+	store *Store
+}
+
+// NewSQLiteDeckStorage creates a new instance of SQLiteDeckStorage
+func NewSQLiteDeckStorage(store *Store) DeckStorage {
+	return &SQLiteDeckStorage{store: store}
+}
+
+// All returns every deck ordered by name
+func (s *SQLiteDeckStorage) All() ([]types.Deck, error) {
+	decks := []types.Deck{}
+	result := s.store.DB().Order("name, id").Find(&decks)
+	return decks, result.Error
+}
+
+// Get returns a deck by id
+func (s *SQLiteDeckStorage) Get(id int) (*types.Deck, error) {
+	deck := &types.Deck{}
+	err := s.store.DB().Take(deck, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, types.ErrDeckNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// Default returns the oldest deck, creating the default deck when there are none
+func (s *SQLiteDeckStorage) Default() (*types.Deck, error) {
+	return defaultDeck(s.store.DB())
+}
+
+// Create creates a new deck
+func (s *SQLiteDeckStorage) Create(deck *types.Deck) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		if err := checkDeckName(tx, deck); err != nil {
+			return err
+		}
+		return tx.Create(deck).Error
+	})
+}
+
+// Update updates an existing deck
+func (s *SQLiteDeckStorage) Update(deck *types.Deck) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		if err := checkDeckName(tx, deck); err != nil {
+			return err
+		}
+		result := tx.Model(&types.Deck{ID: deck.ID}).
+			Select("name", "source", "target", "updated_at").
+			Updates(deck)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrDeckNotFound
+		}
+		return tx.Take(deck, deck.ID).Error
+	})
+}
+
+// Delete deletes a deck that has no notes
+func (s *SQLiteDeckStorage) Delete(id int) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		var notes int64
+		if err := tx.Model(&types.Note{}).Where("deck_id = ?", id).Count(&notes).Error; err != nil {
+			return err
+		}
+		if notes > 0 {
+			return types.ErrDeckInUse
+		}
+		result := tx.Delete(&types.Deck{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrDeckNotFound
+		}
+		return nil
+	})
+}
+
+// checkDeckName makes sure no other deck has the name of deck
+func checkDeckName(tx *gorm.DB, deck *types.Deck) error {
+	var count int64
+	err := tx.Model(&types.Deck{}).Where("name = ? AND id <> ?", deck.Name, deck.ID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return types.ErrDeckExists
+	}
+	return nil
+}
+
+// defaultDeck returns the oldest deck, creating the default deck when there are none
+func defaultDeck(tx *gorm.DB) (*types.Deck, error) {
+	deck := &types.Deck{}
+	err := tx.Order("id").
+		Attrs(types.Deck{Name: types.DefaultDeckName, Source: types.DefaultDeckSource, Target: types.DefaultDeckTarget}).
+		FirstOrCreate(deck).Error
+	if err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// checkNoteDeck makes sure the deck a note points at exists, a note without
+// a deck is put into the default deck
+func checkNoteDeck(tx *gorm.DB, note *types.Note) error {
+	if note.DeckID == 0 {
+		deck, err := defaultDeck(tx)
+		if err != nil {
+			return err
+		}
+		note.DeckID = deck.ID
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&types.Deck{}).Where("id = ?", note.DeckID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return types.ErrDeckNotFound
+	}
+	return nil
+}
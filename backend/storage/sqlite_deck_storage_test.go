@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestDeckStorage(t *testing.T) {
+	store := newTestStore(t)
+	decks := NewSQLiteDeckStorage(store)
+	notes := NewSQLiteNoteStorage(store)
+
+	// The migrations create the default deck
+	def, err := decks.Default()
+	require.NoError(t, err)
+	assert.Equal(t, types.DefaultDeckName, def.Name)
+
+	korean := &types.Deck{Name: "Korean", Source: "ko", Target: "en"}
+	require.NoError(t, decks.Create(korean))
+	assert.ErrorIs(t, decks.Create(&types.Deck{Name: "Korean", Source: "ko", Target: "ja"}), types.ErrDeckExists)
+
+	korean.Target = "zh-CN"
+	require.NoError(t, decks.Update(korean))
+	got, err := decks.Get(korean.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "zh-CN", got.Target)
+	assert.ErrorIs(t, decks.Update(&types.Deck{ID: 99, Name: "Missing"}), types.ErrDeckNotFound)
+	_, err = decks.Get(99)
+	assert.ErrorIs(t, err, types.ErrDeckNotFound)
+
+	all, err := decks.All()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Default", all[0].Name)
+	assert.Equal(t, "Korean", all[1].Name)
+
+	// Notes without a deck go to the default deck and keep their deck on update
+	note := &types.Note{Front: "犬"}
+	require.NoError(t, notes.Create(note))
+	assert.Equal(t, def.ID, note.DeckID)
+	require.NotNil(t, note.Deck)
+	assert.Equal(t, "ja", note.Deck.Source)
+	other := &types.Note{Front: "개", DeckID: korean.ID}
+	require.NoError(t, notes.Create(other))
+	assert.ErrorIs(t, notes.Create(&types.Note{Front: "x", DeckID: 99}), types.ErrDeckNotFound)
+
+	other.DeckID = 0
+	other.Back = "dog"
+	require.NoError(t, notes.Update(other))
+	assert.Equal(t, korean.ID, other.DeckID)
+
+	list, total, err := notes.List(types.NoteFilter{DeckID: korean.ID}, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, "개", list[0].Front)
+
+	assert.ErrorIs(t, decks.Delete(korean.ID), types.ErrDeckInUse)
+	require.NoError(t, notes.Delete(other.ID))
+	require.NoError(t, decks.Delete(korean.ID))
+	assert.ErrorIs(t, decks.Delete(korean.ID), types.ErrDeckNotFound)
+
+	// Imported notes go to the default deck unless they already exist
+	imported := []*types.Note{{GUID: "a", Front: "猫"}}
+	_, err = notes.Import(imported, false)
+	require.NoError(t, err)
+	assert.Equal(t, def.ID, imported[0].DeckID)
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+)
+
+// SQLiteJobStorage implements JobStorage interface with SQLite storage
+type SQLiteJobStorage struct {
+	store *Store
+}
+
+// NewSQLiteJobStorage creates a new instance of SQLiteJobStorage
+func NewSQLiteJobStorage(store *Store) JobStorage {
+	return &SQLiteJobStorage{store: store}
+}
+
+// Create stores a new job
+func (s *SQLiteJobStorage) Create(job *types.Job) error {
+	return s.store.DB().Create(job).Error
+}
+
+// Get returns a job by id
+func (s *SQLiteJobStorage) Get(id int) (*types.Job, error) {
+	job := &types.Job{}
+	err := s.store.DB().Take(job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, types.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns the most recent jobs, only those in status unless it is empty
+func (s *SQLiteJobStorage) List(status string, limit int) ([]types.Job, error) {
+	var jobs []types.Job
+	db := s.store.DB().Order("id desc").Limit(limit)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	return jobs, db.Find(&jobs).Error
+}
+
+// Claim marks the oldest queued job as running and returns it, or nil if none is queued
+func (s *SQLiteJobStorage) Claim() (*types.Job, error) {
+	var claimed *types.Job
+	err := s.store.DB().Transaction(func(tx *gorm.DB) error {
+		job := &types.Job{}
+		result := tx.Where("status = ?", types.JobQueued).Order("id").Limit(1).Find(job)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		job.Status = types.JobRunning
+		job.StartedAt = time.Now().Unix()
+		result = tx.Model(job).Where("status = ?", types.JobQueued).
+			Select("status", "started_at", "updated_at").Updates(job)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		claimed = job
+		return nil
+	})
+	return claimed, err
+}
+
+// Update saves the state, progress and outcome of a job
+func (s *SQLiteJobStorage) Update(job *types.Job) error {
+	result := s.store.DB().Model(job).
+		Select("status", "progress", "total", "result", "error", "started_at", "finished_at", "updated_at").
+		Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return types.ErrJobNotFound
+	}
+	return nil
+}
+
+// CancelQueued marks a job cancelled if it is still queued, reporting whether it was
+func (s *SQLiteJobStorage) CancelQueued(id int) (bool, error) {
+	now := time.Now().Unix()
+	result := s.store.DB().Model(&types.Job{ID: id}).Where("status = ?", types.JobQueued).
+		Updates(map[string]interface{}{"status": types.JobCancelled, "finished_at": now, "updated_at": now})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Requeue puts jobs left running by a previous run back in the queue
+func (s *SQLiteJobStorage) Requeue() (int64, error) {
+	result := s.store.DB().Model(&types.Job{}).Where("status = ?", types.JobRunning).
+		Updates(map[string]interface{}{"status": types.JobQueued, "updated_at": time.Now().Unix()})
+	return result.RowsAffected, result.Error
+}
+
+// Interrupt marks the jobs of kinds left running by a previous run failed with message
+func (s *SQLiteJobStorage) Interrupt(kinds []string, message string) (int64, error) {
+	if len(kinds) == 0 {
+		return 0, nil
+	}
+	now := time.Now().Unix()
+	result := s.store.DB().Model(&types.Job{}).Where("status = ? AND kind IN ?", types.JobRunning, kinds).
+		Updates(map[string]interface{}{"status": types.JobFailed, "error": message, "finished_at": now, "updated_at": now})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteFinished deletes the jobs in a final state
+func (s *SQLiteJobStorage) DeleteFinished() (int64, error) {
+	result := s.store.DB().Where("status IN ?", []string{types.JobDone, types.JobFailed, types.JobCancelled}).
+		Delete(&types.Job{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestJobStorage(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteJobStorage(store)
+
+	first := &types.Job{Kind: types.JobCSVImport, Status: types.JobQueued, Params: []byte(`{"path":"a.csv"}`)}
+	second := &types.Job{Kind: types.JobRebuildSearch, Status: types.JobQueued, Params: []byte(`{}`)}
+	third := &types.Job{Kind: types.JobRebuildSearch, Status: types.JobQueued, Params: []byte(`{}`)}
+	require.NoError(t, st.Create(first))
+	require.NoError(t, st.Create(second))
+	require.NoError(t, st.Create(third))
+
+	// Jobs are claimed oldest first
+	claimed, err := st.Claim()
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, first.ID, claimed.ID)
+	assert.Equal(t, types.JobRunning, claimed.Status)
+	assert.NotZero(t, claimed.StartedAt)
+	assert.JSONEq(t, `{"path":"a.csv"}`, string(claimed.Params))
+
+	claimed.Progress, claimed.Total = 3, 10
+	claimed.Status = types.JobDone
+	claimed.Result = []byte(`{"created":3}`)
+	require.NoError(t, st.Update(claimed))
+	stored, err := st.Get(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobDone, stored.Status)
+	assert.Equal(t, 3, stored.Progress)
+	assert.JSONEq(t, `{"created":3}`, string(stored.Result))
+
+	// Only queued jobs can be cancelled in storage
+	cancelled, err := st.CancelQueued(first.ID)
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+	cancelled, err = st.CancelQueued(third.ID)
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+
+	claimed, err = st.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, claimed.ID)
+	claimed, err = st.Claim()
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// Only the given kinds of jobs left running are failed
+	count, err := st.Interrupt([]string{"anki_import"}, "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// A job left running goes back to the queue
+	count, err = st.Requeue()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	queued, err := st.List(types.JobQueued, 10)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, second.ID, queued[0].ID)
+
+	all, err := st.List("", 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, third.ID, all[0].ID)
+
+	count, err = st.DeleteFinished()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	_, err = st.Get(first.ID)
+	assert.ErrorIs(t, err, types.ErrJobNotFound)
+	assert.ErrorIs(t, st.Update(&types.Job{ID: first.ID, Status: types.JobDone}), types.ErrJobNotFound)
+}
+
+func TestJobStorageInterrupt(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteJobStorage(store)
+	csv := &types.Job{Kind: types.JobCSVImport, Status: types.JobRunning}
+	rebuild := &types.Job{Kind: types.JobRebuildSearch, Status: types.JobRunning}
+	queued := &types.Job{Kind: types.JobCSVImport, Status: types.JobQueued}
+	for _, job := range []*types.Job{csv, rebuild, queued} {
+		require.NoError(t, st.Create(job))
+	}
+
+	count, err := st.Interrupt([]string{types.JobCSVImport}, "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	stored, err := st.Get(csv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobFailed, stored.Status)
+	assert.Equal(t, "interrupted", stored.Error)
+	assert.NotZero(t, stored.FinishedAt)
+
+	count, err = st.Requeue()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "other kinds are resumed")
+}
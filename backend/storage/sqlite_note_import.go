@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"errors"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+)
+
+// errDryRun rolls back an import transaction after the stats were collected
+var errDryRun = errors.New("dry run")
+
+// Import writes notes in a single transaction. Tags are referenced by name and
+// created when missing, notes with a GUID that already exists are updated and
+// keep their deck, their title unless the import has one and the tags they
+// were given since. New notes without a DeckID go to the default deck.
+// A note without a CategoryID may reference its category by name through
+// Category and its Parent chain, missing categories are created.
+// In dry-run mode the transaction is rolled back and only the stats are returned.
+func (s *SQLiteNoteStorage) Import(notes []*types.Note, dryRun bool) (types.ImportStats, error) {
+	var stats types.ImportStats
+	err := s.store.DB().Transaction(func(tx *gorm.DB) error {
+		tags := map[string]types.Tag{}
+		categories := map[categoryKey]int{}
+		for _, note := range notes {
+			resolved, err := resolveTagNames(tx, note.Tags, tags, &stats)
+			if err != nil {
+				return err
+			}
+			if note.CategoryID == nil && note.Category != nil {
+				categoryID, err := resolveCategoryPath(tx, note.Category, categories, &stats)
+				if err != nil {
+					return err
+				}
+				note.CategoryID = &categoryID
+				note.Category = nil
+			}
+			if err := checkNoteCategory(tx, note.CategoryID); err != nil {
+				return err
+			}
+
+			existing := &types.Note{}
+			found := false
+			if note.GUID != "" {
+				result := tx.Select("id", "title", "deck_id", "category_id").Where("guid = ?", note.GUID).Limit(1).Find(existing)
+				if result.Error != nil {
+					return result.Error
+				}
+				found = result.RowsAffected > 0
+			}
+
+			if !found {
+				if err := checkNoteDeck(tx, note); err != nil {
+					return err
+				}
+				note.Tags = resolved
+				if err := tx.Omit("Tags.*", "Category").Create(note).Error; err != nil {
+					return err
+				}
+				stats.Created++
+				continue
+			}
+
+			note.ID = existing.ID
+			note.DeckID = existing.DeckID
+			if note.Title == "" {
+				note.Title = existing.Title
+			}
+			if note.CategoryID == nil {
+				note.CategoryID = existing.CategoryID
+			}
+			columns := []string{"front", "back", "furigana", "romaji", "category_id", "updated_at"}
+			if note.Title != "" {
+				columns = append(columns, "title")
+			}
+			if err := tx.Model(&types.Note{ID: note.ID}).Select(columns).Updates(note).Error; err != nil {
+				return err
+			}
+			// Append saves the tags already on the note too, which are still unresolved
+			note.Tags = nil
+			if err := tx.Model(note).Omit("Tags.*").Association("Tags").Append(resolved); err != nil {
+				return err
+			}
+			if err := tx.Model(note).Association("Tags").Find(&note.Tags); err != nil {
+				return err
+			}
+			stats.Updated++
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return stats, err
+}
+
+// DeckIDs returns the deck of the stored notes with one of the GUIDs, by GUID
+func (s *SQLiteNoteStorage) DeckIDs(guids []string) (map[string]int, error) {
+	var rows []struct {
+		GUID   string
+		DeckID int
+	}
+	err := s.store.DB().Model(&types.Note{}).Select("guid", "deck_id").Where("guid IN ?", guids).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	decks := make(map[string]int, len(rows))
+	for _, row := range rows {
+		decks[row.GUID] = row.DeckID
+	}
+	return decks, nil
+}
+
+// resolveTagNames turns tags referenced by name into stored tags, creating the missing ones.
+// Tags that already carry an id are looked up by id instead.
+func resolveTagNames(tx *gorm.DB, refs []types.Tag, cache map[string]types.Tag, stats *types.ImportStats) ([]types.Tag, error) {
+	resolved := make([]types.Tag, 0, len(refs))
+	seen := map[int]bool{}
+	for _, ref := range refs {
+		if ref.ID > 0 {
+			tags, err := findTagsByID(tx, []types.Tag{ref})
+			if err != nil {
+				return nil, err
+			}
+			ref = tags[0]
+		} else if tag, ok := cache[ref.Name]; ok {
+			ref = tag
+		} else {
+			tag := types.Tag{}
+			result := tx.Where("name = ?", ref.Name).Limit(1).Find(&tag)
+			if result.Error != nil {
+				return nil, result.Error
+			}
+			if result.RowsAffected == 0 {
+				tag = types.Tag{Name: ref.Name}
+				if err := tx.Create(&tag).Error; err != nil {
+					return nil, err
+				}
+				stats.TagsCreated++
+			}
+			cache[ref.Name] = tag
+			ref = tag
+		}
+		if !seen[ref.ID] {
+			seen[ref.ID] = true
+			resolved = append(resolved, ref)
+		}
+	}
+	return resolved, nil
+}
+
+// categoryKey identifies a category by its parent and name
+type categoryKey struct {
+	parentID int
+	name     string
+}
+
+// resolveCategoryPath returns the id of a category referenced by name, whose
+// parents are referenced through Parent, creating the missing ones from the root down
+func resolveCategoryPath(tx *gorm.DB, ref *types.Category, cache map[categoryKey]int, stats *types.ImportStats) (int, error) {
+	parentID := 0
+	if ref.Parent != nil {
+		id, err := resolveCategoryPath(tx, ref.Parent, cache, stats)
+		if err != nil {
+			return 0, err
+		}
+		parentID = id
+	}
+	key := categoryKey{parentID: parentID, name: ref.Name}
+	if id, ok := cache[key]; ok {
+		return id, nil
+	}
+	if ref.Name == "" {
+		return 0, types.ErrCategoryNameEmpty
+	}
+
+	category := types.Category{}
+	query := tx.Where("name = ?", ref.Name)
+	if parentID == 0 {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", parentID)
+	}
+	result := query.Limit(1).Find(&category)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		category = types.Category{Name: ref.Name}
+		if parentID > 0 {
+			category.ParentID = &parentID
+		}
+		if err := tx.Omit("Parent").Create(&category).Error; err != nil {
+			return 0, err
+		}
+		stats.CategoriesCreated++
+	}
+	cache[key] = category.ID
+	return category.ID, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestNoteImport(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+	require.NoError(t, store.DB().Create(&types.Tag{Name: "n5"}).Error)
+
+	batch := func() []*types.Note {
+		return []*types.Note{
+			{GUID: "a", Front: "いぬ", Back: "dog", Tags: []types.Tag{{Name: "n5"}, {Name: "animals"}}},
+			{GUID: "b", Front: "ねこ", Back: "cat", Tags: []types.Tag{{Name: "animals"}, {Name: "animals"}}},
+			{Front: "とり", Back: "bird"},
+		}
+	}
+
+	stats, err := st.Import(batch(), true)
+	require.NoError(t, err)
+	assert.Equal(t, types.ImportStats{Created: 3, TagsCreated: 1}, stats)
+	_, total, err := st.List(types.NoteFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total, "dry run must not write")
+
+	stats, err = st.Import(batch(), false)
+	require.NoError(t, err)
+	assert.Equal(t, types.ImportStats{Created: 3, TagsCreated: 1}, stats)
+
+	// A title and tag given since the last import are kept
+	notes, _, err := st.List(types.NoteFilter{Keyword: "いぬ"}, 0, 10)
+	require.NoError(t, err)
+	dog := notes[0]
+	dog.Title = "Dog"
+	require.NoError(t, st.Update(&dog))
+	local := types.Tag{Name: "mine"}
+	require.NoError(t, store.DB().Create(&local).Error)
+	require.NoError(t, st.AddTags(dog.ID, []int{local.ID}))
+
+	again := batch()
+	again[0].Back = "dog (animal)"
+	again[0].Tags = []types.Tag{{Name: "animals"}}
+	stats, err = st.Import(again, false)
+	require.NoError(t, err)
+	assert.Equal(t, types.ImportStats{Created: 1, Updated: 2}, stats, "notes without a GUID are always new")
+	assert.Equal(t, "Dog", again[0].Title)
+
+	notes, total, err = st.List(types.NoteFilter{Keyword: "dog"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "dog (animal)", notes[0].Back)
+	assert.Equal(t, "Dog", notes[0].Title)
+	names := []string{}
+	for _, tag := range notes[0].Tags {
+		names = append(names, tag.Name)
+	}
+	assert.ElementsMatch(t, []string{"n5", "animals", "mine"}, names)
+
+	_, total, err = st.List(types.NoteFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+
+	decks, err := st.DeckIDs([]string{"a", "b", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": notes[0].DeckID, "b": notes[0].DeckID}, decks)
+
+	_, err = st.Import([]*types.Note{{Front: "x", Tags: []types.Tag{{ID: 99}}}}, false)
+	assert.Equal(t, types.ErrTagNotFound, err)
+}
+
+func TestNoteImportCategoryPath(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+	textbook := types.Category{Name: "Textbook"}
+	require.NoError(t, store.DB().Create(&textbook).Error)
+
+	lesson := func(name string) *types.Category {
+		return &types.Category{Name: name, Parent: &types.Category{Name: "Textbook"}}
+	}
+	notes := []*types.Note{
+		{Front: "いぬ", Category: lesson("Lesson 1")},
+		{Front: "ねこ", Category: lesson("Lesson 1")},
+		{Front: "とり", Category: lesson("Lesson 2")},
+		// A category with the same name at the root is a different category
+		{Front: "さかな", Category: &types.Category{Name: "Lesson 1"}},
+	}
+	stats, err := st.Import(notes, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.CategoriesCreated)
+	assert.Equal(t, *notes[0].CategoryID, *notes[1].CategoryID)
+	assert.NotEqual(t, *notes[0].CategoryID, *notes[3].CategoryID)
+
+	_, total, err := st.List(types.NoteFilter{CategoryID: textbook.ID}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	var count int64
+	store.DB().Model(&types.Category{}).Count(&count)
+	assert.Equal(t, int64(4), count)
+
+	_, err = st.Import([]*types.Note{{Front: "x", Category: &types.Category{}}}, false)
+	assert.Equal(t, types.ErrCategoryNameEmpty, err)
+}
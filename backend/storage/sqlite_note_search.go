@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"fmt"
+	"langlearner1/backend/types"
+	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ftsMinTermLength is the shortest term the trigram tokenizer can match
+const ftsMinTermLength = 3
+
+// snippet markers wrapped around matched text
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+// warnNoFTS logs once that note search runs without the full-text index
+var warnNoFTS sync.Once
+
+// noteSearchTriggers keep notes_fts in sync with the notes table
+var noteSearchTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS notes_fts_ai AFTER INSERT ON notes BEGIN
+		INSERT INTO notes_fts(rowid, title, front, back) VALUES (new.id, new.title, new.front, new.back);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS notes_fts_ad AFTER DELETE ON notes BEGIN
+		INSERT INTO notes_fts(notes_fts, rowid, title, front, back) VALUES ('delete', old.id, old.title, old.front, old.back);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS notes_fts_au AFTER UPDATE OF title, front, back ON notes BEGIN
+		INSERT INTO notes_fts(notes_fts, rowid, title, front, back) VALUES ('delete', old.id, old.title, old.front, old.back);
+		INSERT INTO notes_fts(rowid, title, front, back) VALUES (new.id, new.title, new.front, new.back);
+	END`,
+}
+
+// setupNoteSearch creates the FTS5 index over notes and its sync triggers, and
+// reports whether the index is available. The trigram tokenizer matches arbitrary
+// substrings, which covers Japanese and Chinese text that has no spaces between words.
+func setupNoteSearch(db *gorm.DB) (bool, error) {
+	err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
+		title, front, back,
+		content='notes', content_rowid='id', tokenize='trigram'
+	)`).Error
+	if err != nil {
+		if !strings.Contains(err.Error(), "no such module: fts5") {
+			return false, err
+		}
+		warnNoFTS.Do(func() {
+			log.Println("storage: SQLite was built without FTS5, note search scans every note with LIKE; build with -tags sqlite_fts5")
+		})
+		// Without FTS5 the triggers would make every write to notes fail
+		for _, name := range []string{"notes_fts_ai", "notes_fts_ad", "notes_fts_au"} {
+			if err := db.Exec("DROP TRIGGER IF EXISTS " + name).Error; err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	// Triggers missing means the index is new or was left stale by a build without FTS5
+	var triggers int64
+	err = db.Table("sqlite_master").Where("type = 'trigger' AND name LIKE 'notes_fts_%'").Count(&triggers).Error
+	if err != nil {
+		return false, err
+	}
+	for _, trigger := range noteSearchTriggers {
+		if err := db.Exec(trigger).Error; err != nil {
+			return false, err
+		}
+	}
+	if triggers < int64(len(noteSearchTriggers)) {
+		return true, rebuildNoteSearch(db)
+	}
+	return true, nil
+}
+
+// rebuildNoteSearch regenerates the FTS index from the notes table
+func rebuildNoteSearch(db *gorm.DB) error {
+	return db.Exec("INSERT INTO notes_fts(notes_fts) VALUES ('rebuild')").Error
+}
+
+// ftsQuery converts user input into an FTS5 query that ANDs every whitespace
+// separated term as a literal phrase. Terms shorter than a trigram cannot be
+// matched by the index and are returned in short, to be matched with LIKE
+// among the notes the index finds. ok is false when the index cannot answer
+// any of the terms, as for most two character Japanese words on their own.
+func (s *Store) ftsQuery(keyword string) (query string, short []string, ok bool) {
+	terms := strings.Fields(keyword)
+	if !s.searchable() {
+		return "", terms, false
+	}
+	phrases := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < ftsMinTermLength {
+			short = append(short, term)
+			continue
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(phrases, " "), short, len(phrases) > 0
+}
+
+// applyKeywordFilter restricts a notes query to notes containing every term of keyword
+func (s *Store) applyKeywordFilter(db *gorm.DB, keyword string) *gorm.DB {
+	query, short, ok := s.ftsQuery(keyword)
+	if ok {
+		db = db.Where("notes.id IN (SELECT rowid FROM notes_fts WHERE notes_fts MATCH ?)", query)
+	}
+	return applyLikeTerms(db, short)
+}
+
+// applyLikeTerms restricts a notes query to notes containing every one of terms
+func applyLikeTerms(db *gorm.DB, terms []string) *gorm.DB {
+	for _, term := range terms {
+		pattern := "%" + term + "%"
+		db = db.Where("notes.title LIKE ? OR notes.front LIKE ? OR notes.back LIKE ?", pattern, pattern, pattern)
+	}
+	return db
+}
+
+// searchRow is a matched note id with its snippets before the note is loaded
+type searchRow struct {
+	ID           int
+	FrontSnippet string
+	BackSnippet  string
+	Rank         float64
+}
+
+// Search returns notes matching keyword ordered by relevance, with highlighted snippets
+func (s *SQLiteNoteStorage) Search(keyword string, limit int) ([]types.NoteSearchResult, error) {
+	if strings.TrimSpace(keyword) == "" {
+		return []types.NoteSearchResult{}, nil
+	}
+
+	var rows []searchRow
+	if query, short, ok := s.store.ftsQuery(keyword); ok {
+		// bm25 weights: title, front, back
+		db := s.store.DB().Table("notes_fts").
+			Select(fmt.Sprintf(`notes_fts.rowid AS id,
+				snippet(notes_fts, 1, '%[1]s', '%[2]s', '…', 64) AS front_snippet,
+				snippet(notes_fts, 2, '%[1]s', '%[2]s', '…', 64) AS back_snippet,
+				bm25(notes_fts, 2.0, 1.0, 1.0) AS rank`, highlightOpen, highlightClose)).
+			Where("notes_fts MATCH ?", query)
+		if len(short) > 0 {
+			db = db.Joins("JOIN notes ON notes.id = notes_fts.rowid")
+			db = applyLikeTerms(db, short)
+		}
+		if err := db.Order("rank").Limit(limit).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			rows[i].FrontSnippet = highlightTerms(rows[i].FrontSnippet, short)
+			rows[i].BackSnippet = highlightTerms(rows[i].BackSnippet, short)
+		}
+	} else {
+		var notes []types.Note
+		err := s.store.applyKeywordFilter(s.store.DB().Model(&types.Note{}), keyword).
+			Select("id", "front", "back").
+			Order("updated_at desc").
+			Limit(limit).
+			Find(&notes).Error
+		if err != nil {
+			return nil, err
+		}
+		terms := strings.Fields(keyword)
+		for _, note := range notes {
+			rows = append(rows, searchRow{
+				ID:           note.ID,
+				FrontSnippet: highlightTerms(note.Front, terms),
+				BackSnippet:  highlightTerms(note.Back, terms),
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return []types.NoteSearchResult{}, nil
+	}
+
+	ids := make([]int, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var notes []types.Note
+	if err := preloadNote(s.store.DB()).Where("id IN ?", ids).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	notesByID := make(map[int]types.Note, len(notes))
+	for _, note := range notes {
+		notesByID[note.ID] = note
+	}
+	results := make([]types.NoteSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, types.NoteSearchResult{
+			Note:         notesByID[row.ID],
+			FrontSnippet: row.FrontSnippet,
+			BackSnippet:  row.BackSnippet,
+			Rank:         row.Rank,
+		})
+	}
+	return results, nil
+}
+
+// RebuildSearchIndex regenerates the full-text index from the notes table
+func (s *SQLiteNoteStorage) RebuildSearchIndex() error {
+	if !s.store.searchable() {
+		return nil
+	}
+	return rebuildNoteSearch(s.store.DB())
+}
+
+// highlightTerms wraps case-insensitive occurrences of terms in text with highlight markers
+func highlightTerms(text string, terms []string) string {
+	if text == "" {
+		return text
+	}
+	// Match case-insensitively unless lower-casing changes byte offsets
+	fold := strings.ToLower
+	if len(fold(text)) != len(text) {
+		fold = func(s string) string { return s }
+	}
+	haystack := fold(text)
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		needle := fold(term)
+		if needle == "" {
+			continue
+		}
+		for start := 0; ; {
+			i := strings.Index(haystack[start:], needle)
+			if i < 0 {
+				break
+			}
+			for j := start + i; j < start+i+len(needle); j++ {
+				marked[j] = true
+			}
+			start += i + len(needle)
+		}
+	}
+
+	var b strings.Builder
+	open := false
+	for i := 0; i < len(text); i++ {
+		if marked[i] != open {
+			if marked[i] {
+				b.WriteString(highlightOpen)
+			} else {
+				b.WriteString(highlightClose)
+			}
+			open = marked[i]
+		}
+		b.WriteByte(text[i])
+	}
+	if open {
+		b.WriteString(highlightClose)
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+//go:build sqlite_fts5 || fts5
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestNoteSearchRanking(t *testing.T) {
+	store := newTestStore(t)
+	require.True(t, store.fts)
+	st := NewSQLiteNoteStorage(store)
+
+	require.NoError(t, st.Create(&types.Note{Front: "駅はどこですか", Back: "Where is the station?"}))
+	require.NoError(t, st.Create(&types.Note{Title: "駅で", Front: "駅で待っています。駅の前です", Back: "I am waiting at the station"}))
+	require.NoError(t, st.Create(&types.Note{Front: "天気がいい", Back: "Nice weather"}))
+
+	results, err := st.Search("station", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.LessOrEqual(t, results[0].Rank, results[1].Rank)
+	assert.Contains(t, results[0].BackSnippet, "<mark>station</mark>")
+
+	// Rebuilding keeps the index consistent with the table
+	require.NoError(t, st.RebuildSearchIndex())
+	results, err = st.Search("待って", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "駅で", results[0].Note.Title)
+}
+
+func TestSetupNoteSearchIndexesExistingNotes(t *testing.T) {
+	store := newTestStore(t)
+	for _, name := range []string{"notes_fts_ai", "notes_fts_ad", "notes_fts_au"} {
+		require.NoError(t, store.DB().Exec("DROP TRIGGER "+name).Error)
+	}
+	deck, err := defaultDeck(store.DB())
+	require.NoError(t, err)
+	require.NoError(t, store.DB().Create(&types.Note{Front: "図書館に行きます", DeckID: deck.ID}).Error)
+
+	fts, err := setupNoteSearch(store.DB())
+	require.NoError(t, err)
+	require.True(t, fts)
+	results, err := NewSQLiteNoteStorage(store).Search("図書館", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+// newTestStore opens a fresh in-memory database for one test
+func newTestStore(t *testing.T) *Store {
+	store, err := OpenMemory()
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestNoteSearch(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+
+	greeting := &types.Note{Front: "こんにちは、田中さん", Back: "Hello, Mr. Tanaka"}
+	thanks := &types.Note{Front: "ありがとうございます", Back: "Thank you very much"}
+	require.NoError(t, st.Create(greeting))
+	require.NoError(t, st.Create(thanks))
+
+	results, err := st.Search("こんにちは", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, greeting.ID, results[0].Note.ID)
+	assert.Contains(t, results[0].FrontSnippet, "<mark>こんにちは</mark>")
+
+	// Two character Japanese words are shorter than a trigram and go through LIKE
+	results, err = st.Search("田中", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "こんにちは、<mark>田中</mark>さん", results[0].FrontSnippet)
+
+	// With a longer term the index finds the notes the short term is matched in
+	results, err = st.Search("こんにちは 田中", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].FrontSnippet, "<mark>田中</mark>")
+	results, err = st.Search("こんにちは 鈴木", 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	notes, _, err := st.List(types.NoteFilter{Keyword: "こんにちは 田中"}, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+
+	results, err = st.Search("thank very", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, thanks.ID, results[0].Note.ID)
+
+	notes, _, err = st.List(types.NoteFilter{Keyword: "ございます"}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, thanks.ID, notes[0].ID)
+
+	// Edits and deletes are picked up by the index
+	thanks.Front = "どうもありがとう"
+	require.NoError(t, st.Update(thanks))
+	results, err = st.Search("ございます", 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	results, err = st.Search("どうも", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	require.NoError(t, st.Delete(greeting.ID))
+	results, err = st.Search("こんにちは", 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, err = st.Search("  ", 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestHighlightTerms(t *testing.T) {
+	assert.Equal(t, "<mark>Hello</mark> <mark>hello</mark>", highlightTerms("Hello hello", []string{"hello"}))
+	assert.Equal(t, "<mark>ねこ</mark>が<mark>すき</mark>", highlightTerms("ねこがすき", []string{"すき", "ねこ"}))
+	assert.Equal(t, "<mark>abcd</mark>", highlightTerms("abcd", []string{"abc", "bcd"}))
+	assert.Equal(t, "", highlightTerms("", []string{"a"}))
+}
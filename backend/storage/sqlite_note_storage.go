@@ -1,51 +1,289 @@
 package storage
 
 import (
+	"errors"
 	"langlearner1/backend/types"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // SQLiteNoteStorage implements NoteStorageIf interface with SQLite storage
-type SQLiteNoteStorage struct{}
+type SQLiteNoteStorage struct {
+	store *Store
+}
 
 // NewSQLiteNoteStorage creates a new instance of SQLiteNoteStorage
-func NewSQLiteNoteStorage() NoteStorageIf {
-	return &SQLiteNoteStorage{}
+func NewSQLiteNoteStorage(store *Store) NoteStorageIf {
+	return &SQLiteNoteStorage{store: store}
 }
 
-// List returns notes with optional id and keyword filters, supports pagination
-func (s *SQLiteNoteStorage) List(id int, keyword string, offset int, limit int) ([]types.Note, error) {
+// List returns one page of notes matching the filter, and the total number of matches
+func (s *SQLiteNoteStorage) List(filter types.NoteFilter, offset int, limit int) ([]types.Note, int64, error) {
 	var notes []types.Note
-	db := DB
-	if id > 0 {
-		db = db.Where("id = ?", id)
+	var total int64
+	db := s.store.applyNoteFilter(s.store.DB().Model(&types.Note{}), filter)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
-	if keyword != "" {
-		db = db.Where("front LIKE ? OR back LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	result := preloadNote(db).
+		Order("notes.updated_at desc, notes.id desc").
+		Offset(offset).Limit(limit).Find(&notes)
+	return notes, total, result.Error
+}
+
+// ListAfter returns up to limit notes matching the filter that come after cursor in the list order.
+// It seeks on (updated_at, id) instead of skipping rows, so deep pages stay cheap on large decks.
+func (s *SQLiteNoteStorage) ListAfter(filter types.NoteFilter, cursor types.NoteCursor, limit int) ([]types.Note, error) {
+	var notes []types.Note
+	db := s.store.applyNoteFilter(s.store.DB().Model(&types.Note{}), filter)
+	if cursor.ID > 0 {
+		db = db.Where("notes.updated_at < ? OR (notes.updated_at = ? AND notes.id < ?)",
+			cursor.UpdatedAt, cursor.UpdatedAt, cursor.ID)
 	}
-	result := db.Preload("Tags").Order("updated_at desc").Offset(offset).Limit(limit).Find(&notes)
+	result := preloadNote(db).
+		Order("notes.updated_at desc, notes.id desc").
+		Limit(limit).Find(&notes)
 	return notes, result.Error
 }
 
-// Create creates a new note
+// Get returns a note by id
+func (s *SQLiteNoteStorage) Get(id int) (*types.Note, error) {
+	note := &types.Note{}
+	err := preloadNote(s.store.DB()).Take(note, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, types.ErrNoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// preloadNote loads the deck, tags, category and assets of the notes a query returns
+func preloadNote(db *gorm.DB) *gorm.DB {
+	return db.Preload("Deck").Preload("Tags").Preload("Category").
+		Preload("Assets", func(db *gorm.DB) *gorm.DB {
+			return db.Order("role, created_at, asset_id")
+		}).
+		Preload("Assets.Asset")
+}
+
+// applyNoteFilter adds the conditions of filter to a query on the notes table
+func (s *Store) applyNoteFilter(db *gorm.DB, filter types.NoteFilter) *gorm.DB {
+	sub := db.Session(&gorm.Session{NewDB: true})
+	if filter.ID > 0 {
+		db = db.Where("notes.id = ?", filter.ID)
+	}
+	if filter.Keyword != "" {
+		db = s.applyKeywordFilter(db, filter.Keyword)
+	}
+	if filter.DeckID > 0 {
+		db = db.Where("notes.deck_id = ?", filter.DeckID)
+	}
+	if len(filter.TagIDs) > 0 {
+		tagIDs := uniqueIDs(filter.TagIDs)
+		if filter.MatchAllTags {
+			db = db.Where("notes.id IN (?)", sub.Table("note_tags").Select("note_id").
+				Where("tag_id IN ?", tagIDs).
+				Group("note_id").
+				Having("COUNT(DISTINCT tag_id) = ?", len(tagIDs)))
+		} else {
+			db = db.Where("notes.id IN (?)", sub.Table("note_tags").Select("note_id").Where("tag_id IN ?", tagIDs))
+		}
+	}
+	if filter.CategoryID > 0 {
+		db = db.Where("notes.category_id IN (?)", sub.Raw(categoryTreeSQL, filter.CategoryID))
+	}
+	return db
+}
+
+// Create creates a new note and links it to the tags referenced by ID,
+// a note without a deck goes to the default deck
 func (s *SQLiteNoteStorage) Create(note *types.Note) error {
-	result := DB.Create(note)
-	return result.Error
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		tags, err := findTagsByID(tx, note.Tags)
+		if err != nil {
+			return err
+		}
+		if err := checkNoteDeck(tx, note); err != nil {
+			return err
+		}
+		if err := checkNoteCategory(tx, note.CategoryID); err != nil {
+			return err
+		}
+		note.Tags = tags
+		if err := tx.Omit("Tags.*", "Category").Create(note).Error; err != nil {
+			return err
+		}
+		return preloadNote(tx).First(note, note.ID).Error
+	})
 }
 
-// Update updates an existing note
+// Update updates the editable fields of an existing note and replaces its tags,
+// a note without a deck stays in its deck
 func (s *SQLiteNoteStorage) Update(note *types.Note) error {
-	result := DB.Save(note)
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		tags, err := findTagsByID(tx, note.Tags)
+		if err != nil {
+			return err
+		}
+		fields := []interface{}{"title", "front", "back", "furigana", "romaji", "category_id", "updated_at"}
+		if note.DeckID != 0 {
+			if err := checkNoteDeck(tx, note); err != nil {
+				return err
+			}
+			fields = append(fields, "deck_id")
+		}
+		if err := checkNoteCategory(tx, note.CategoryID); err != nil {
+			return err
+		}
+		result := tx.Model(&types.Note{ID: note.ID}).
+			Select(fields[0], fields[1:]...).
+			Updates(note)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrNoteNotFound
+		}
+		if err := tx.Model(note).Omit("Tags.*").Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		return preloadNote(tx).First(note, note.ID).Error
+	})
+}
+
+// UpdateReadings saves the furigana and romaji of a note without touching its update time
+func (s *SQLiteNoteStorage) UpdateReadings(note *types.Note) error {
+	result := s.store.DB().Model(&types.Note{ID: note.ID}).
+		UpdateColumns(map[string]interface{}{"furigana": note.Furigana, "romaji": note.Romaji})
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return types.ErrNoteNotFound
 	}
-	return result.Error
+	return nil
 }
 
-// Delete deletes a note
+// Delete deletes a note together with its tag and asset links and its review data
 func (s *SQLiteNoteStorage) Delete(id int) error {
-	result := DB.Delete(&types.Note{}, id)
-	if result.RowsAffected == 0 {
-		return types.ErrNoteNotFound
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"note_tags", "note_assets", "review_states", "review_logs"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE note_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Delete(&types.Note{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrNoteNotFound
+		}
+		return nil
+	})
+}
+
+// AddTags links the given tags to a note, ignoring links that already exist
+func (s *SQLiteNoteStorage) AddTags(noteID int, tagIDs []int) error {
+	return s.changeTags(noteID, tagIDs, func(assoc *gorm.Association, tags []types.Tag) error {
+		return assoc.Append(tags)
+	})
+}
+
+// RemoveTags unlinks the given tags from a note
+func (s *SQLiteNoteStorage) RemoveTags(noteID int, tagIDs []int) error {
+	return s.changeTags(noteID, tagIDs, func(assoc *gorm.Association, tags []types.Tag) error {
+		return assoc.Delete(tags)
+	})
+}
+
+// ReplaceTags makes the given tags the only tags of a note
+func (s *SQLiteNoteStorage) ReplaceTags(noteID int, tagIDs []int) error {
+	return s.changeTags(noteID, tagIDs, func(assoc *gorm.Association, tags []types.Tag) error {
+		return assoc.Replace(tags)
+	})
+}
+
+// changeTags resolves the note and tags, then applies fn to the note's tag association
+func (s *SQLiteNoteStorage) changeTags(noteID int, tagIDs []int, fn func(*gorm.Association, []types.Tag) error) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		note := &types.Note{}
+		if err := tx.Select("id").Take(note, noteID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return types.ErrNoteNotFound
+			}
+			return err
+		}
+		refs := make([]types.Tag, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			refs = append(refs, types.Tag{ID: tagID})
+		}
+		tags, err := findTagsByID(tx, refs)
+		if err != nil {
+			return err
+		}
+		if err := fn(tx.Model(note).Omit("Tags.*").Association("Tags"), tags); err != nil {
+			return err
+		}
+		// Touch the note so tag changes show up in the recently updated order
+		return tx.Model(note).Update("updated_at", time.Now().Unix()).Error
+	})
+}
+
+// findTagsByID loads the tags referenced by ID, failing if any of them is missing
+func findTagsByID(tx *gorm.DB, refs []types.Tag) ([]types.Tag, error) {
+	if len(refs) == 0 {
+		return []types.Tag{}, nil
+	}
+	ids := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	ids = uniqueIDs(ids)
+	var tags []types.Tag
+	if err := tx.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) != len(ids) {
+		return nil, types.ErrTagNotFound
+	}
+	return tags, nil
+}
+
+// categoryTreeSQL selects a category and all of its descendants
+const categoryTreeSQL = `WITH RECURSIVE category_tree(id) AS (
+	SELECT ?
+	UNION ALL
+	SELECT categories.id FROM categories JOIN category_tree ON categories.parent_id = category_tree.id
+) SELECT id FROM category_tree`
+
+// checkNoteCategory makes sure the category a note points at exists
+func checkNoteCategory(tx *gorm.DB, categoryID *int) error {
+	if categoryID == nil {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&types.Category{}).Where("id = ?", *categoryID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return types.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// uniqueIDs returns ids without duplicates, keeping the first occurrence order
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
 	}
-	return result.Error
+	return result
 }
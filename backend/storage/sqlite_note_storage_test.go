@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestNoteListPagination(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+	require.NoError(t, store.DB().Create(&types.Tag{Name: "n5"}).Error)
+	for i := 1; i <= 7; i++ {
+		note := &types.Note{Front: fmt.Sprintf("note %d", i)}
+		if i%2 == 1 {
+			note.Tags = []types.Tag{{ID: 1}}
+		}
+		require.NoError(t, st.Create(note))
+	}
+	// Give every note the same timestamp so ordering falls back to the id
+	require.NoError(t, store.DB().Exec("UPDATE notes SET updated_at = 100").Error)
+
+	var fronts []string
+	for offset := 0; ; offset += 3 {
+		notes, total, err := st.List(types.NoteFilter{}, offset, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), total)
+		if len(notes) == 0 {
+			break
+		}
+		for _, note := range notes {
+			fronts = append(fronts, note.Front)
+		}
+	}
+	assert.Equal(t, []string{"note 7", "note 6", "note 5", "note 4", "note 3", "note 2", "note 1"}, fronts)
+
+	notes, total, err := st.List(types.NoteFilter{TagIDs: []int{1}}, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "note 3", notes[0].Front)
+	assert.Equal(t, "note 1", notes[1].Front)
+}
+
+func TestNoteListMatchAllTags(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+	for _, name := range []string{"n5", "verb", "food"} {
+		require.NoError(t, store.DB().Create(&types.Tag{Name: name}).Error)
+	}
+	require.NoError(t, st.Create(&types.Note{Front: "食べる", Tags: []types.Tag{{ID: 1}, {ID: 2}, {ID: 3}}}))
+	require.NoError(t, st.Create(&types.Note{Front: "行く", Tags: []types.Tag{{ID: 1}, {ID: 2}}}))
+	require.NoError(t, st.Create(&types.Note{Front: "りんご", Tags: []types.Tag{{ID: 1}, {ID: 3}}}))
+
+	fronts := func(filter types.NoteFilter) []string {
+		notes, total, err := st.List(filter, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(notes)), total)
+		var fronts []string
+		for _, note := range notes {
+			fronts = append(fronts, note.Front)
+		}
+		return fronts
+	}
+	// A note with only some of the tags is left out
+	assert.ElementsMatch(t, []string{"食べる", "行く"}, fronts(types.NoteFilter{TagIDs: []int{1, 2}, MatchAllTags: true}))
+	assert.ElementsMatch(t, []string{"食べる"}, fronts(types.NoteFilter{TagIDs: []int{2, 3, 2}, MatchAllTags: true}), "repeated ids count once")
+	assert.ElementsMatch(t, []string{"食べる", "行く", "りんご"}, fronts(types.NoteFilter{TagIDs: []int{2, 3}}))
+}
+
+func TestNoteListAfter(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, st.Create(&types.Note{Front: fmt.Sprintf("note %d", i)}))
+	}
+	require.NoError(t, store.DB().Exec("UPDATE notes SET updated_at = 100 WHERE id <= 3").Error)
+	require.NoError(t, store.DB().Exec("UPDATE notes SET updated_at = 200 WHERE id > 3").Error)
+
+	var ids []int
+	cursor := types.NoteCursor{}
+	for {
+		notes, err := st.ListAfter(types.NoteFilter{}, cursor, 2)
+		require.NoError(t, err)
+		if len(notes) == 0 {
+			break
+		}
+		for _, note := range notes {
+			ids = append(ids, note.ID)
+		}
+		last := notes[len(notes)-1]
+		cursor = types.NoteCursor{UpdatedAt: last.UpdatedAt, ID: last.ID}
+	}
+	assert.Equal(t, []int{5, 4, 3, 2, 1}, ids)
+}
+
+func TestNoteUpdateReadings(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteNoteStorage(store)
+	note := &types.Note{Front: "犬"}
+	require.NoError(t, st.Create(note))
+	require.NoError(t, store.DB().Exec("UPDATE notes SET updated_at = 100").Error)
+
+	note.Furigana, note.Romaji = "<ruby>犬<rt>いぬ</rt></ruby>", "inu"
+	require.NoError(t, st.UpdateReadings(note))
+	saved, err := st.Get(note.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "inu", saved.Romaji)
+	assert.Equal(t, "<ruby>犬<rt>いぬ</rt></ruby>", saved.Furigana)
+	assert.Equal(t, int64(100), saved.UpdatedAt, "readings do not count as an edit")
+
+	assert.ErrorIs(t, st.UpdateReadings(&types.Note{ID: 99}), types.ErrNoteNotFound)
+}
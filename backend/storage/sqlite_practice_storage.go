@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"errors"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+)
+
+// practiceSummaryColumns are the session columns that change while practicing
+var practiceSummaryColumns = []string{"position", "correct", "incorrect", "skipped", "timed_out", "finished_at"}
+
+// SQLitePracticeStorage implements PracticeStorage interface with SQLite storage
+type SQLitePracticeStorage struct {
+	store *Store
+}
+
+// NewSQLitePracticeStorage creates a new instance of SQLitePracticeStorage
+func NewSQLitePracticeStorage(store *Store) PracticeStorage {
+	return &SQLitePracticeStorage{store: store}
+}
+
+// SelectNotes returns the ids of the notes matching a practice filter.
+// Due notes are ordered by due date, random samples in random order and everything else by id.
+func (s *SQLitePracticeStorage) SelectNotes(filter types.PracticeFilter, now int64) ([]int, error) {
+	db := s.store.applyNoteFilter(s.store.DB().Model(&types.Note{}), types.NoteFilter{
+		TagIDs:       filter.TagIDs,
+		MatchAllTags: filter.MatchAllTags,
+		CategoryID:   filter.CategoryID,
+	})
+	if filter.DueOnly {
+		db = db.Joins("LEFT JOIN review_states ON review_states.note_id = notes.id").
+			Where("review_states.note_id IS NULL OR review_states.due <= ?", now)
+	}
+	switch {
+	case filter.Sample > 0:
+		db = db.Order("RANDOM()").Limit(filter.Sample)
+	case filter.DueOnly:
+		db = db.Order("review_states.note_id IS NULL, review_states.due, notes.id")
+	default:
+		db = db.Order("notes.id")
+	}
+	var ids []int
+	result := db.Pluck("notes.id", &ids)
+	return ids, result.Error
+}
+
+// CreateSession creates a new session
+func (s *SQLitePracticeStorage) CreateSession(session *types.PracticeSession) error {
+	return s.store.DB().Create(session).Error
+}
+
+// GetSession returns a session by id
+func (s *SQLitePracticeStorage) GetSession(id int) (*types.PracticeSession, error) {
+	session := &types.PracticeSession{}
+	err := s.store.DB().Take(session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, types.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// SaveResult stores a card outcome together with the updated session summary
+func (s *SQLitePracticeStorage) SaveResult(session *types.PracticeSession, result *types.PracticeResult) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(result).Error; err != nil {
+			return err
+		}
+		return updatePracticeSummary(tx, session)
+	})
+}
+
+// UpdateSession stores the summary fields of a session
+func (s *SQLitePracticeStorage) UpdateSession(session *types.PracticeSession) error {
+	return updatePracticeSummary(s.store.DB(), session)
+}
+
+// ListSessions returns one page of sessions ordered from the most recent, and the total number of sessions
+func (s *SQLitePracticeStorage) ListSessions(offset int, limit int) ([]types.PracticeSession, int64, error) {
+	var sessions []types.PracticeSession
+	var total int64
+	if err := s.store.DB().Model(&types.PracticeSession{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	result := s.store.DB().Order("started_at desc, id desc").Offset(offset).Limit(limit).Find(&sessions)
+	return sessions, total, result.Error
+}
+
+func updatePracticeSummary(tx *gorm.DB, session *types.PracticeSession) error {
+	result := tx.Model(&types.PracticeSession{ID: session.ID}).Select(practiceSummaryColumns).Updates(session)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return types.ErrSessionNotFound
+	}
+	return nil
+}
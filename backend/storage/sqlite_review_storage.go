@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SQLiteReviewStorage implements ReviewStorage interface with SQLite storage
+type SQLiteReviewStorage struct {
+	store *Store
+}
+
+// NewSQLiteReviewStorage creates a new instance of SQLiteReviewStorage
+func NewSQLiteReviewStorage(store *Store) ReviewStorage {
+	return &SQLiteReviewStorage{store: store}
+}
+
+// DueNotes returns up to limit notes due at or before now, including never reviewed notes.
+// Notes already in review come first, ordered by how overdue they are, then new notes by age.
+func (s *SQLiteReviewStorage) DueNotes(now int64, limit int) ([]types.DueNote, error) {
+	var rows []struct {
+		NoteID int
+		IsNew  bool
+	}
+	err := s.store.DB().Table("notes").
+		Select("notes.id AS note_id, review_states.note_id IS NULL AS is_new").
+		Joins("LEFT JOIN review_states ON review_states.note_id = notes.id").
+		Where("review_states.note_id IS NULL OR review_states.due <= ?", now).
+		Order("is_new, review_states.due, notes.id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []types.DueNote{}, nil
+	}
+
+	ids := make([]int, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.NoteID)
+	}
+	var notes []types.Note
+	if err := preloadNote(s.store.DB()).Where("id IN ?", ids).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	var states []types.ReviewState
+	if err := s.store.DB().Where("note_id IN ?", ids).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	notesByID := make(map[int]types.Note, len(notes))
+	for _, note := range notes {
+		notesByID[note.ID] = note
+	}
+	statesByID := make(map[int]types.ReviewState, len(states))
+	for _, state := range states {
+		statesByID[state.NoteID] = state
+	}
+
+	result := make([]types.DueNote, 0, len(rows))
+	for _, row := range rows {
+		state, ok := statesByID[row.NoteID]
+		if !ok {
+			state = types.ReviewState{NoteID: row.NoteID}
+		}
+		result = append(result, types.DueNote{
+			Note:  notesByID[row.NoteID],
+			State: state,
+			IsNew: row.IsNew,
+		})
+	}
+	return result, nil
+}
+
+// GetState returns the scheduling state of a note, nil if the note was never reviewed
+func (s *SQLiteReviewStorage) GetState(noteID int) (*types.ReviewState, error) {
+	state := &types.ReviewState{}
+	err := s.store.DB().Take(state, "note_id = ?", noteID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SaveReview stores the new state of a note together with the review log entry
+func (s *SQLiteReviewStorage) SaveReview(state *types.ReviewState, log *types.ReviewLog) error {
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.Note{}).Where("id = ?", state.NoteID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return types.ErrNoteNotFound
+		}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(state).Error; err != nil {
+			return err
+		}
+		return tx.Create(log).Error
+	})
+}
+
+// History returns the most recent review logs of a note
+func (s *SQLiteReviewStorage) History(noteID int, limit int) ([]types.ReviewLog, error) {
+	var logs []types.ReviewLog
+	result := s.store.DB().Where("note_id = ?", noteID).Order("reviewed_at desc, id desc").Limit(limit).Find(&logs)
+	return logs, result.Error
+}
+
+// Stats counts the notes, the new and due notes at now and the reviews since since
+func (s *SQLiteReviewStorage) Stats(now int64, since int64) (*types.ReviewStats, error) {
+	db := s.store.DB()
+	var notes, states, due, reviews, today, failed int64
+	counts := []struct {
+		count *int64
+		query *gorm.DB
+	}{
+		{&notes, db.Model(&types.Note{})},
+		{&states, db.Model(&types.ReviewState{})},
+		{&due, db.Model(&types.ReviewState{}).Where("due <= ?", now)},
+		{&reviews, db.Model(&types.ReviewLog{})},
+		{&today, db.Model(&types.ReviewLog{}).Where("reviewed_at >= ?", since)},
+		// Grade 1 is Again
+		{&failed, db.Model(&types.ReviewLog{}).Where("reviewed_at >= ? AND grade = 1", since)},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.count).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &types.ReviewStats{
+		Notes:        int(notes),
+		New:          int(notes - states),
+		Due:          int(due),
+		Reviews:      int(reviews),
+		ReviewsToday: int(today),
+		FailedToday:  int(failed),
+	}, nil
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestReviewStats(t *testing.T) {
+	store := newTestStore(t)
+	notes := NewSQLiteNoteStorage(store)
+	st := NewSQLiteReviewStorage(store)
+	for _, front := range []string{"犬", "猫", "鳥"} {
+		require.NoError(t, notes.Create(&types.Note{Front: front}))
+	}
+	require.NoError(t, st.SaveReview(&types.ReviewState{NoteID: 1, Due: 500},
+		&types.ReviewLog{NoteID: 1, Grade: 1, ReviewedAt: 50}))
+	require.NoError(t, st.SaveReview(&types.ReviewState{NoteID: 1, Due: 500},
+		&types.ReviewLog{NoteID: 1, Grade: 1, ReviewedAt: 150}))
+	require.NoError(t, st.SaveReview(&types.ReviewState{NoteID: 2, Due: 2000},
+		&types.ReviewLog{NoteID: 2, Grade: 3, ReviewedAt: 200}))
+
+	stats, err := st.Stats(1000, 100)
+	require.NoError(t, err)
+	assert.Equal(t, &types.ReviewStats{Notes: 3, New: 1, Due: 1, Reviews: 3, ReviewsToday: 2, FailedToday: 1}, stats)
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm/clause"
+)
+
+// SQLiteSpeechStorage implements SpeechStorage interface with SQLite storage
+type SQLiteSpeechStorage struct {
+	store *Store
+}
+
+// NewSQLiteSpeechStorage creates a new instance of SQLiteSpeechStorage
+func NewSQLiteSpeechStorage(store *Store) SpeechStorage {
+	return &SQLiteSpeechStorage{store: store}
+}
+
+// Get returns the asset cached under key, or nil if there is none or its asset was removed
+func (s *SQLiteSpeechStorage) Get(key string) (*types.Asset, error) {
+	asset := &types.Asset{}
+	result := s.store.DB().Joins("JOIN speech_cache ON speech_cache.asset_id = assets.id").
+		Where("speech_cache.key = ?", key).Limit(1).Find(asset)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return asset, nil
+}
+
+// Save caches an asset under key
+func (s *SQLiteSpeechStorage) Save(key string, assetID int) error {
+	return s.store.DB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"asset_id", "created_at"}),
+	}).Omit("Asset").Create(&types.SpeechCache{Key: key, AssetID: assetID}).Error
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestSpeechStorage(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteSpeechStorage(store)
+	assetStorage := NewSQLiteAssetStorage(store)
+
+	cached, err := st.Get("key")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+
+	first := &types.Asset{Hash: "aa", Name: "a.wav", MimeType: "audio/wav"}
+	second := &types.Asset{Hash: "bb", Name: "b.wav", MimeType: "audio/wav"}
+	require.NoError(t, assetStorage.Create(first))
+	require.NoError(t, assetStorage.Create(second))
+
+	require.NoError(t, st.Save("key", first.ID))
+	require.NoError(t, st.Save("key", second.ID))
+	cached, err = st.Get("key")
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.Equal(t, "bb", cached.Hash)
+
+	// Removing the asset also removes it from the cache
+	_, err = assetStorage.Delete([]int{second.ID})
+	require.NoError(t, err)
+	cached, err = st.Get("key")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+}
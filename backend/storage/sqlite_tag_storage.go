@@ -2,50 +2,75 @@ package storage
 
 import (
 	"langlearner1/backend/types"
+
+	"gorm.io/gorm"
 )
 
 // SQLiteTagStorage implements TagStorage interface with SQLite storage
-type SQLiteTagStorage struct {}
+type SQLiteTagStorage struct {
+	store *Store
+}
 
 // NewSQLiteTagStorage creates a new instance of SQLiteTagStorage
-func NewSQLiteTagStorage() TagStorage {
-	return &SQLiteTagStorage{}
+func NewSQLiteTagStorage(store *Store) TagStorage {
+	return &SQLiteTagStorage{store: store}
 }
 
-// List returns tags with optional id and keyword filters, supports pagination
-func (s *SQLiteTagStorage) List(id int, keyword string, offset int, limit int) ([]types.Tag, error) {
+// List returns one page of tags with optional id and keyword filters, and the total number of matches
+func (s *SQLiteTagStorage) List(id int, keyword string, offset int, limit int) ([]types.Tag, int64, error) {
 	var tags []types.Tag
-	db := DB
+	var total int64
+	db := s.store.DB().Model(&types.Tag{})
 	if id > 0 {
 		db = db.Where("id = ?", id)
 	}
 	if keyword != "" {
 		db = db.Where("name LIKE ?", "%"+keyword+"%")
 	}
-	result := db.Offset(offset).Limit(limit).Find(&tags)
-	return tags, result.Error
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	result := db.Order("id").Offset(offset).Limit(limit).Find(&tags)
+	return tags, total, result.Error
 }
 
-// Create creates a new tag
+// Create creates a new tag, ErrTagExists is returned for a name that is taken
 func (s *SQLiteTagStorage) Create(tag *types.Tag) error {
-	result := DB.Create(tag)
+	result := s.store.DB().Create(tag)
+	if isUniqueViolation(result.Error) {
+		return types.ErrTagExists
+	}
 	return result.Error
 }
 
-// Update updates an existing tag
+// Update updates an existing tag, ErrTagExists is returned for a name that is taken
 func (s *SQLiteTagStorage) Update(tag *types.Tag) error {
-	result := DB.Save(tag)
+	result := s.store.DB().Save(tag)
+	if isUniqueViolation(result.Error) {
+		return types.ErrTagExists
+	}
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return types.ErrTagNotFound
 	}
-	return result.Error
+	return nil
 }
 
-// Delete deletes a tag
+// Delete deletes a tag and removes it from the notes it is linked to
 func (s *SQLiteTagStorage) Delete(id int) error {
-	result := DB.Delete(&types.Tag{}, id)
-	if result.RowsAffected == 0 {
-		return types.ErrTagNotFound
-	}
-	return result.Error
+	return s.store.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM note_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&types.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrTagNotFound
+		}
+		return nil
+	})
 }
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestTagDelete(t *testing.T) {
+	store := newTestStore(t)
+	tags := NewSQLiteTagStorage(store)
+	notes := NewSQLiteNoteStorage(store)
+	n5, n4 := &types.Tag{Name: "n5"}, &types.Tag{Name: "n4"}
+	require.NoError(t, tags.Create(n5))
+	require.NoError(t, tags.Create(n4))
+	note := &types.Note{Front: "犬", Tags: []types.Tag{{ID: n5.ID}, {ID: n4.ID}}}
+	require.NoError(t, notes.Create(note))
+
+	require.NoError(t, tags.Delete(n5.ID))
+	got, err := notes.Get(note.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Tags, 1, "the note keeps its other tags")
+	assert.Equal(t, "n4", got.Tags[0].Name)
+	var links int64
+	require.NoError(t, store.DB().Table("note_tags").Where("tag_id = ?", n5.ID).Count(&links).Error)
+	assert.Zero(t, links)
+
+	assert.ErrorIs(t, tags.Delete(n5.ID), types.ErrTagNotFound)
+}
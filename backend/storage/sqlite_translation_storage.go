@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm/clause"
+)
+
+// SQLiteTranslationStorage implements TranslationStorage interface with SQLite storage
+type SQLiteTranslationStorage struct {
+	store *Store
+}
+
+// NewSQLiteTranslationStorage creates a new instance of SQLiteTranslationStorage
+func NewSQLiteTranslationStorage(store *Store) TranslationStorage {
+	return &SQLiteTranslationStorage{store: store}
+}
+
+// Get returns the cached translation of text for a language pair, or nil if there is none
+func (s *SQLiteTranslationStorage) Get(text string, source string, target string) (*types.Translation, error) {
+	translation := &types.Translation{}
+	result := s.store.DB().Where("source = ? AND target = ? AND text_hash = ?", source, target, textHash(text)).
+		Limit(1).Find(translation)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return translation, nil
+}
+
+// Save stores a translation, replacing the cached one for the same text and pair
+func (s *SQLiteTranslationStorage) Save(translation *types.Translation) error {
+	translation.TextHash = textHash(translation.Text)
+	return s.store.DB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}, {Name: "target"}, {Name: "text_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"result", "provider", "created_at"}),
+	}).Create(translation).Error
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+func TestTranslationStorage(t *testing.T) {
+	store := newTestStore(t)
+	st := NewSQLiteTranslationStorage(store)
+
+	cached, err := st.Get("いぬ", "ja", "en")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+
+	require.NoError(t, st.Save(&types.Translation{Source: "ja", Target: "en", Text: "いぬ", Result: "doggy", Provider: "a"}))
+	require.NoError(t, st.Save(&types.Translation{Source: "ja", Target: "en", Text: "いぬ", Result: "dog", Provider: "b"}))
+	require.NoError(t, st.Save(&types.Translation{Source: "ja", Target: "zh-CN", Text: "いぬ", Result: "狗", Provider: "a"}))
+
+	cached, err = st.Get("いぬ", "ja", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "dog", cached.Result)
+	assert.Equal(t, "b", cached.Provider)
+	cached, err = st.Get("いぬ", "ja", "zh-CN")
+	require.NoError(t, err)
+	assert.Equal(t, "狗", cached.Result)
+
+	var count int64
+	store.DB().Model(&types.Translation{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
@@ -6,12 +6,12 @@ import (
 
 // TagStorage defines the interface for tag data persistence
 type TagStorage interface {
-	// List returns tags with optional id and keyword filters, supports pagination
-	List(id int, keyword string, offset int, limit int) ([]types.Tag, error)
+	// List returns one page of tags with optional id and keyword filters, and the total number of matches
+	List(id int, keyword string, offset int, limit int) ([]types.Tag, int64, error)
 	// Create creates a new tag
 	Create(tag *types.Tag) error
 	// Update updates an existing tag
 	Update(tag *types.Tag) error
-	// Delete deletes a tag
+	// Delete deletes a tag and removes it from the notes it is linked to
 	Delete(id int) error
 }
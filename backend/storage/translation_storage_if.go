@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"langlearner1/backend/types"
+)
+
+// TranslationStorage defines the interface for the translation cache
+type TranslationStorage interface {
+	// Get returns the cached translation of text for a language pair, or nil if there is none
+	Get(text string, source string, target string) (*types.Translation, error)
+	// Save stores a translation, replacing the cached one for the same text and pair
+	Save(translation *types.Translation) error
+}
@@ -0,0 +1,98 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"langlearner1/backend/types"
+)
+
+// LibreTranslate calls a LibreTranslate compatible /translate endpoint
+type LibreTranslate struct {
+	endpoint  string
+	apiKey    string
+	languages map[string]string
+	client    *http.Client
+}
+
+// NewLibreTranslate creates a LibreTranslate provider for config.Endpoint
+func NewLibreTranslate(config Config) (*LibreTranslate, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("%w: libretranslate", types.ErrProviderEndpoint)
+	}
+	return &LibreTranslate{
+		endpoint:  strings.TrimSuffix(config.Endpoint, "/") + "/translate",
+		apiKey:    config.APIKey,
+		languages: config.Languages,
+		client:    http.DefaultClient,
+	}, nil
+}
+
+func (t *LibreTranslate) Name() string {
+	return "libretranslate"
+}
+
+// language returns the LibreTranslate code of a BCP 47 code. LibreTranslate
+// names languages by their ISO 639-1 code alone, except Traditional Chinese
+// which is "zt". Codes in the languages of the config are used as they are.
+func (t *LibreTranslate) language(code string) string {
+	if mapped, ok := t.languages[code]; ok {
+		return mapped
+	}
+	tag, err := language.Parse(code)
+	if err != nil {
+		return code
+	}
+	base, _ := tag.Base()
+	if base.String() == "zh" {
+		if script, _ := tag.Script(); script.String() == "Hant" {
+			return "zt"
+		}
+	}
+	return base.String()
+}
+
+// Translate sends text as plain text and returns the translated text
+func (t *LibreTranslate) Translate(ctx context.Context, text string, source string, target string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"q":       text,
+		"source":  t.language(source),
+		"target":  t.language(target),
+		"format":  "text",
+		"api_key": t.apiKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		TranslatedText string `json:"translatedText"`
+		Error          string `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil && res.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("%w: libretranslate: invalid response: %w", types.ErrTranslateFailed, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		if result.Error == "" {
+			result.Error = res.Status
+		}
+		return "", fmt.Errorf("%w: libretranslate: %s", types.ErrTranslateFailed, result.Error)
+	}
+	return result.TranslatedText, nil
+}
@@ -0,0 +1,191 @@
+// Package translate provides machine translation through pluggable providers.
+//
+// Providers are registered by name and configured per language pair, so for
+// example Japanese to Chinese can use a different service than English to Japanese.
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"langlearner1/backend/types"
+)
+
+// Translator translates text between two languages given as BCP 47 codes
+type Translator interface {
+	// Name returns the registry name of the provider
+	Name() string
+	// Translate returns the translation of text from source to target
+	Translate(ctx context.Context, text string, source string, target string) (string, error)
+}
+
+// Config configures a provider
+type Config struct {
+	// Provider is the registry name of the provider
+	Provider string `json:"provider"`
+	// Endpoint is the base URL of the provider's API
+	Endpoint string `json:"endpoint"`
+	APIKey   string `json:"api_key"`
+	// Timeout limits a single request in seconds, 0 means DefaultTimeout
+	Timeout int `json:"timeout"`
+	// Languages maps BCP 47 codes to the codes the provider expects, such as
+	// "zh-CN" to "zh-Hans", overriding the provider's own mapping
+	Languages map[string]string `json:"languages"`
+}
+
+// DefaultTimeout limits a request when the config sets no timeout
+const DefaultTimeout = 15 * time.Second
+
+// Any matches every language in a PairConfig
+const Any = "*"
+
+// PairConfig assigns a provider to a language pair, Any matches every language
+type PairConfig struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Config
+}
+
+// registry maps provider names to translator constructors
+var registry = map[string]func(Config) (Translator, error){
+	"libretranslate": func(config Config) (Translator, error) { return NewLibreTranslate(config) },
+}
+
+// Register makes a provider available under the given name
+func Register(name string, factory func(Config) (Translator, error)) {
+	registry[name] = factory
+}
+
+// New creates the translator configured by config
+func New(config Config) (Translator, error) {
+	factory, ok := registry[config.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", types.ErrUnknownTranslator, config.Provider)
+	}
+	return factory(config)
+}
+
+// Names returns the registered provider names in sorted order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeLanguage validates a BCP 47 language code and returns its canonical form, such as "zh-CN" for "zh_cn"
+func NormalizeLanguage(code string) (string, error) {
+	tag, err := language.Parse(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if err != nil || tag == language.Und {
+		return "", fmt.Errorf("%w: %q", types.ErrInvalidLanguage, code)
+	}
+	return tag.String(), nil
+}
+
+// pairTranslator is a translator configured for a language pair
+type pairTranslator struct {
+	source, target string
+	translator     Translator
+	timeout        time.Duration
+}
+
+// Pairs selects the translator configured for a language pair
+type Pairs struct {
+	pairs []pairTranslator
+}
+
+// NewPairs creates the translators of the given pair configs
+func NewPairs(configs []PairConfig) (*Pairs, error) {
+	p := &Pairs{}
+	for _, config := range configs {
+		source, target := config.Source, config.Target
+		var err error
+		if source != Any {
+			if source, err = NormalizeLanguage(source); err != nil {
+				return nil, err
+			}
+		}
+		if target != Any {
+			if target, err = NormalizeLanguage(target); err != nil {
+				return nil, err
+			}
+		}
+		translator, err := New(config.Config)
+		if err != nil {
+			return nil, err
+		}
+		timeout := time.Duration(config.Timeout) * time.Second
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		p.pairs = append(p.pairs, pairTranslator{source: source, target: target, translator: translator, timeout: timeout})
+	}
+	return p, nil
+}
+
+// LoadPairs reads pair configs from a JSON file, a missing file configures no translators
+func LoadPairs(path string) (*Pairs, error) {
+	configs, err := LoadPairConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPairs(configs)
+}
+
+// LoadPairConfigs reads the pair configs of a JSON file, a missing file has none
+func LoadPairConfigs(path string) ([]PairConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var configs []PairConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", types.ErrProviderConfig, path, err)
+	}
+	return configs, nil
+}
+
+// For returns the translator for a pair of canonical language codes and its
+// request timeout. An exact pair wins over wildcards, earlier configs win over later ones.
+func (p *Pairs) For(source string, target string) (Translator, time.Duration, error) {
+	var best *pairTranslator
+	bestScore := -1
+	for i := range p.pairs {
+		pair := &p.pairs[i]
+		score := 0
+		switch pair.source {
+		case source:
+			score += 2
+		case Any:
+		default:
+			continue
+		}
+		switch pair.target {
+		case target:
+			score++
+		case Any:
+		default:
+			continue
+		}
+		if score > bestScore {
+			best, bestScore = pair, score
+		}
+	}
+	if best == nil {
+		return nil, 0, types.ErrNoTranslator
+	}
+	return best.translator, best.timeout, nil
+}
@@ -0,0 +1,143 @@
+package translate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/translate/translatetest"
+	"langlearner1/backend/types"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"ja":      "ja",
+		"zh_cn":   "zh-CN",
+		" EN-us ": "en-US",
+	}
+	for code, want := range tests {
+		got, err := NormalizeLanguage(code)
+		require.NoError(t, err, code)
+		assert.Equal(t, want, got)
+	}
+	for _, code := range []string{"", "und", "not a language"} {
+		_, err := NormalizeLanguage(code)
+		assert.ErrorIs(t, err, types.ErrInvalidLanguage, code)
+	}
+}
+
+func TestLibreTranslate(t *testing.T) {
+	server := translatetest.NewServer(map[string]string{"ja:en:いぬ": "dog"})
+	defer server.Close()
+
+	translator, err := New(Config{Provider: "libretranslate", Endpoint: server.URL + "/"})
+	require.NoError(t, err)
+	assert.Equal(t, "libretranslate", translator.Name())
+
+	result, err := translator.Translate(context.Background(), "いぬ", "ja", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "dog", result)
+	result, err = translator.Translate(context.Background(), "ねこ", "ja", "zh-CN")
+	require.NoError(t, err)
+	assert.Equal(t, "[zh] ねこ", result, "LibreTranslate codes are sent")
+	assert.Equal(t, 2, server.Requests())
+
+	// Errors reported by the server are passed on
+	_, err = translator.Translate(context.Background(), "", "ja", "en")
+	assert.ErrorIs(t, err, types.ErrTranslateFailed)
+	assert.ErrorContains(t, err, "invalid request")
+
+	_, err = New(Config{Provider: "libretranslate"})
+	assert.ErrorIs(t, err, types.ErrProviderEndpoint)
+	_, err = New(Config{Provider: "babelfish"})
+	assert.ErrorIs(t, err, types.ErrUnknownTranslator)
+}
+
+func TestLibreTranslateLanguages(t *testing.T) {
+	translator, err := NewLibreTranslate(Config{Endpoint: "http://localhost:5000", Languages: map[string]string{"zh-CN": "zh-Hans"}})
+	require.NoError(t, err)
+	tests := map[string]string{
+		"ja":      "ja",
+		"en-US":   "en",
+		"pt-BR":   "pt",
+		"zh":      "zh",
+		"zh-CN":   "zh-Hans",
+		"zh-Hans": "zh",
+		"zh-TW":   "zt",
+		"zh-Hant": "zt",
+		"zh-HK":   "zt",
+	}
+	for code, want := range tests {
+		assert.Equal(t, want, translator.language(code), code)
+	}
+}
+
+// namedTranslator is a translator that only reports its name
+type namedTranslator string
+
+func (n namedTranslator) Name() string { return string(n) }
+
+func (n namedTranslator) Translate(ctx context.Context, text string, source string, target string) (string, error) {
+	return string(n), nil
+}
+
+func TestPairs(t *testing.T) {
+	for _, name := range []string{"exact", "source", "any"} {
+		name := name
+		Register(name, func(Config) (Translator, error) { return namedTranslator(name), nil })
+	}
+	assert.Subset(t, Names(), []string{"any", "exact", "libretranslate", "source"})
+
+	pairs, err := NewPairs([]PairConfig{
+		{Source: Any, Target: Any, Config: Config{Provider: "any"}},
+		{Source: "ja", Target: Any, Config: Config{Provider: "source", Timeout: 3}},
+		{Source: "ja", Target: "zh_CN", Config: Config{Provider: "exact"}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		source, target, want string
+	}{
+		{"ja", "zh-CN", "exact"},
+		{"ja", "en", "source"},
+		{"en", "ja", "any"},
+	}
+	for _, tt := range tests {
+		translator, _, err := pairs.For(tt.source, tt.target)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, translator.Name(), tt.source+"->"+tt.target)
+	}
+	_, timeout, _ := pairs.For("ja", "en")
+	assert.Equal(t, 3*time.Second, timeout)
+	_, timeout, _ = pairs.For("en", "ja")
+	assert.Equal(t, DefaultTimeout, timeout)
+
+	_, _, err = (&Pairs{}).For("ja", "en")
+	assert.Equal(t, types.ErrNoTranslator, err)
+	_, err = NewPairs([]PairConfig{{Source: "??", Target: Any, Config: Config{Provider: "any"}}})
+	assert.ErrorIs(t, err, types.ErrInvalidLanguage)
+}
+
+func TestLoadPairs(t *testing.T) {
+	dir := t.TempDir()
+	pairs, err := LoadPairs(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	_, _, err = pairs.For("ja", "en")
+	assert.Equal(t, types.ErrNoTranslator, err)
+
+	path := filepath.Join(dir, "translate.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"source": "ja", "target": "en", "provider": "libretranslate", "endpoint": "http://localhost:5000"}]`), 0644))
+	pairs, err = LoadPairs(path)
+	require.NoError(t, err)
+	translator, _, err := pairs.For("ja", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "libretranslate", translator.Name())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0644))
+	_, err = LoadPairs(path)
+	assert.Error(t, err)
+}
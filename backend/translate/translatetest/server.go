@@ -0,0 +1,55 @@
+// Package translatetest provides a fake translation server for tests that run offline.
+package translatetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+)
+
+// Server is a fake LibreTranslate server. Known texts are translated from its
+// dictionary, other texts come back as "[target] text".
+type Server struct {
+	*httptest.Server
+	// Dictionary maps "source:target:text" to its translation
+	Dictionary map[string]string
+	requests   atomic.Int64
+}
+
+// NewServer starts a fake server, close it with Close
+func NewServer(dictionary map[string]string) *Server {
+	s := &Server{Dictionary: dictionary}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Requests returns the number of translations requested so far
+func (s *Server) Requests() int {
+	return int(s.requests.Load())
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost || r.URL.Path != "/translate" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+		return
+	}
+	var req struct {
+		Q      string `json:"q"`
+		Source string `json:"source"`
+		Target string `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Q == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request"})
+		return
+	}
+	s.requests.Add(1)
+	translated, ok := s.Dictionary[req.Source+":"+req.Target+":"+req.Q]
+	if !ok {
+		translated = "[" + req.Target + "] " + req.Q
+	}
+	json.NewEncoder(w).Encode(map[string]string{"translatedText": translated})
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"gorm.io/gorm"
+)
+
+// Roles of an asset attached to a note
+const (
+	AssetRoleFrontAudio = "front_audio"
+	AssetRoleBackAudio  = "back_audio"
+	AssetRoleImage      = "image"
+)
+
+// AssetURLPrefix is the path under which the asset server serves assets by hash
+const AssetURLPrefix = "/media/"
+
+// Asset is a media file kept in the content-addressed asset store
+type Asset struct {
+	ID int `json:"id" gorm:"primaryKey"`
+	// Hash is the hex SHA-256 of the content, identical files share one asset
+	Hash string `json:"hash" gorm:"type:char(64);uniqueIndex"`
+	// Name is the file name the asset was first added with
+	Name      string `json:"name" gorm:"type:varchar(255)"`
+	MimeType  string `json:"mime_type" gorm:"type:varchar(100)"`
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime"`
+	// URL is where the frontend loads the asset from
+	URL string `json:"url" gorm:"-"`
+}
+
+// TableName specifies the table name for Asset model
+func (Asset) TableName() string {
+	return "assets"
+}
+
+// AfterFind fills in the URL of assets read from the database
+func (a *Asset) AfterFind(tx *gorm.DB) error {
+	a.URL = AssetURLPrefix + a.Hash
+	return nil
+}
+
+// NoteAsset links an asset to a note in a role. A note has at most one
+// asset per audio role and any number of images.
+type NoteAsset struct {
+	NoteID    int    `json:"note_id" gorm:"primaryKey"`
+	Role      string `json:"role" gorm:"primaryKey;type:varchar(20)"`
+	AssetID   int    `json:"asset_id" gorm:"primaryKey;index"`
+	Asset     Asset  `json:"asset"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for NoteAsset model
+func (NoteAsset) TableName() string {
+	return "note_assets"
+}
+
+// AssetCleanup reports what removing orphaned assets deleted
+type AssetCleanup struct {
+	Assets int `json:"assets"`
+	Files  int `json:"files"`
+	// Bytes is the disk space freed
+	Bytes int64 `json:"bytes"`
+}
+
+// AssetServiceIf defines the interface for note media
+type AssetServiceIf interface {
+	// Attach copies a local file into the asset store and links it to a note in a role
+	Attach(noteID int, path string, role string) JSResp
+	// Detach unlinks an asset from a note and removes it once no note uses it
+	Detach(noteID int, assetID int, role string) JSResp
+	// List returns the assets of a note
+	List(noteID int) JSResp
+	// CleanOrphans removes assets no note uses and files without an asset
+	CleanOrphans() JSResp
+}
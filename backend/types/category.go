@@ -0,0 +1,38 @@
+package types
+
+// Category represents a note category, categories can be nested through ParentID
+type Category struct {
+	ID       int        `json:"id" gorm:"primaryKey"`
+	Name     string     `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_categories_parent_name"`
+	ParentID *int       `json:"parent_id" gorm:"uniqueIndex:idx_categories_parent_name"`
+	Parent   *Category  `json:"-" gorm:"constraint:OnDelete:RESTRICT"`
+	Children []Category `json:"children,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for Category model
+func (Category) TableName() string {
+	return "categories"
+}
+
+// CategoryList represents a paginated list of categories
+type CategoryList struct {
+	Total       int        `json:"total"`
+	TotalPages  int        `json:"total_pages"`
+	CurrentPage int        `json:"current_page"`
+	PageSize    int        `json:"page_size"`
+	Data        []Category `json:"data"`
+}
+
+// CategoryServiceIf defines the interface for category operations
+type CategoryServiceIf interface {
+	// List returns a paginated flat list of categories
+	List(page, pageSize int, keyword string) JSResp
+	// Tree returns all categories nested under their parents
+	Tree() JSResp
+	// Create creates a new category, parentID 0 creates a top level category
+	Create(name string, parentID int) JSResp
+	// Update renames a category and moves it under another parent
+	Update(id int, name string, parentID int) JSResp
+	// Delete deletes a category that has no children and no notes
+	Delete(id int) JSResp
+}
@@ -0,0 +1,32 @@
+package types
+
+// The deck created for notes that were written before decks existed
+const (
+	DefaultDeckName   = "Default"
+	DefaultDeckSource = "ja"
+	DefaultDeckTarget = "zh-CN"
+)
+
+// Deck groups notes that share a language pair
+type Deck struct {
+	ID   int    `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	// Source is the language of the note fronts and Target the language of the
+	// backs, both canonical BCP 47 codes
+	Source    string `json:"source" gorm:"type:varchar(35);not null"`
+	Target    string `json:"target" gorm:"type:varchar(35);not null"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt int64  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for Deck model
+func (Deck) TableName() string {
+	return "decks"
+}
+
+// DeckPayload carries the editable fields of a deck from the frontend
+type DeckPayload struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
@@ -1,18 +1,190 @@
 package types
 
-import "errors"
+import (
+	"sort"
+	"strings"
 
-// Common errors for tag operations
+	"langlearner1/backend/i18n"
+)
+
+// Error is an error the frontend can tell apart by its Code, which stays the
+// same when the message changes. Key names the message in the i18n catalogs,
+// Message is the English text used in logs and by errors.Is.
+type Error struct {
+	Code    string
+	Key     string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// errorsByCode holds the declared errors by their code
+var errorsByCode = map[string]*Error{}
+
+// newError declares an error with a code such as "TAG_NOT_FOUND", its key is
+// the code in lower case under "error.", like "error.tag_not_found"
+func newError(code string, message string) *Error {
+	if _, ok := errorsByCode[code]; ok {
+		panic("types: duplicate error code " + code)
+	}
+	e := &Error{Code: code, Key: "error." + strings.ToLower(code), Message: message}
+	errorsByCode[code] = e
+	return e
+}
+
+// Errors returns the declared errors ordered by code
+func Errors() []*Error {
+	list := make([]*Error, 0, len(errorsByCode))
+	for _, e := range errorsByCode {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// Localize returns the message of err in the current locale. The messages of
+// the declared errors in it are translated, the details around them such as a
+// quoted value are kept.
+func Localize(err error) string {
+	msg := err.Error()
+	for _, e := range declared(err, nil) {
+		msg = strings.ReplaceAll(msg, e.Message, i18n.T(e.Key))
+	}
+	return msg
+}
+
+// declared appends the declared errors in the tree of err to list, once each
+func declared(err error, list []*Error) []*Error {
+	if e, ok := err.(*Error); ok {
+		for _, seen := range list {
+			if seen == e {
+				return list
+			}
+		}
+		return append(list, e)
+	}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := wrapped.Unwrap(); inner != nil {
+			list = declared(inner, list)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			list = declared(inner, list)
+		}
+	}
+	return list
+}
+
+// FieldError reports an invalid field of a payload, Err tells what is wrong with it
+type FieldError struct {
+	// Field is the JSON name of the field
+	Field string
+	Err   error
+}
+
+// NewFieldError returns err as the problem with field
+func NewFieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ErrInternal is reported for failures without a declared error, such as a
+// database or file system error
+var ErrInternal = newError("INTERNAL", "internal error")
+
+// Errors reported by the services, wrapped errors keep the message as prefix
 var (
-	ErrTagNotFound  = errors.New("tag not found")
-	ErrTagNameEmpty = errors.New("tag name cannot be empty")
-	ErrTagExists    = errors.New("tag already exists")
-	ErrTagInUse     = errors.New("tag is in use")
+	ErrTagNotFound  = newError("TAG_NOT_FOUND", "tag not found")
+	ErrTagNameEmpty = newError("TAG_NAME_EMPTY", "tag name cannot be empty")
+	ErrTagExists    = newError("TAG_EXISTS", "tag already exists")
+	ErrTagInUse     = newError("TAG_IN_USE", "tag is in use")
+
+	ErrCategoryNotFound  = newError("CATEGORY_NOT_FOUND", "category not found")
+	ErrCategoryNameEmpty = newError("CATEGORY_NAME_EMPTY", "category name cannot be empty")
+	ErrCategoryExists    = newError("CATEGORY_EXISTS", "category already exists")
+	ErrCategoryInUse     = newError("CATEGORY_IN_USE", "category is in use")
+	ErrCategoryCycle     = newError("CATEGORY_CYCLE", "category cannot be moved under itself")
+
+	ErrDeckNotFound  = newError("DECK_NOT_FOUND", "deck not found")
+	ErrDeckNameEmpty = newError("DECK_NAME_EMPTY", "deck name cannot be empty")
+	ErrDeckExists    = newError("DECK_EXISTS", "deck already exists")
+	ErrDeckInUse     = newError("DECK_IN_USE", "deck still has notes")
+
+	ErrInvalidRequest = newError("INVALID_REQUEST", "invalid request")
+	ErrInvalidToken   = newError("INVALID_TOKEN", "missing or invalid token")
+	ErrInvalidPageNum = newError("INVALID_PAGE_NUM", "invalid page number")
+	ErrInvalidCursor  = newError("INVALID_CURSOR", "invalid page cursor")
+
+	ErrNoteNotFound   = newError("NOTE_NOT_FOUND", "Note not found")
+	ErrNoteNameEmpty  = newError("NOTE_NAME_EMPTY", "Note name cannot be empty")
+	ErrNoteExists     = newError("NOTE_EXISTS", "Note already exists")
+	ErrNoteInUse      = newError("NOTE_IN_USE", "Note is in use")
+	ErrNoteFrontEmpty = newError("NOTE_FRONT_EMPTY", "note front cannot be empty")
+
+	ErrSessionNotFound = newError("SESSION_NOT_FOUND", "practice session not found")
+	ErrSessionEmpty    = newError("SESSION_EMPTY", "no notes match the practice filter")
+	ErrSessionFinished = newError("SESSION_FINISHED", "practice session is already finished")
+	ErrInvalidOutcome  = newError("INVALID_OUTCOME", "invalid practice outcome")
+	ErrUnexpectedCard  = newError("UNEXPECTED_CARD", "note is not the current card of the session")
+
+	ErrInvalidGrade     = newError("INVALID_GRADE", "invalid review grade")
+	ErrUnknownAlgorithm = newError("UNKNOWN_ALGORITHM", "unknown scheduling algorithm")
+
+	ErrAssetNotFound    = newError("ASSET_NOT_FOUND", "asset not found")
+	ErrInvalidAssetRole = newError("INVALID_ASSET_ROLE", "invalid asset role")
+	ErrAssetType        = newError("ASSET_TYPE", "file type does not match the asset role")
+
+	ErrUnknownTranslator = newError("UNKNOWN_TRANSLATOR", "unknown translation provider")
+	ErrNoTranslator      = newError("NO_TRANSLATOR", "no translation provider configured for this language pair")
+	ErrInvalidLanguage   = newError("INVALID_LANGUAGE", "invalid language code")
+	ErrTextEmpty         = newError("TEXT_EMPTY", "text to translate cannot be empty")
+	ErrTranslateFailed   = newError("TRANSLATE_FAILED", "translation provider failed")
+
+	ErrProviderEndpoint = newError("PROVIDER_ENDPOINT", "provider needs an endpoint")
+	ErrProviderCommand  = newError("PROVIDER_COMMAND", "provider needs a command")
+	ErrProviderConfig   = newError("PROVIDER_CONFIG", "invalid provider config")
+
+	ErrUnknownSynthesizer = newError("UNKNOWN_SYNTHESIZER", "unknown speech synthesis backend")
+	ErrNoSynthesizer      = newError("NO_SYNTHESIZER", "no speech synthesis backend configured")
+	ErrInvalidSide        = newError("INVALID_SIDE", "note side must be front or back")
+	ErrSpeechTextEmpty    = newError("SPEECH_TEXT_EMPTY", "note side has no text to speak")
+	ErrAnswerTextEmpty    = newError("ANSWER_TEXT_EMPTY", "note side has no text to check the answer against")
+	ErrSpeechFailed       = newError("SPEECH_FAILED", "speech synthesis failed")
+
+	ErrJobNotFound    = newError("JOB_NOT_FOUND", "job not found")
+	ErrJobFinished    = newError("JOB_FINISHED", "job has already finished")
+	ErrUnknownJobKind = newError("UNKNOWN_JOB_KIND", "unknown job kind")
+	ErrJobInterrupted = newError("JOB_INTERRUPTED", "job was interrupted and has to be started again")
+
+	ErrSchemaTooNew = newError("SCHEMA_TOO_NEW", "database was created by a newer version of the app")
+
+	ErrProfileNotFound  = newError("PROFILE_NOT_FOUND", "profile not found")
+	ErrProfileNameEmpty = newError("PROFILE_NAME_EMPTY", "profile name cannot be empty")
+	ErrProfileExists    = newError("PROFILE_EXISTS", "profile already exists")
+	ErrProfileActive    = newError("PROFILE_ACTIVE", "the active profile cannot be deleted")
+
+	ErrSettingsTooNew = newError("SETTINGS_TOO_NEW", "settings were saved by a newer version of the app")
+	ErrInvalidTimeout = newError("INVALID_TIMEOUT", "timeout must be between 0.5 and 60 seconds")
+	ErrUnknownLocale  = newError("UNKNOWN_LOCALE", "unsupported language")
+	ErrDataDirEmpty   = newError("DATA_DIR_EMPTY", "data directory cannot be empty")
 
-	ErrInvalidPageNum = errors.New("invalid page number")
+	ErrInvalidDelimiter = newError("INVALID_DELIMITER", "delimiter must be a single character")
+	ErrUnknownColumn    = newError("UNKNOWN_COLUMN", "unknown column")
+	ErrUnknownField     = newError("UNKNOWN_FIELD", "unknown note field")
+	ErrUnknownEncoding  = newError("UNKNOWN_ENCODING", "unknown encoding")
 
-	ErrNoteNotFound  = errors.New("Note not found")
-	ErrNoteNameEmpty = errors.New("Note name cannot be empty")
-	ErrNoteExists    = errors.New("Note already exists")
-	ErrNoteInUse     = errors.New("Note is in use")
+	ErrAnkiNoCollection  = newError("ANKI_NO_COLLECTION", "no collection found in the Anki package")
+	ErrAnkiCompressed    = newError("ANKI_COMPRESSED", `compressed collection.anki21b is not supported, export with "Support older Anki versions" enabled`)
+	ErrAnkiInvalid       = newError("ANKI_INVALID", "invalid Anki package")
+	ErrAnkiMediaNotFound = newError("ANKI_MEDIA_NOT_FOUND", "media file not found in the Anki package")
 )
@@ -0,0 +1,115 @@
+package types
+
+// ImportStats counts the rows written by a storage import
+type ImportStats struct {
+	Created           int `json:"created"`
+	Updated           int `json:"updated"`
+	TagsCreated       int `json:"tags_created"`
+	CategoriesCreated int `json:"categories_created"`
+}
+
+// ImportError describes an input record that could not be imported
+type ImportError struct {
+	// Row is the 1-based record number in the input, or the line number for
+	// text files, 0 when not applicable
+	Row int `json:"row"`
+	// Ref identifies the record in the source, such as an Anki note GUID
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarizes an import, in dry-run mode nothing is written
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	// Total is the number of records read from the input
+	Total int `json:"total"`
+	ImportStats
+	Skipped int `json:"skipped"`
+	// MediaCopied counts the media files referenced by the notes that were added to the asset store
+	MediaCopied int           `json:"media_copied"`
+	Errors      []ImportError `json:"errors"`
+}
+
+// AnkiImportOptions controls how Anki notes are mapped onto notes
+type AnkiImportOptions struct {
+	// FrontField and BackField name the Anki fields used for the note sides,
+	// empty means the first and second field of the note type
+	FrontField string `json:"front_field"`
+	BackField  string `json:"back_field"`
+	// DeckID puts new notes into a deck, 0 means the default deck. Notes that
+	// were imported before stay in their deck.
+	DeckID int `json:"deck_id"`
+	// CategoryID puts every imported note into a category, 0 leaves them as they are
+	CategoryID int  `json:"category_id"`
+	DryRun     bool `json:"dry_run"`
+}
+
+// AnkiExportOptions selects the notes written to an .apkg file
+type AnkiExportOptions struct {
+	// DeckName is the name of the deck in Anki, empty means "LangLearner"
+	DeckName string     `json:"deck_name"`
+	Filter   NoteFilter `json:"filter"`
+}
+
+// ExportReport summarizes an export
+type ExportReport struct {
+	Notes int `json:"notes"`
+	Media int `json:"media"`
+	// MissingMedia lists referenced media files that were not found and left out
+	MissingMedia []string `json:"missing_media"`
+}
+
+// AnkiServiceIf defines the interface for Anki deck import and export
+type AnkiServiceIf interface {
+	// Import reads an .apkg file into notes, tags and media
+	Import(path string, options AnkiImportOptions) JSResp
+	// Export writes the notes matching the options and their media to an .apkg file
+	Export(path string, options AnkiExportOptions) JSResp
+}
+
+// CSVColumns maps note fields to columns. Each entry is a header name, or a
+// 1-based column number for files without a header, empty leaves the field unmapped.
+type CSVColumns struct {
+	Title string `json:"title"`
+	// Front and Back default to the first and second column
+	Front string `json:"front"`
+	Back  string `json:"back"`
+	// Category holds a category path such as "Textbook::Lesson 1", missing categories are created
+	Category string `json:"category"`
+	Tags     string `json:"tags"`
+}
+
+// CSVImportOptions controls how a CSV or TSV file is read into notes
+type CSVImportOptions struct {
+	// Delimiter is a single character or "tab", empty picks a tab for .tsv files and a comma otherwise
+	Delimiter string `json:"delimiter"`
+	// Encoding is an encoding label such as "utf-8", "shift_jis", "gbk" or "utf-16", empty means UTF-8
+	Encoding  string     `json:"encoding"`
+	HasHeader bool       `json:"has_header"`
+	Columns   CSVColumns `json:"columns"`
+	// TagSeparator splits the tags column, empty means ";"
+	TagSeparator string `json:"tag_separator"`
+	// DeckID puts new notes into a deck, 0 means the default deck
+	DeckID int `json:"deck_id"`
+	// CategoryID is used for rows without a category, 0 leaves them uncategorized
+	CategoryID int  `json:"category_id"`
+	DryRun     bool `json:"dry_run"`
+}
+
+// CSVExportOptions controls how notes are written to a CSV or TSV file
+type CSVExportOptions struct {
+	Delimiter string `json:"delimiter"`
+	Encoding  string `json:"encoding"`
+	// Header writes a first row with the column names
+	Header       bool       `json:"header"`
+	TagSeparator string     `json:"tag_separator"`
+	Filter       NoteFilter `json:"filter"`
+}
+
+// CSVServiceIf defines the interface for CSV and TSV import and export
+type CSVServiceIf interface {
+	// Import reads notes from a CSV or TSV file in a single transaction
+	Import(path string, options CSVImportOptions) JSResp
+	// Export writes the notes matching the filter to a CSV or TSV file
+	Export(path string, options CSVExportOptions) JSResp
+}
@@ -0,0 +1,96 @@
+package types
+
+import "encoding/json"
+
+// Job states, queued and running jobs are resumed when the app starts except
+// for CSV imports, which are failed when interrupted while running
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job kinds
+const (
+	JobCSVImport     = "csv_import"
+	JobAnkiImport    = "anki_import"
+	JobFillBacks     = "fill_backs"
+	JobFillSpeech    = "fill_speech"
+	JobFillReadings  = "fill_readings"
+	JobRebuildSearch = "rebuild_search"
+)
+
+// JobEvent is emitted to the frontend with the Job whenever a job changes state or makes progress
+const JobEvent = "job:update"
+
+// Job is a long-running operation executed in the background
+type Job struct {
+	ID     int    `json:"id" gorm:"primaryKey"`
+	Kind   string `json:"kind" gorm:"type:varchar(50);not null"`
+	Status string `json:"status" gorm:"type:varchar(20);index;not null"`
+	// Params holds the JSON arguments of the job
+	Params json.RawMessage `json:"params" gorm:"type:text"`
+	// Progress counts the processed items out of Total, Total is 0 when unknown
+	Progress int `json:"progress"`
+	Total    int `json:"total"`
+	// Result holds the JSON report of a finished job
+	Result     json.RawMessage `json:"result" gorm:"type:text"`
+	Error      string          `json:"error" gorm:"type:text"`
+	CreatedAt  int64           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  int64           `json:"updated_at" gorm:"autoUpdateTime"`
+	StartedAt  int64           `json:"started_at"`
+	FinishedAt int64           `json:"finished_at"`
+}
+
+// TableName specifies the table name for Job model
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Finished reports whether the job reached a final state
+func (j *Job) Finished() bool {
+	return j.Status == JobDone || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// BatchError describes a note a batch operation failed on
+type BatchError struct {
+	NoteID  int    `json:"note_id"`
+	Message string `json:"message"`
+}
+
+// BatchReport summarizes a batch operation over a group of notes
+type BatchReport struct {
+	Total   int `json:"total"`
+	Updated int `json:"updated"`
+	// Skipped counts notes that needed no change or had nothing to work on
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Errors  []BatchError `json:"errors"`
+}
+
+// JobServiceIf defines the interface for background jobs
+type JobServiceIf interface {
+	// List returns the most recent jobs, optionally only those in a status
+	List(status string) JSResp
+	// Get returns a job
+	Get(id int) JSResp
+	// Cancel stops a queued or running job
+	Cancel(id int) JSResp
+	// ClearFinished deletes the jobs that are done, failed or cancelled
+	ClearFinished() JSResp
+	// ImportCSV queues a CSV or TSV import
+	ImportCSV(path string, options CSVImportOptions) JSResp
+	// ImportAnki queues an .apkg import
+	ImportAnki(path string, options AnkiImportOptions) JSResp
+	// FillBacks queues translating the front into the empty back of every note with the tag
+	FillBacks(tagID int, source string, target string) JSResp
+	// FillSpeech queues generating the missing audio of a side for every note with the tag
+	FillSpeech(tagID int, side string, voice string) JSResp
+	// FillReadings queues regenerating the furigana and romaji of the notes in a
+	// deck, or of all notes for deck 0
+	FillReadings(deckID int) JSResp
+	// RebuildSearchIndex queues regenerating the full-text index
+	RebuildSearchIndex() JSResp
+}
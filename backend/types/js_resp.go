@@ -1,7 +1,89 @@
 package types
 
+import "errors"
+
 type JSResp struct {
 	Success int    `json:"success"` // 1:success;
 	Msg     string `json:"msg"`
-	Data    any    `json:"data,omitempty"`
+	// Code identifies the error of a failed response and Key names its message
+	// in the translation catalogs, see Error
+	Code string `json:"code,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// Details lists the invalid fields of a rejected payload
+	Details []ErrorDetail `json:"details,omitempty"`
+	Data    any           `json:"data,omitempty"`
+}
+
+// ErrorDetail describes an invalid field of a payload
+type ErrorDetail struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// SetError reports err as the failure of the response with its message in the
+// current locale. Errors without a declared Error are reported with the code
+// of ErrInternal.
+func (r *JSResp) SetError(err error) {
+	r.Success = 0
+	r.Msg = Localize(err)
+	e := errorOf(err)
+	r.Code, r.Key = e.Code, e.Key
+	r.Details = nil
+	collectDetails(err, &r.Details)
+}
+
+// Err returns the error of a failed response with its localized message, nil
+// on success. The declared error of the code stays matchable with errors.Is.
+func (r JSResp) Err() error {
+	if r.Success == 1 {
+		return nil
+	}
+	if e, ok := errorsByCode[r.Code]; ok && e != ErrInternal {
+		return &respError{msg: r.Msg, err: e}
+	}
+	return errors.New(r.Msg)
+}
+
+// respError keeps the message of a response together with its declared error
+type respError struct {
+	msg string
+	err *Error
+}
+
+func (e *respError) Error() string {
+	return e.msg
+}
+
+func (e *respError) Unwrap() error {
+	return e.err
+}
+
+// errorOf returns the declared error err wraps, ErrInternal if there is none
+func errorOf(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal
+}
+
+// collectDetails appends the field errors in the tree of err to details
+func collectDetails(err error, details *[]ErrorDetail) {
+	if field, ok := err.(*FieldError); ok {
+		e := errorOf(field.Err)
+		*details = append(*details, ErrorDetail{Field: field.Field, Code: e.Code, Key: e.Key, Message: Localize(field.Err)})
+		return
+	}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := wrapped.Unwrap(); inner != nil {
+			collectDetails(inner, details)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			collectDetails(inner, details)
+		}
+	}
 }
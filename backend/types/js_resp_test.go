@@ -0,0 +1,49 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetError(t *testing.T) {
+	var resp JSResp
+	resp.SetError(fmt.Errorf("%w: %q", ErrInvalidLanguage, "xx"))
+	assert.Equal(t, `invalid language code: "xx"`, resp.Msg)
+	assert.Equal(t, "INVALID_LANGUAGE", resp.Code)
+	assert.Equal(t, "error.invalid_language", resp.Key)
+	assert.Empty(t, resp.Details)
+	assert.ErrorIs(t, resp.Err(), ErrInvalidLanguage)
+	assert.Equal(t, resp.Msg, resp.Err().Error())
+
+	resp.SetError(errors.Join(
+		NewFieldError("name", ErrDeckNameEmpty),
+		NewFieldError("target", fmt.Errorf("%w: %q", ErrInvalidLanguage, "xx")),
+	))
+	assert.Equal(t, "DECK_NAME_EMPTY", resp.Code, "the first error gives the code")
+	require.Len(t, resp.Details, 2)
+	assert.Equal(t, ErrorDetail{Field: "name", Code: "DECK_NAME_EMPTY", Key: "error.deck_name_empty", Message: "deck name cannot be empty"}, resp.Details[0])
+	assert.Equal(t, "target", resp.Details[1].Field)
+	assert.Equal(t, "INVALID_LANGUAGE", resp.Details[1].Code)
+
+	resp.SetError(errors.New("disk I/O error"))
+	assert.Equal(t, "disk I/O error", resp.Msg)
+	assert.Equal(t, ErrInternal.Code, resp.Code)
+	assert.Empty(t, resp.Details)
+	assert.False(t, errors.Is(resp.Err(), ErrInternal), "internal errors keep only their message")
+
+	assert.NoError(t, JSResp{Success: 1}.Err())
+}
+
+func TestErrors(t *testing.T) {
+	keys := map[string]bool{}
+	for _, e := range Errors() {
+		assert.Regexp(t, `^[A-Z][A-Z0-9_]*$`, e.Code)
+		assert.False(t, keys[e.Key], "duplicate key %s", e.Key)
+		keys[e.Key] = true
+	}
+	assert.True(t, keys[ErrTagExists.Key])
+}
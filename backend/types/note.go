@@ -2,13 +2,23 @@ package types
 
 // Note represents a note entity
 type Note struct {
-	ID          int    `json:"id" gorm:"primaryKey"`
-	Front       string `json:"front" gorm:"type:text;not null"`
-	Back        string `json:"back" gorm:"type:text"`
-	Category    string `json:"category" gorm:"type:varchar(100)"`
-	Tags        []Tag  `json:"tags" gorm:"many2many:note_tags"`
-	CreatedAt   int64  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   int64  `json:"updated_at" gorm:"autoUpdateTime"`
+	ID    int    `json:"id" gorm:"primaryKey"`
+	GUID  string `json:"guid" gorm:"type:varchar(64);index"` // identifies imported notes so re-imports update them
+	Title string `json:"title" gorm:"type:varchar(255)"`
+	Front string `json:"front" gorm:"type:text;not null"`
+	Back  string `json:"back" gorm:"type:text"`
+	// Furigana and Romaji are the readings of the front, generated when the
+	// front of a note in a Japanese deck changes
+	Furigana   string      `json:"furigana" gorm:"type:text"`
+	Romaji     string      `json:"romaji" gorm:"type:text"`
+	DeckID     int         `json:"deck_id" gorm:"index;not null"`
+	Deck       *Deck       `json:"deck,omitempty" gorm:"constraint:OnDelete:RESTRICT"`
+	CategoryID *int        `json:"category_id" gorm:"index"`
+	Category   *Category   `json:"category,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	Tags       []Tag       `json:"tags" gorm:"many2many:note_tags"`
+	Assets     []NoteAsset `json:"assets"`
+	CreatedAt  int64       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  int64       `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName specifies the table name for Note model
@@ -24,3 +34,54 @@ type NoteList struct {
 	PageSize    int    `json:"page_size"`
 	Data        []Note `json:"data"`
 }
+
+// NotePayload carries the editable fields of a note from the frontend
+type NotePayload struct {
+	Title string `json:"title"`
+	Front string `json:"front"`
+	Back  string `json:"back"`
+	// DeckID puts the note into a deck, 0 uses the default deck for a new note
+	// and keeps the deck of an existing one
+	DeckID int `json:"deck_id"`
+	// CategoryID links the note to a category, 0 leaves it uncategorized
+	CategoryID int   `json:"category_id"`
+	TagIDs     []int `json:"tag_ids"`
+}
+
+// NoteFilter narrows down the notes returned by a list query
+type NoteFilter struct {
+	ID      int    `json:"id"`
+	Keyword string `json:"keyword"`
+	// DeckID limits the result to the notes of a deck
+	DeckID int `json:"deck_id"`
+	// TagIDs limits the result to notes linked to these tags
+	TagIDs []int `json:"tag_ids"`
+	// MatchAllTags requires every tag in TagIDs (AND), otherwise any of them (OR)
+	MatchAllTags bool `json:"match_all_tags"`
+	// CategoryID limits the result to a category and its subcategories
+	CategoryID int `json:"category_id"`
+}
+
+// NoteSearchResult is a note matched by a full-text search
+type NoteSearchResult struct {
+	Note Note `json:"note"`
+	// FrontSnippet and BackSnippet are excerpts with matches wrapped in <mark> tags
+	FrontSnippet string `json:"front_snippet"`
+	BackSnippet  string `json:"back_snippet"`
+	// Rank is the bm25 relevance score, lower is more relevant
+	Rank float64 `json:"rank"`
+}
+
+// NoteCursor marks a position in the note list for keyset pagination
+type NoteCursor struct {
+	UpdatedAt int64 `json:"updated_at"`
+	ID        int   `json:"id"`
+}
+
+// NotePage is a page of notes fetched with a cursor
+type NotePage struct {
+	Data []Note `json:"data"`
+	// NextCursor is passed back to fetch the following page, empty on the last page
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
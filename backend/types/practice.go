@@ -0,0 +1,94 @@
+package types
+
+// Practice outcomes recorded for each card of a session
+const (
+	OutcomeCorrect   = "correct"
+	OutcomeIncorrect = "incorrect"
+	OutcomeSkipped   = "skipped"
+	OutcomeTimedOut  = "timed_out"
+)
+
+// PracticeFilter selects the notes of a practice session
+type PracticeFilter struct {
+	TagIDs       []int `json:"tag_ids"`
+	MatchAllTags bool  `json:"match_all_tags"`
+	CategoryID   int   `json:"category_id"`
+	// DueOnly restricts the session to notes due for review
+	DueOnly bool `json:"due_only"`
+	// Sample picks that many notes at random, 0 takes every matching note in order
+	Sample int `json:"sample"`
+	// QuestionTimeout and AnswerTimeout are the slideshow timings in milliseconds
+	QuestionTimeout int `json:"question_timeout"`
+	AnswerTimeout   int `json:"answer_timeout"`
+}
+
+// PracticeSession is a persisted practice run and its summary
+type PracticeSession struct {
+	ID              int    `json:"id" gorm:"primaryKey"`
+	Filter          string `json:"-" gorm:"type:text"`
+	NoteIDs         string `json:"-" gorm:"type:text"`
+	QuestionTimeout int    `json:"question_timeout"`
+	AnswerTimeout   int    `json:"answer_timeout"`
+	Position        int    `json:"position"`
+	Total           int    `json:"total"`
+	Correct         int    `json:"correct"`
+	Incorrect       int    `json:"incorrect"`
+	Skipped         int    `json:"skipped"`
+	TimedOut        int    `json:"timed_out"`
+	StartedAt       int64  `json:"started_at" gorm:"autoCreateTime"`
+	FinishedAt      int64  `json:"finished_at"`
+}
+
+// TableName specifies the table name for PracticeSession model
+func (PracticeSession) TableName() string {
+	return "practice_sessions"
+}
+
+// PracticeResult is the outcome of one card in a practice session
+type PracticeResult struct {
+	ID        int    `json:"id" gorm:"primaryKey"`
+	SessionID int    `json:"session_id" gorm:"index;not null"`
+	NoteID    int    `json:"note_id" gorm:"index;not null"`
+	Outcome   string `json:"outcome" gorm:"type:varchar(20)"`
+	ElapsedMs int    `json:"elapsed_ms"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for PracticeResult model
+func (PracticeResult) TableName() string {
+	return "practice_results"
+}
+
+// PracticeCard is the card a session is currently showing
+type PracticeCard struct {
+	SessionID int `json:"session_id"`
+	// Position is the 1-based index of the card within the session
+	Position        int  `json:"position"`
+	Total           int  `json:"total"`
+	QuestionTimeout int  `json:"question_timeout"`
+	AnswerTimeout   int  `json:"answer_timeout"`
+	Note            Note `json:"note"`
+}
+
+// PracticeSessionList represents a paginated list of practice sessions
+type PracticeSessionList struct {
+	Total       int               `json:"total"`
+	TotalPages  int               `json:"total_pages"`
+	CurrentPage int               `json:"current_page"`
+	PageSize    int               `json:"page_size"`
+	Data        []PracticeSession `json:"data"`
+}
+
+// PracticeServiceIf defines the interface for practice session operations
+type PracticeServiceIf interface {
+	// StartSession selects notes with the filter and starts a new session
+	StartSession(filter PracticeFilter) JSResp
+	// NextCard returns the current card of a session, Data is empty once all cards are done
+	NextCard(sessionID int) JSResp
+	// RecordOutcome stores the outcome of the current card and moves to the next one
+	RecordOutcome(sessionID int, noteID int, outcome string, elapsedMs int) JSResp
+	// FinishSession ends a session early and returns its summary
+	FinishSession(sessionID int) JSResp
+	// Sessions returns a paginated list of past sessions, most recent first
+	Sessions(page, pageSize int) JSResp
+}
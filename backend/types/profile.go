@@ -0,0 +1,30 @@
+package types
+
+// ProfileEvent is emitted with the new active Profile after a switch
+const ProfileEvent = "profile:switched"
+
+// Profile is a learner workspace with its own database and asset directory
+type Profile struct {
+	Name string `json:"name"`
+	// Dir holds the profile's database and files, relative to the profiles config
+	Dir string `json:"dir"`
+	// Active is set on the profile the app is using
+	Active bool `json:"active"`
+}
+
+// ProfileServiceIf defines the interface for managing learner profiles
+type ProfileServiceIf interface {
+	// List returns all profiles
+	List() JSResp
+	// Active returns the profile in use
+	Active() JSResp
+	// Create adds an empty profile, its database is created when it is first opened
+	Create(name string) JSResp
+	// Rename changes the name of a profile, its files stay where they are
+	Rename(name string, newName string) JSResp
+	// Delete removes a profile together with its database and files, the
+	// active profile cannot be deleted
+	Delete(name string) JSResp
+	// Switch opens the database and files of another profile and makes it active
+	Switch(name string) JSResp
+}
@@ -0,0 +1,113 @@
+package types
+
+// ReviewState holds the spaced repetition scheduling state of a note
+type ReviewState struct {
+	NoteID int `json:"note_id" gorm:"primaryKey;autoIncrement:false"`
+	// Ease is the SM-2 ease factor
+	Ease float64 `json:"ease"`
+	// Interval is the current interval in days
+	Interval int   `json:"interval"`
+	Due      int64 `json:"due" gorm:"index"`
+	Reps     int   `json:"reps"`
+	Lapses   int   `json:"lapses"`
+	// Stability and Difficulty are the FSRS memory state
+	Stability  float64 `json:"stability"`
+	Difficulty float64 `json:"difficulty"`
+	LastReview int64   `json:"last_review"`
+}
+
+// TableName specifies the table name for ReviewState model
+func (ReviewState) TableName() string {
+	return "review_states"
+}
+
+// ReviewLog records a single review of a note
+type ReviewLog struct {
+	ID         int     `json:"id" gorm:"primaryKey"`
+	NoteID     int     `json:"note_id" gorm:"index;not null"`
+	Grade      int     `json:"grade"`
+	Algorithm  string  `json:"algorithm" gorm:"type:varchar(20)"`
+	Interval   int     `json:"interval"`
+	Ease       float64 `json:"ease"`
+	Stability  float64 `json:"stability"`
+	Difficulty float64 `json:"difficulty"`
+	// Answer and Score hold the typed answer a grade was derived from and its
+	// similarity to the expected text, Answer is empty for reviews graded by hand
+	Answer     string  `json:"answer" gorm:"type:text"`
+	Score      float64 `json:"score"`
+	ReviewedAt int64   `json:"reviewed_at" gorm:"index"`
+}
+
+// TableName specifies the table name for ReviewLog model
+func (ReviewLog) TableName() string {
+	return "review_logs"
+}
+
+// DueNote is a note waiting for review together with its scheduling state
+type DueNote struct {
+	Note  Note        `json:"note"`
+	State ReviewState `json:"state"`
+	// IsNew is set for notes that have never been reviewed
+	IsNew bool `json:"is_new"`
+}
+
+// ReviewStats summarizes the review progress of the collection
+type ReviewStats struct {
+	Notes int `json:"notes"`
+	// New counts the notes that were never reviewed, Due the reviewed notes
+	// that are due again
+	New     int `json:"new"`
+	Due     int `json:"due"`
+	Reviews int `json:"reviews"`
+	// ReviewsToday counts the reviews since the start of the day and
+	// FailedToday those of them graded Again
+	ReviewsToday int `json:"reviews_today"`
+	FailedToday  int `json:"failed_today"`
+}
+
+// Operations of the runs in an answer diff
+const (
+	DiffEqual   = "equal"
+	DiffMissing = "missing" // expected text the answer left out
+	DiffExtra   = "extra"   // typed text that is not expected
+)
+
+// AnswerDiff is a run of text in the diff between a typed answer and the expected text
+type AnswerDiff struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// AnswerCheck is the result of comparing a typed answer with the expected text
+type AnswerCheck struct {
+	// Expected and Answer are the normalized texts that were compared
+	Expected string `json:"expected"`
+	Answer   string `json:"answer"`
+	// Distance is the edit distance in characters, Score the similarity from 0 to 1
+	Distance int     `json:"distance"`
+	Score    float64 `json:"score"`
+	Correct  bool    `json:"correct"`
+	// Grade is the review grade the answer earns
+	Grade int          `json:"grade"`
+	Diff  []AnswerDiff `json:"diff"`
+	// State is the scheduling state after the review the answer was recorded as
+	State *ReviewState `json:"state,omitempty"`
+}
+
+// ReviewServiceIf defines the interface for review operations
+type ReviewServiceIf interface {
+	// GetDueNotes returns up to limit notes that are due for review
+	GetDueNotes(limit int) JSResp
+	// SubmitReview grades a review of a note and schedules the next one
+	SubmitReview(noteID int, grade int) JSResp
+	// CheckAnswer grades an answer typed for a side of a note and records the review
+	CheckAnswer(noteID int, side string, answer string) JSResp
+	// History returns the most recent reviews of a note
+	History(noteID int, limit int) JSResp
+	// Algorithm returns the name of the active scheduling algorithm
+	Algorithm() JSResp
+	// SetAlgorithm switches the scheduling algorithm
+	SetAlgorithm(name string) JSResp
+	// Stats returns the number of new and due notes and of the reviews done today
+	Stats() JSResp
+}
@@ -0,0 +1,69 @@
+package types
+
+// SettingsEvent is emitted with the new Settings after they change
+const SettingsEvent = "settings:changed"
+
+// Settings are the app preferences kept in the user's config directory
+type Settings struct {
+	// Version is the schema version the settings were saved with
+	Version int `json:"version"`
+	// Locale is the language of backend messages, empty follows the system
+	Locale string `json:"locale"`
+	// DataDir holds profiles.json and the provider configs, relative to the
+	// working directory. A change applies when the app is restarted.
+	DataDir     string              `json:"data_dir"`
+	Practice    PracticeSettings    `json:"practice"`
+	Review      ReviewSettings      `json:"review"`
+	Translation TranslationSettings `json:"translation"`
+	Speech      SpeechSettings      `json:"speech"`
+}
+
+// PracticeSettings are the slideshow timings in milliseconds of practice
+// sessions that set none
+type PracticeSettings struct {
+	QuestionTimeout int `json:"question_timeout"`
+	AnswerTimeout   int `json:"answer_timeout"`
+}
+
+// ReviewSettings configure the review scheduler
+type ReviewSettings struct {
+	// Algorithm is the name of the scheduling algorithm, such as "sm2" or "fsrs"
+	Algorithm string `json:"algorithm"`
+}
+
+// TranslationSettings choose among the providers of translate.json, a change
+// applies when the app is restarted
+type TranslationSettings struct {
+	// Provider restricts translation to the language pairs of that provider,
+	// empty uses every configured pair
+	Provider string `json:"provider"`
+}
+
+// SpeechSettings override speech.json, a change applies when the app is restarted
+type SpeechSettings struct {
+	// Provider replaces the backend of the config, empty keeps it
+	Provider string `json:"provider"`
+	// Voice replaces the default voice of the config, empty keeps it
+	Voice string `json:"voice"`
+}
+
+// SettingsChoices lists the values the settings with a fixed set of values accept
+type SettingsChoices struct {
+	Locales      []string `json:"locales"`
+	Algorithms   []string `json:"algorithms"`
+	Translators  []string `json:"translators"`
+	Synthesizers []string `json:"synthesizers"`
+}
+
+// SettingsServiceIf defines the interface for reading and changing the app settings
+type SettingsServiceIf interface {
+	// Get returns the current settings
+	Get() JSResp
+	// Update validates and saves settings and applies the ones that take
+	// effect right away, a SettingsEvent is emitted when anything changed
+	Update(settings Settings) JSResp
+	// Reset saves and applies the default settings
+	Reset() JSResp
+	// Choices returns the values the locale, algorithm and provider settings accept
+	Choices() JSResp
+}
@@ -0,0 +1,33 @@
+package types
+
+// Note sides that can be spoken
+const (
+	SideFront = "front"
+	SideBack  = "back"
+)
+
+// SpeechCache remembers the asset generated for a text and voice, so the
+// same sentence is not synthesized twice
+type SpeechCache struct {
+	// Key is the hex SHA-256 of the backend, voice and text
+	Key       string `json:"key" gorm:"primaryKey;type:char(64)"`
+	AssetID   int    `json:"asset_id" gorm:"index;not null"`
+	Asset     Asset  `json:"asset"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for SpeechCache model
+func (SpeechCache) TableName() string {
+	return "speech_cache"
+}
+
+// SpeechServiceIf defines the interface for generating note audio
+type SpeechServiceIf interface {
+	// Generate synthesizes a side of a note and attaches it as the audio of that side.
+	// Existing audio is only replaced when overwrite is set. An empty voice uses the
+	// voice configured for the language of the side in the note's deck, or the default.
+	Generate(noteID int, side string, voice string, overwrite bool) JSResp
+	// FillTag generates the missing audio of a side for every note with the tag, Updated
+	// counts the generated files
+	FillTag(tagID int, side string, voice string) JSResp
+}
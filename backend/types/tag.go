@@ -32,14 +32,42 @@ type TagServiceIf interface {
 	Delete(id int) JSResp
 }
 
-// NoteServiceIf defines the interface for tag operations
+// NoteServiceIf defines the interface for note operations
 type NoteServiceIf interface {
-	// List returns a paginated list of tags
-	List(page, pageSize int, keyword string) JSResp
-	// Create creates a new tag
-	Create(name string) JSResp
-	// Update updates an existing tag
-	Update(id int, frontCont, backCont string) JSResp
-	// Delete deletes a tag
+	// List returns a paginated list of notes matching the filter
+	List(page, pageSize int, filter NoteFilter) JSResp
+	// ListByCursor returns the notes after cursor using keyset pagination
+	ListByCursor(cursor string, pageSize int, filter NoteFilter) JSResp
+	// Get returns a single note with its tags
+	Get(id int) JSResp
+	// Create creates a new note from the given payload
+	Create(payload NotePayload) JSResp
+	// Update replaces the editable fields of an existing note
+	Update(id int, payload NotePayload) JSResp
+	// Delete deletes a note
 	Delete(id int) JSResp
+	// AddTags links additional tags to a note
+	AddTags(id int, tagIDs []int) JSResp
+	// RemoveTags unlinks tags from a note
+	RemoveTags(id int, tagIDs []int) JSResp
+	// SetTags replaces all tags of a note
+	SetTags(id int, tagIDs []int) JSResp
+	// Search returns notes ranked by relevance to the query with highlighted snippets
+	Search(query string, limit int) JSResp
+	// RebuildSearchIndex regenerates the full-text search index
+	RebuildSearchIndex() JSResp
+	// Translate translates text from source to target using the configured providers and the cache
+	Translate(text string, source string, target string) JSResp
+	// FillBack sets the back of a note to the translation of its front, an existing
+	// back is only replaced when overwrite is set. Empty languages come from the note's deck.
+	FillBack(id int, source string, target string, overwrite bool) JSResp
+	// ListDecks returns all decks ordered by name, the notes of a deck are listed
+	// with the DeckID of the filter
+	ListDecks() JSResp
+	// CreateDeck creates a deck for a language pair
+	CreateDeck(payload DeckPayload) JSResp
+	// UpdateDeck renames a deck or changes its languages
+	UpdateDeck(id int, payload DeckPayload) JSResp
+	// DeleteDeck deletes a deck that has no notes
+	DeleteDeck(id int) JSResp
 }
@@ -0,0 +1,21 @@
+package types
+
+// Translation caches a machine translation of a text for a language pair
+type Translation struct {
+	ID int `json:"id" gorm:"primaryKey"`
+	// Source and Target are canonical BCP 47 language codes
+	Source string `json:"source" gorm:"type:varchar(35);uniqueIndex:idx_translations_key"`
+	Target string `json:"target" gorm:"type:varchar(35);uniqueIndex:idx_translations_key"`
+	// TextHash is the hex SHA-256 of Text, it keeps the cache key short for long sentences
+	TextHash string `json:"-" gorm:"type:char(64);uniqueIndex:idx_translations_key"`
+	Text     string `json:"text" gorm:"type:text"`
+	Result   string `json:"result" gorm:"type:text"`
+	// Provider is the name of the provider that produced the result
+	Provider  string `json:"provider" gorm:"type:varchar(50)"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for Translation model
+func (Translation) TableName() string {
+	return "translations"
+}
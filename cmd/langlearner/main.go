@@ -0,0 +1,223 @@
+// Command langlearner manages the notes, tags and reviews of a LangLearner
+// database from the terminal. It runs on the same storage and services as the
+// desktop app:
+//
+//	langlearner [--db path] [--json] <command> [arguments]
+//
+// Without --db it uses the database of the active learner profile. The
+// language of the messages, the data directory and the review algorithm come
+// from the app settings.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/profiles"
+	"langlearner1/backend/services"
+	"langlearner1/backend/settings"
+	"langlearner1/backend/storage"
+	"langlearner1/backend/types"
+
+	"gorm.io/gorm/logger"
+)
+
+const usage = `Usage: langlearner [--db path] [--settings path] [--json] <command> [arguments]
+
+Commands:
+  notes list|show|search|add|edit|rm   manage notes
+  tags list|add|rename|rm              manage tags
+  decks list|add|rm                    manage decks
+  import FILE                          import a .csv, .tsv or .apkg file
+  export FILE                          export notes to a .csv, .tsv or .apkg file
+  review                               review the due notes
+  stats                                show the review progress
+
+Run "langlearner <command> -h" for the options of a command.
+
+Global options:
+`
+
+// errUsage reports a command line that could not be understood, the problem
+// has already been printed
+var errUsage = errors.New("usage")
+
+// cli holds the services the commands run on
+type cli struct {
+	tags    types.TagServiceIf
+	notes   types.NoteServiceIf
+	reviews types.ReviewServiceIf
+	csv     types.CSVServiceIf
+	anki    types.AnkiServiceIf
+
+	json   bool
+	in     *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	// Keep database warnings out of the output, which may be piped as JSON
+	logger.Default = logger.New(log.New(os.Stderr, "", log.LstdFlags), logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+	})
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("langlearner", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	dbPath := fs.String("db", "", "database file, default is the database of the active profile in the data directory")
+	settingsPath := fs.String("settings", settings.DefaultPath(), "settings file of the app")
+	asJSON := fs.Bool("json", false, "print JSON instead of tables")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	settingsMgr, err := settings.Load(*settingsPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "langlearner:", err)
+		return 1
+	}
+	for _, problem := range settingsMgr.Problems() {
+		fmt.Fprintln(stderr, "langlearner:", problem)
+	}
+	config := settingsMgr.Get()
+	i18n.SetLocale(settings.Locale(config))
+
+	path, assetDir, err := locate(*dbPath, config)
+	if err != nil {
+		fmt.Fprintln(stderr, "langlearner:", err)
+		return 1
+	}
+	store, err := storage.Open(path)
+	if err != nil {
+		fmt.Fprintln(stderr, "langlearner:", err)
+		return 1
+	}
+	defer store.Close()
+
+	c := newCLI(store, assetDir)
+	c.reviews.SetAlgorithm(config.Review.Algorithm)
+	c.json = *asJSON
+	c.in = bufio.NewReader(stdin)
+	c.stdout, c.stderr = stdout, stderr
+
+	err = c.dispatch(fs.Arg(0), fs.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return 2
+	case err != nil:
+		fmt.Fprintln(stderr, "langlearner:", err)
+		return 1
+	}
+	return 0
+}
+
+// locate returns the database to open and the directory of the asset store
+// next to it. An empty dbPath selects the active profile of the data
+// directory in config.
+func locate(dbPath string, config types.Settings) (string, string, error) {
+	if dbPath != "" {
+		return dbPath, filepath.Join(filepath.Dir(dbPath), "assets"), nil
+	}
+	profileMgr, err := profiles.Load(settings.DataPath(config, "profiles.json"))
+	if err != nil {
+		return "", "", err
+	}
+	profile := profileMgr.Active()
+	return profileMgr.DatabasePath(profile), profileMgr.AssetDir(profile), nil
+}
+
+// newCLI creates the services on store the way the app does
+func newCLI(store *storage.Store, assetDir string) *cli {
+	ctx := context.Background()
+	tagSvc := services.NewTagService(store)
+	noteSvc := services.NewNoteServiceImpl(store)
+	reviewSvc := services.NewReviewService(store)
+	csvSvc := services.NewCSVService(store)
+	ankiSvc := services.NewAnkiService(store, services.NewAssetService(store, assetDir))
+	tagSvc.(*(services.TagServiceImpl)).Start(ctx)
+	noteSvc.(*(services.NoteServiceImpl)).Start(ctx)
+	reviewSvc.(*(services.ReviewServiceImpl)).Start(ctx)
+	csvSvc.(*(services.CSVServiceImpl)).Start(ctx)
+	ankiSvc.(*(services.AnkiServiceImpl)).Start(ctx)
+	return &cli{
+		tags:    tagSvc,
+		notes:   noteSvc,
+		reviews: reviewSvc,
+		csv:     csvSvc,
+		anki:    ankiSvc,
+	}
+}
+
+// dispatch runs the command named name
+func (c *cli) dispatch(name string, args []string) error {
+	switch name {
+	case "notes":
+		return c.subcommand("notes", args, map[string]func([]string) error{
+			"list":   c.listNotes,
+			"show":   c.showNote,
+			"search": c.searchNotes,
+			"add":    c.addNote,
+			"edit":   c.editNote,
+			"rm":     c.removeNote,
+		})
+	case "tags":
+		return c.subcommand("tags", args, map[string]func([]string) error{
+			"list":   c.listTags,
+			"add":    c.addTag,
+			"rename": c.renameTag,
+			"rm":     c.removeTag,
+		})
+	case "decks":
+		return c.subcommand("decks", args, map[string]func([]string) error{
+			"list": c.listDecks,
+			"add":  c.addDeck,
+			"rm":   c.removeDeck,
+		})
+	case "import":
+		return c.importFile(args)
+	case "export":
+		return c.exportFile(args)
+	case "review":
+		return c.review(args)
+	case "stats":
+		return c.stats(args)
+	}
+	fmt.Fprintf(c.stderr, "langlearner: unknown command %q\n", name)
+	return errUsage
+}
+
+// subcommand runs the subcommand of group named by the first of args
+func (c *cli) subcommand(group string, args []string, commands map[string]func([]string) error) error {
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			return command(args[1:])
+		}
+		fmt.Fprintf(c.stderr, "langlearner: unknown command %q\n", group+" "+args[0])
+	} else {
+		fmt.Fprintf(c.stderr, "langlearner: %s needs a command\n", group)
+	}
+	return errUsage
+}
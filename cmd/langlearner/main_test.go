@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"langlearner1/backend/types"
+)
+
+// runner runs command lines against a database in a temporary directory
+type runner struct {
+	t   *testing.T
+	dir string
+}
+
+func newRunner(t *testing.T) *runner {
+	return &runner{t: t, dir: t.TempDir()}
+}
+
+// run runs args with input on stdin and returns the exit status and the output
+func (r *runner) run(input string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"--db", filepath.Join(r.dir, "test.db"), "--settings", filepath.Join(r.dir, "settings.json")}, args...)
+	code := run(args, strings.NewReader(input), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// json runs args with --json, requires success and decodes the output into v
+func (r *runner) json(v interface{}, args ...string) {
+	code, stdout, stderr := r.run("", append([]string{"--json"}, args...)...)
+	require.Equal(r.t, 0, code, stderr)
+	require.NoError(r.t, json.Unmarshal([]byte(stdout), v), stdout)
+}
+
+func TestNotesAndTags(t *testing.T) {
+	r := newRunner(t)
+	var tag types.Tag
+	r.json(&tag, "tags", "add", "n5")
+	assert.Equal(t, "n5", tag.Name)
+
+	var note types.Note
+	r.json(&note, "notes", "add", "--front", "犬", "--back", "dog", "--tags", "1")
+	assert.Equal(t, "inu", note.Romaji)
+
+	r.json(&note, "notes", "edit", "1", "--back", "a dog")
+	assert.Equal(t, "犬", note.Front, "fields without a flag are kept")
+	assert.Equal(t, "a dog", note.Back)
+	require.Len(t, note.Tags, 1)
+
+	code, stdout, _ := r.run("", "notes", "list", "--tags", "1")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "a dog")
+	assert.Contains(t, stdout, "1 notes")
+
+	code, _, stderr := r.run("", "tags", "add", "n5")
+	assert.Equal(t, 1, code)
+	assert.NotEmpty(t, stderr)
+
+	code, _, _ = r.run("", "notes", "rm")
+	assert.Equal(t, 2, code, "a missing id is a usage error")
+	code, _, _ = r.run("", "notes", "copy")
+	assert.Equal(t, 2, code)
+}
+
+func TestInvalidSettings(t *testing.T) {
+	r := newRunner(t)
+	require.NoError(t, os.WriteFile(filepath.Join(r.dir, "settings.json"), []byte(`{"practice": {"question_timeout": 100}}`), 0644))
+
+	// The command runs with the default for the invalid value and tells about it
+	code, stdout, stderr := r.run("", "tags", "add", "n5")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "n5")
+	assert.Contains(t, stderr, "practice.question_timeout")
+}
+
+func TestReviewAndStats(t *testing.T) {
+	r := newRunner(t)
+	var note types.Note
+	r.json(&note, "notes", "add", "--front", "犬", "--back", "dog")
+	r.json(&note, "notes", "add", "--front", "猫", "--back", "cat")
+	r.json(&note, "notes", "add", "--front", "鳥", "--back", "bird")
+
+	// A typed answer for the first note, a grade by hand for the second, then quit
+	code, stdout, stderr := r.run("dog\n\nmaybe\n1\n\nq\n", "review")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Correct")
+	assert.Contains(t, stdout, "Reviewed 2 notes, 1 left")
+
+	var stats types.ReviewStats
+	r.json(&stats, "stats")
+	assert.Equal(t, types.ReviewStats{Notes: 3, New: 1, Reviews: 2, ReviewsToday: 2, FailedToday: 1}, stats)
+}
+
+func TestImportExport(t *testing.T) {
+	r := newRunner(t)
+	var note types.Note
+	r.json(&note, "notes", "add", "--front", "犬", "--back", "dog")
+	path := filepath.Join(r.dir, "notes.csv")
+
+	var exported types.ExportReport
+	r.json(&exported, "export", path, "--header")
+	assert.Equal(t, 1, exported.Notes)
+
+	var imported types.ImportReport
+	r.json(&imported, "import", path, "--header", "--dry-run")
+	assert.True(t, imported.DryRun)
+	assert.Equal(t, 1, imported.Total)
+	assert.Empty(t, imported.Errors)
+}
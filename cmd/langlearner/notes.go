@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"langlearner1/backend/types"
+)
+
+// deleted is printed for a removed record
+type deleted struct {
+	ID      int  `json:"id"`
+	Deleted bool `json:"deleted"`
+}
+
+// remove deletes the record whose id is the single argument with del
+func (c *cli) remove(name string, args []string, del func(id int) types.JSResp) error {
+	fs := c.newFlags(name+" rm", "ID")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	recordID, err := id(positional[0])
+	if err != nil {
+		return err
+	}
+	if _, err := result(del(recordID)); err != nil {
+		return err
+	}
+	return c.show(deleted{ID: recordID, Deleted: true}, func(w io.Writer) {
+		fmt.Fprintf(w, "Deleted %s %d\n", name[:len(name)-1], recordID)
+	})
+}
+
+func (c *cli) listNotes(args []string) error {
+	fs := c.newFlags("notes list", "[options]")
+	page := fs.Int("page", 1, "page number")
+	size := fs.Int("size", 20, "notes per page")
+	filter := filterFlags(fs)
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := result(c.notes.List(*page, *size, *filter))
+	if err != nil {
+		return err
+	}
+	list := data.(*types.NoteList)
+	return c.show(list, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tFRONT\tBACK\tDECK\tTAGS")
+		for _, note := range list.Data {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", note.ID, cell(note.Front), cell(note.Back), note.DeckID, tagNames(note.Tags))
+		}
+		fmt.Fprintf(w, "Page %d of %d, %d notes\n", list.CurrentPage, list.TotalPages, list.Total)
+	})
+}
+
+func (c *cli) showNote(args []string) error {
+	fs := c.newFlags("notes show", "ID")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	noteID, err := id(positional[0])
+	if err != nil {
+		return err
+	}
+	data, err := result(c.notes.Get(noteID))
+	if err != nil {
+		return err
+	}
+	return c.printNote(data.(*types.Note))
+}
+
+// printNote prints the fields of a note one per line
+func (c *cli) printNote(note *types.Note) error {
+	return c.show(note, func(w io.Writer) {
+		fmt.Fprintf(w, "ID\t%d\n", note.ID)
+		if note.Title != "" {
+			fmt.Fprintf(w, "Title\t%s\n", note.Title)
+		}
+		fmt.Fprintf(w, "Front\t%s\n", text(note.Front))
+		fmt.Fprintf(w, "Back\t%s\n", text(note.Back))
+		if note.Romaji != "" {
+			fmt.Fprintf(w, "Romaji\t%s\n", note.Romaji)
+		}
+		fmt.Fprintf(w, "Deck\t%d\n", note.DeckID)
+		if note.CategoryID != nil {
+			fmt.Fprintf(w, "Category\t%d\n", *note.CategoryID)
+		}
+		fmt.Fprintf(w, "Tags\t%s\n", tagNames(note.Tags))
+		fmt.Fprintf(w, "Updated\t%s\n", date(note.UpdatedAt))
+	})
+}
+
+func (c *cli) searchNotes(args []string) error {
+	fs := c.newFlags("notes search", "QUERY [--limit N]")
+	limit := fs.Int("limit", 20, "maximum number of results")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := result(c.notes.Search(positional[0], *limit))
+	if err != nil {
+		return err
+	}
+	results := data.([]types.NoteSearchResult)
+	return c.show(results, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tFRONT\tBACK")
+		for _, found := range results {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", found.Note.ID, cell(found.Note.Front), cell(found.Note.Back))
+		}
+	})
+}
+
+// noteFlags defines the flags of the editable note fields on fs
+func noteFlags(fs *flag.FlagSet) *types.NotePayload {
+	payload := &types.NotePayload{}
+	fs.StringVar(&payload.Title, "title", "", "title of the note")
+	fs.StringVar(&payload.Front, "front", "", "front of the note")
+	fs.StringVar(&payload.Back, "back", "", "back of the note")
+	fs.IntVar(&payload.DeckID, "deck", 0, "id of the deck, default is the default deck")
+	fs.IntVar(&payload.CategoryID, "category", 0, "id of the category, 0 for none")
+	fs.Var((*idList)(&payload.TagIDs), "tags", "comma separated tag ids, empty for none")
+	return payload
+}
+
+func (c *cli) addNote(args []string) error {
+	fs := c.newFlags("notes add", "--front TEXT [options]")
+	payload := noteFlags(fs)
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := result(c.notes.Create(*payload))
+	if err != nil {
+		return err
+	}
+	return c.printNote(data.(*types.Note))
+}
+
+// editNote changes the fields given as flags and keeps the others
+func (c *cli) editNote(args []string) error {
+	fs := c.newFlags("notes edit", "ID [options]")
+	changes := noteFlags(fs)
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	noteID, err := id(positional[0])
+	if err != nil {
+		return err
+	}
+	data, err := result(c.notes.Get(noteID))
+	if err != nil {
+		return err
+	}
+
+	note := data.(*types.Note)
+	payload := types.NotePayload{Title: note.Title, Front: note.Front, Back: note.Back, DeckID: note.DeckID}
+	if note.CategoryID != nil {
+		payload.CategoryID = *note.CategoryID
+	}
+	for _, tag := range note.Tags {
+		payload.TagIDs = append(payload.TagIDs, tag.ID)
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			payload.Title = changes.Title
+		case "front":
+			payload.Front = changes.Front
+		case "back":
+			payload.Back = changes.Back
+		case "deck":
+			payload.DeckID = changes.DeckID
+		case "category":
+			payload.CategoryID = changes.CategoryID
+		case "tags":
+			payload.TagIDs = changes.TagIDs
+		}
+	})
+	if _, err := result(c.notes.Update(noteID, payload)); err != nil {
+		return err
+	}
+	data, err = result(c.notes.Get(noteID))
+	if err != nil {
+		return err
+	}
+	return c.printNote(data.(*types.Note))
+}
+
+func (c *cli) removeNote(args []string) error {
+	return c.remove("notes", args, c.notes.Delete)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"langlearner1/backend/types"
+)
+
+// cellWidth is the number of characters a table cell is cut to
+const cellWidth = 40
+
+var (
+	markup     = regexp.MustCompile(`<[^>]*>|\[sound:[^\]]*\]`)
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+// newFlags returns the flag set of a command, usage describes its arguments
+func (c *cli) newFlags(name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: langlearner %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args with fs, flags may also follow the positional arguments.
+// It fails unless there are exactly n positional arguments.
+func parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != n {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// id parses the id of a record given as a positional argument
+func id(arg string) (int, error) {
+	value, err := strconv.Atoi(arg)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("invalid id %q", arg)
+	}
+	return value, nil
+}
+
+// idList is a flag holding comma separated ids
+type idList []int
+
+func (l *idList) String() string {
+	parts := make([]string, len(*l))
+	for i, value := range *l {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *idList) Set(value string) error {
+	*l = nil
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	for _, part := range strings.Split(value, ",") {
+		value, err := id(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		*l = append(*l, value)
+	}
+	return nil
+}
+
+// filterFlags defines the flags selecting notes on fs
+func filterFlags(fs *flag.FlagSet) *types.NoteFilter {
+	filter := &types.NoteFilter{}
+	fs.StringVar(&filter.Keyword, "keyword", "", "only notes containing the keyword")
+	fs.IntVar(&filter.DeckID, "deck", 0, "only notes of the deck with this id")
+	fs.Var((*idList)(&filter.TagIDs), "tags", "only notes with any of these comma separated tag ids")
+	fs.BoolVar(&filter.MatchAllTags, "all-tags", false, "require all of the --tags")
+	fs.IntVar(&filter.CategoryID, "category", 0, "only notes in the category with this id or its subcategories")
+	return filter
+}
+
+// result returns the data of a service response, or its error
+func result(resp types.JSResp) (interface{}, error) {
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// show prints data as JSON with --json, otherwise table writes it as tab
+// separated columns
+func (c *cli) show(data interface{}, table func(w io.Writer)) error {
+	if c.json {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(data)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// text returns a note field without markup on a single line
+func text(field string) string {
+	field = html.UnescapeString(markup.ReplaceAllString(field, " "))
+	return strings.TrimSpace(whitespace.ReplaceAllString(field, " "))
+}
+
+// cell returns a note field for a table cell, cut to cellWidth characters
+func cell(field string) string {
+	field = text(field)
+	if utf8.RuneCountInString(field) <= cellWidth {
+		return field
+	}
+	return string([]rune(field)[:cellWidth-1]) + "…"
+}
+
+// tagNames returns the names of tags separated by commas
+func tagNames(tags []types.Tag) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// date formats a unix timestamp as a local date, empty for 0
+func date(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).Format("2006-01-02 15:04")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"langlearner1/backend/srs"
+	"langlearner1/backend/types"
+)
+
+// gradeNames are the names of the review grades, indexed by grade
+var gradeNames = []string{"", "again", "hard", "good", "easy"}
+
+// reviewSummary is printed at the end of a review session
+type reviewSummary struct {
+	Reviewed int `json:"reviewed"`
+	// Remaining counts the due notes that were left when the session was quit
+	Remaining int `json:"remaining"`
+}
+
+// review shows the front of every due note and records the typed answer, or
+// the grade the learner gives after seeing the back. It ends when the notes
+// are done, on "q" at the grade prompt or at the end of the input.
+func (c *cli) review(args []string) error {
+	fs := c.newFlags("review", "[--limit N]")
+	limit := fs.Int("limit", 20, "maximum number of notes to review")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := result(c.reviews.GetDueNotes(*limit))
+	if err != nil {
+		return err
+	}
+	due := data.([]types.DueNote)
+
+	summary := reviewSummary{Remaining: len(due)}
+	for i, item := range due {
+		fmt.Fprintf(c.stdout, "\n[%d/%d] %s\n", i+1, len(due), text(item.Note.Front))
+		state, ok, err := c.reviewNote(item.Note)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		fmt.Fprintf(c.stdout, "Next review %s\n", date(state.Due))
+		summary.Reviewed++
+		summary.Remaining--
+	}
+	if len(due) == 0 {
+		fmt.Fprintln(c.stdout, "No notes are due")
+	}
+	return c.show(summary, func(w io.Writer) {
+		fmt.Fprintf(w, "\nReviewed %d notes, %d left\n", summary.Reviewed, summary.Remaining)
+	})
+}
+
+// reviewNote asks for the back of note and records the review, ok is false
+// when the learner quits
+func (c *cli) reviewNote(note types.Note) (state *types.ReviewState, ok bool, err error) {
+	answer, ok := c.prompt("Answer, or Enter to show the back: ")
+	if !ok {
+		return nil, false, nil
+	}
+	if answer != "" {
+		data, err := result(c.reviews.CheckAnswer(note.ID, types.SideBack, answer))
+		if err == nil {
+			check := data.(*types.AnswerCheck)
+			if check.Correct {
+				fmt.Fprintln(c.stdout, "Correct")
+			} else {
+				fmt.Fprintf(c.stdout, "%.0f%% right, the back is: %s\n", check.Score*100, text(note.Back))
+			}
+			fmt.Fprintf(c.stdout, "Graded %s\n", gradeNames[check.Grade])
+			return check.State, true, nil
+		}
+		// Notes without a back cannot be checked, fall back to grading by hand
+		fmt.Fprintln(c.stderr, "langlearner:", err)
+	}
+
+	fmt.Fprintf(c.stdout, "Back: %s\n", text(note.Back))
+	for {
+		input, ok := c.prompt("Grade 1 again, 2 hard, 3 good, 4 easy, q to quit: ")
+		if !ok || input == "q" {
+			return nil, false, nil
+		}
+		grade := srs.Grade(0)
+		for value, name := range gradeNames {
+			if value > 0 && (input == name || input == fmt.Sprint(value)) {
+				grade = srs.Grade(value)
+			}
+		}
+		if !grade.Valid() {
+			continue
+		}
+		data, err := result(c.reviews.SubmitReview(note.ID, int(grade)))
+		if err != nil {
+			return nil, false, err
+		}
+		return data.(*types.ReviewState), true, nil
+	}
+}
+
+// prompt prints question and reads a line of input, ok is false at the end of the input
+func (c *cli) prompt(question string) (line string, ok bool) {
+	fmt.Fprint(c.stdout, question)
+	line, err := c.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(c.stdout)
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+func (c *cli) stats(args []string) error {
+	fs := c.newFlags("stats", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := result(c.reviews.Stats())
+	if err != nil {
+		return err
+	}
+	stats := data.(*types.ReviewStats)
+	return c.show(stats, func(w io.Writer) {
+		fmt.Fprintf(w, "Notes\t%d\n", stats.Notes)
+		fmt.Fprintf(w, "New\t%d\n", stats.New)
+		fmt.Fprintf(w, "Due\t%d\n", stats.Due)
+		fmt.Fprintf(w, "Reviews\t%d\n", stats.Reviews)
+		fmt.Fprintf(w, "Reviews today\t%d\n", stats.ReviewsToday)
+		fmt.Fprintf(w, "Failed today\t%d\n", stats.FailedToday)
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"langlearner1/backend/types"
+)
+
+func (c *cli) listTags(args []string) error {
+	fs := c.newFlags("tags list", "[options]")
+	page := fs.Int("page", 1, "page number")
+	size := fs.Int("size", 50, "tags per page")
+	keyword := fs.String("keyword", "", "only tags containing the keyword")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := result(c.tags.List(*page, *size, *keyword))
+	if err != nil {
+		return err
+	}
+	list := data.(*types.TagList)
+	return c.show(list, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME")
+		for _, tag := range list.Data {
+			fmt.Fprintf(w, "%d\t%s\n", tag.ID, tag.Name)
+		}
+		fmt.Fprintf(w, "Page %d of %d, %d tags\n", list.CurrentPage, list.TotalPages, list.Total)
+	})
+}
+
+// printTag prints the tag in data
+func (c *cli) printTag(data interface{}) error {
+	tag := data.(*types.Tag)
+	return c.show(tag, func(w io.Writer) {
+		fmt.Fprintf(w, "%d\t%s\n", tag.ID, tag.Name)
+	})
+}
+
+func (c *cli) addTag(args []string) error {
+	fs := c.newFlags("tags add", "NAME")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := result(c.tags.Create(positional[0]))
+	if err != nil {
+		return err
+	}
+	return c.printTag(data)
+}
+
+func (c *cli) renameTag(args []string) error {
+	fs := c.newFlags("tags rename", "ID NAME")
+	positional, err := parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	tagID, err := id(positional[0])
+	if err != nil {
+		return err
+	}
+	data, err := result(c.tags.Update(tagID, positional[1]))
+	if err != nil {
+		return err
+	}
+	return c.printTag(data)
+}
+
+func (c *cli) removeTag(args []string) error {
+	return c.remove("tags", args, c.tags.Delete)
+}
+
+func (c *cli) listDecks(args []string) error {
+	fs := c.newFlags("decks list", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := result(c.notes.ListDecks())
+	if err != nil {
+		return err
+	}
+	decks := data.([]types.Deck)
+	return c.show(decks, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tSOURCE\tTARGET")
+		for _, deck := range decks {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", deck.ID, deck.Name, deck.Source, deck.Target)
+		}
+	})
+}
+
+func (c *cli) addDeck(args []string) error {
+	fs := c.newFlags("decks add", "NAME --source LANG --target LANG")
+	source := fs.String("source", "", "language of the note fronts, such as ja")
+	target := fs.String("target", "", "language of the note backs, such as en")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := result(c.notes.CreateDeck(types.DeckPayload{Name: positional[0], Source: *source, Target: *target}))
+	if err != nil {
+		return err
+	}
+	deck := data.(*types.Deck)
+	return c.show(deck, func(w io.Writer) {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", deck.ID, deck.Name, deck.Source, deck.Target)
+	})
+}
+
+func (c *cli) removeDeck(args []string) error {
+	return c.remove("decks", args, c.notes.DeleteDeck)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"langlearner1/backend/types"
+)
+
+// isAnki reports whether path names an Anki package, other files are read
+// and written as CSV or TSV
+func isAnki(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".apkg")
+}
+
+func (c *cli) importFile(args []string) error {
+	fs := c.newFlags("import", "FILE [options]")
+	deckID := fs.Int("deck", 0, "id of the deck of new notes, default is the default deck")
+	categoryID := fs.Int("category", 0, "id of the category of the imported notes")
+	dryRun := fs.Bool("dry-run", false, "check the file without writing anything")
+	front := fs.String("front", "", "field, or CSV column name or number, of the note fronts")
+	back := fs.String("back", "", "field, or CSV column name or number, of the note backs")
+	csvOptions := types.CSVImportOptions{}
+	fs.StringVar(&csvOptions.Delimiter, "delimiter", "", "CSV delimiter, a single character or \"tab\"")
+	fs.StringVar(&csvOptions.Encoding, "encoding", "", "CSV encoding such as shift_jis or gbk, default is UTF-8")
+	fs.BoolVar(&csvOptions.HasHeader, "header", false, "the first CSV row holds the column names")
+	fs.StringVar(&csvOptions.Columns.Title, "title-column", "", "CSV column of the note titles")
+	fs.StringVar(&csvOptions.Columns.Category, "category-column", "", "CSV column of the category paths")
+	fs.StringVar(&csvOptions.Columns.Tags, "tags-column", "", "CSV column of the tags")
+	fs.StringVar(&csvOptions.TagSeparator, "tag-separator", "", "separator of the tags in a CSV column, default is \";\"")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	var resp types.JSResp
+	if path := positional[0]; isAnki(path) {
+		resp = c.anki.Import(path, types.AnkiImportOptions{
+			FrontField: *front,
+			BackField:  *back,
+			DeckID:     *deckID,
+			CategoryID: *categoryID,
+			DryRun:     *dryRun,
+		})
+	} else {
+		csvOptions.Columns.Front, csvOptions.Columns.Back = *front, *back
+		csvOptions.DeckID, csvOptions.CategoryID, csvOptions.DryRun = *deckID, *categoryID, *dryRun
+		resp = c.csv.Import(path, csvOptions)
+	}
+	data, err := result(resp)
+	if err != nil {
+		return err
+	}
+
+	report := data.(*types.ImportReport)
+	return c.show(report, func(w io.Writer) {
+		if report.DryRun {
+			fmt.Fprintln(w, "Dry run, nothing was written")
+		}
+		fmt.Fprintf(w, "Read\t%d\n", report.Total)
+		fmt.Fprintf(w, "Created\t%d\n", report.Created)
+		fmt.Fprintf(w, "Updated\t%d\n", report.Updated)
+		fmt.Fprintf(w, "Skipped\t%d\n", report.Skipped)
+		fmt.Fprintf(w, "New tags\t%d\n", report.TagsCreated)
+		fmt.Fprintf(w, "New categories\t%d\n", report.CategoriesCreated)
+		if report.MediaCopied > 0 {
+			fmt.Fprintf(w, "Media copied\t%d\n", report.MediaCopied)
+		}
+		for _, importErr := range report.Errors {
+			ref := importErr.Ref
+			if importErr.Row > 0 {
+				ref = fmt.Sprintf("row %d", importErr.Row)
+			}
+			fmt.Fprintf(w, "Error\t%s: %s\n", ref, importErr.Message)
+		}
+	})
+}
+
+func (c *cli) exportFile(args []string) error {
+	fs := c.newFlags("export", "FILE [options]")
+	filter := filterFlags(fs)
+	deckName := fs.String("deck-name", "", "name of the deck in an Anki package, default is \"LangLearner\"")
+	csvOptions := types.CSVExportOptions{}
+	fs.StringVar(&csvOptions.Delimiter, "delimiter", "", "CSV delimiter, a single character or \"tab\"")
+	fs.StringVar(&csvOptions.Encoding, "encoding", "", "CSV encoding such as shift_jis or gbk, default is UTF-8")
+	fs.BoolVar(&csvOptions.Header, "header", false, "write a first CSV row with the column names")
+	fs.StringVar(&csvOptions.TagSeparator, "tag-separator", "", "separator of the tags in a CSV column, default is \";\"")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	var resp types.JSResp
+	if path := positional[0]; isAnki(path) {
+		resp = c.anki.Export(path, types.AnkiExportOptions{DeckName: *deckName, Filter: *filter})
+	} else {
+		csvOptions.Filter = *filter
+		resp = c.csv.Export(path, csvOptions)
+	}
+	data, err := result(resp)
+	if err != nil {
+		return err
+	}
+
+	report := data.(*types.ExportReport)
+	return c.show(report, func(w io.Writer) {
+		fmt.Fprintf(w, "Notes\t%d\n", report.Notes)
+		if report.Media > 0 {
+			fmt.Fprintf(w, "Media\t%d\n", report.Media)
+		}
+		for _, name := range report.MissingMedia {
+			fmt.Fprintf(w, "Missing\t%s\n", name)
+		}
+	})
+}
@@ -1,8 +1,221 @@
 export namespace types {
 	
+	export class NoteFilter {
+	    id: number;
+	    keyword: string;
+	    deck_id: number;
+	    tag_ids: number[];
+	    match_all_tags: boolean;
+	    category_id: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NoteFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.keyword = source["keyword"];
+	        this.deck_id = source["deck_id"];
+	        this.tag_ids = source["tag_ids"];
+	        this.match_all_tags = source["match_all_tags"];
+	        this.category_id = source["category_id"];
+	    }
+	}
+	export class AnkiExportOptions {
+	    deck_name: string;
+	    filter: NoteFilter;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnkiExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deck_name = source["deck_name"];
+	        this.filter = this.convertValues(source["filter"], NoteFilter);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AnkiImportOptions {
+	    front_field: string;
+	    back_field: string;
+	    deck_id: number;
+	    category_id: number;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnkiImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.front_field = source["front_field"];
+	        this.back_field = source["back_field"];
+	        this.deck_id = source["deck_id"];
+	        this.category_id = source["category_id"];
+	        this.dry_run = source["dry_run"];
+	    }
+	}
+	export class CSVColumns {
+	    title: string;
+	    front: string;
+	    back: string;
+	    category: string;
+	    tags: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVColumns(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.front = source["front"];
+	        this.back = source["back"];
+	        this.category = source["category"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class CSVExportOptions {
+	    delimiter: string;
+	    encoding: string;
+	    header: boolean;
+	    tag_separator: string;
+	    filter: NoteFilter;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delimiter = source["delimiter"];
+	        this.encoding = source["encoding"];
+	        this.header = source["header"];
+	        this.tag_separator = source["tag_separator"];
+	        this.filter = this.convertValues(source["filter"], NoteFilter);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CSVImportOptions {
+	    delimiter: string;
+	    encoding: string;
+	    has_header: boolean;
+	    columns: CSVColumns;
+	    tag_separator: string;
+	    deck_id: number;
+	    category_id: number;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delimiter = source["delimiter"];
+	        this.encoding = source["encoding"];
+	        this.has_header = source["has_header"];
+	        this.columns = this.convertValues(source["columns"], CSVColumns);
+	        this.tag_separator = source["tag_separator"];
+	        this.deck_id = source["deck_id"];
+	        this.category_id = source["category_id"];
+	        this.dry_run = source["dry_run"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DeckPayload {
+	    name: string;
+	    source: string;
+	    target: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeckPayload(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.source = source["source"];
+	        this.target = source["target"];
+	    }
+	}
+	export class ErrorDetail {
+	    field: string;
+	    code: string;
+	    key: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ErrorDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.code = source["code"];
+	        this.key = source["key"];
+	        this.message = source["message"];
+	    }
+	}
 	export class JSResp {
 	    success: number;
 	    msg: string;
+	    code?: string;
+	    key?: string;
+	    details?: ErrorDetail[];
 	    data?: any;
 	
 	    static createFrom(source: any = {}) {
@@ -13,9 +226,172 @@ export namespace types {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.success = source["success"];
 	        this.msg = source["msg"];
+	        this.code = source["code"];
+	        this.key = source["key"];
+	        this.details = this.convertValues(source["details"], ErrorDetail);
 	        this.data = source["data"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class NotePayload {
+	    title: string;
+	    front: string;
+	    back: string;
+	    deck_id: number;
+	    category_id: number;
+	    tag_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NotePayload(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.front = source["front"];
+	        this.back = source["back"];
+	        this.deck_id = source["deck_id"];
+	        this.category_id = source["category_id"];
+	        this.tag_ids = source["tag_ids"];
+	    }
+	}
+	export class PracticeFilter {
+	    tag_ids: number[];
+	    match_all_tags: boolean;
+	    category_id: number;
+	    due_only: boolean;
+	    sample: number;
+	    question_timeout: number;
+	    answer_timeout: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PracticeFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag_ids = source["tag_ids"];
+	        this.match_all_tags = source["match_all_tags"];
+	        this.category_id = source["category_id"];
+	        this.due_only = source["due_only"];
+	        this.sample = source["sample"];
+	        this.question_timeout = source["question_timeout"];
+	        this.answer_timeout = source["answer_timeout"];
+	    }
+	}
+	export class PracticeSettings {
+	    question_timeout: number;
+	    answer_timeout: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PracticeSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.question_timeout = source["question_timeout"];
+	        this.answer_timeout = source["answer_timeout"];
+	    }
 	}
+	export class ReviewSettings {
+	    algorithm: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReviewSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.algorithm = source["algorithm"];
+	    }
+	}
+	export class SpeechSettings {
+	    provider: string;
+	    voice: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SpeechSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.provider = source["provider"];
+	        this.voice = source["voice"];
+	    }
+	}
+	export class TranslationSettings {
+	    provider: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TranslationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.provider = source["provider"];
+	    }
+	}
+	export class Settings {
+	    version: number;
+	    locale: string;
+	    data_dir: string;
+	    practice: PracticeSettings;
+	    review: ReviewSettings;
+	    translation: TranslationSettings;
+	    speech: SpeechSettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.locale = source["locale"];
+	        this.data_dir = source["data_dir"];
+	        this.practice = this.convertValues(source["practice"], PracticeSettings);
+	        this.review = this.convertValues(source["review"], ReviewSettings);
+	        this.translation = this.convertValues(source["translation"], TranslationSettings);
+	        this.speech = this.convertValues(source["speech"], SpeechSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
 
 }
 
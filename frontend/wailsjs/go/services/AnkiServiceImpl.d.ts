@@ -0,0 +1,10 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function Export(arg1:string,arg2:types.AnkiExportOptions):Promise<types.JSResp>;
+
+export function Import(arg1:string,arg2:types.AnkiImportOptions):Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
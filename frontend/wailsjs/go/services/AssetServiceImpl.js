@@ -0,0 +1,23 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Attach(arg1, arg2, arg3) {
+  return window['go']['services']['AssetServiceImpl']['Attach'](arg1, arg2, arg3);
+}
+
+export function CleanOrphans() {
+  return window['go']['services']['AssetServiceImpl']['CleanOrphans']();
+}
+
+export function Detach(arg1, arg2, arg3) {
+  return window['go']['services']['AssetServiceImpl']['Detach'](arg1, arg2, arg3);
+}
+
+export function List(arg1) {
+  return window['go']['services']['AssetServiceImpl']['List'](arg1);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['AssetServiceImpl']['Start'](arg1);
+}
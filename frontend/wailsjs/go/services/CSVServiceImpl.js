@@ -0,0 +1,15 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Export(arg1, arg2) {
+  return window['go']['services']['CSVServiceImpl']['Export'](arg1, arg2);
+}
+
+export function Import(arg1, arg2) {
+  return window['go']['services']['CSVServiceImpl']['Import'](arg1, arg2);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['CSVServiceImpl']['Start'](arg1);
+}
@@ -0,0 +1,27 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Create(arg1, arg2) {
+  return window['go']['services']['CategoryServiceImpl']['Create'](arg1, arg2);
+}
+
+export function Delete(arg1) {
+  return window['go']['services']['CategoryServiceImpl']['Delete'](arg1);
+}
+
+export function List(arg1, arg2, arg3) {
+  return window['go']['services']['CategoryServiceImpl']['List'](arg1, arg2, arg3);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['CategoryServiceImpl']['Start'](arg1);
+}
+
+export function Tree() {
+  return window['go']['services']['CategoryServiceImpl']['Tree']();
+}
+
+export function Update(arg1, arg2, arg3) {
+  return window['go']['services']['CategoryServiceImpl']['Update'](arg1, arg2, arg3);
+}
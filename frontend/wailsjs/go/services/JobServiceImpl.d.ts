@@ -0,0 +1,26 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function Cancel(arg1:number):Promise<types.JSResp>;
+
+export function ClearFinished():Promise<types.JSResp>;
+
+export function FillBacks(arg1:number,arg2:string,arg3:string):Promise<types.JSResp>;
+
+export function FillReadings(arg1:number):Promise<types.JSResp>;
+
+export function FillSpeech(arg1:number,arg2:string,arg3:string):Promise<types.JSResp>;
+
+export function Get(arg1:number):Promise<types.JSResp>;
+
+export function ImportAnki(arg1:string,arg2:types.AnkiImportOptions):Promise<types.JSResp>;
+
+export function ImportCSV(arg1:string,arg2:types.CSVImportOptions):Promise<types.JSResp>;
+
+export function List(arg1:string):Promise<types.JSResp>;
+
+export function RebuildSearchIndex():Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
@@ -0,0 +1,47 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Cancel(arg1) {
+  return window['go']['services']['JobServiceImpl']['Cancel'](arg1);
+}
+
+export function ClearFinished() {
+  return window['go']['services']['JobServiceImpl']['ClearFinished']();
+}
+
+export function FillBacks(arg1, arg2, arg3) {
+  return window['go']['services']['JobServiceImpl']['FillBacks'](arg1, arg2, arg3);
+}
+
+export function FillReadings(arg1) {
+  return window['go']['services']['JobServiceImpl']['FillReadings'](arg1);
+}
+
+export function FillSpeech(arg1, arg2, arg3) {
+  return window['go']['services']['JobServiceImpl']['FillSpeech'](arg1, arg2, arg3);
+}
+
+export function Get(arg1) {
+  return window['go']['services']['JobServiceImpl']['Get'](arg1);
+}
+
+export function ImportAnki(arg1, arg2) {
+  return window['go']['services']['JobServiceImpl']['ImportAnki'](arg1, arg2);
+}
+
+export function ImportCSV(arg1, arg2) {
+  return window['go']['services']['JobServiceImpl']['ImportCSV'](arg1, arg2);
+}
+
+export function List(arg1) {
+  return window['go']['services']['JobServiceImpl']['List'](arg1);
+}
+
+export function RebuildSearchIndex() {
+  return window['go']['services']['JobServiceImpl']['RebuildSearchIndex']();
+}
+
+export function Start(arg1) {
+  return window['go']['services']['JobServiceImpl']['Start'](arg1);
+}
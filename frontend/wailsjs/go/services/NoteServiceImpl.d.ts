@@ -0,0 +1,40 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function AddTags(arg1:number,arg2:Array<number>):Promise<types.JSResp>;
+
+export function Create(arg1:types.NotePayload):Promise<types.JSResp>;
+
+export function CreateDeck(arg1:types.DeckPayload):Promise<types.JSResp>;
+
+export function Delete(arg1:number):Promise<types.JSResp>;
+
+export function DeleteDeck(arg1:number):Promise<types.JSResp>;
+
+export function FillBack(arg1:number,arg2:string,arg3:string,arg4:boolean):Promise<types.JSResp>;
+
+export function Get(arg1:number):Promise<types.JSResp>;
+
+export function List(arg1:number,arg2:number,arg3:types.NoteFilter):Promise<types.JSResp>;
+
+export function ListByCursor(arg1:string,arg2:number,arg3:types.NoteFilter):Promise<types.JSResp>;
+
+export function ListDecks():Promise<types.JSResp>;
+
+export function RebuildSearchIndex():Promise<types.JSResp>;
+
+export function RemoveTags(arg1:number,arg2:Array<number>):Promise<types.JSResp>;
+
+export function Search(arg1:string,arg2:number):Promise<types.JSResp>;
+
+export function SetTags(arg1:number,arg2:Array<number>):Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
+
+export function Translate(arg1:string,arg2:string,arg3:string):Promise<types.JSResp>;
+
+export function Update(arg1:number,arg2:types.NotePayload):Promise<types.JSResp>;
+
+export function UpdateDeck(arg1:number,arg2:types.DeckPayload):Promise<types.JSResp>;
@@ -0,0 +1,75 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function AddTags(arg1, arg2) {
+  return window['go']['services']['NoteServiceImpl']['AddTags'](arg1, arg2);
+}
+
+export function Create(arg1) {
+  return window['go']['services']['NoteServiceImpl']['Create'](arg1);
+}
+
+export function CreateDeck(arg1) {
+  return window['go']['services']['NoteServiceImpl']['CreateDeck'](arg1);
+}
+
+export function Delete(arg1) {
+  return window['go']['services']['NoteServiceImpl']['Delete'](arg1);
+}
+
+export function DeleteDeck(arg1) {
+  return window['go']['services']['NoteServiceImpl']['DeleteDeck'](arg1);
+}
+
+export function FillBack(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['NoteServiceImpl']['FillBack'](arg1, arg2, arg3, arg4);
+}
+
+export function Get(arg1) {
+  return window['go']['services']['NoteServiceImpl']['Get'](arg1);
+}
+
+export function List(arg1, arg2, arg3) {
+  return window['go']['services']['NoteServiceImpl']['List'](arg1, arg2, arg3);
+}
+
+export function ListByCursor(arg1, arg2, arg3) {
+  return window['go']['services']['NoteServiceImpl']['ListByCursor'](arg1, arg2, arg3);
+}
+
+export function ListDecks() {
+  return window['go']['services']['NoteServiceImpl']['ListDecks']();
+}
+
+export function RebuildSearchIndex() {
+  return window['go']['services']['NoteServiceImpl']['RebuildSearchIndex']();
+}
+
+export function RemoveTags(arg1, arg2) {
+  return window['go']['services']['NoteServiceImpl']['RemoveTags'](arg1, arg2);
+}
+
+export function Search(arg1, arg2) {
+  return window['go']['services']['NoteServiceImpl']['Search'](arg1, arg2);
+}
+
+export function SetTags(arg1, arg2) {
+  return window['go']['services']['NoteServiceImpl']['SetTags'](arg1, arg2);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['NoteServiceImpl']['Start'](arg1);
+}
+
+export function Translate(arg1, arg2, arg3) {
+  return window['go']['services']['NoteServiceImpl']['Translate'](arg1, arg2, arg3);
+}
+
+export function Update(arg1, arg2) {
+  return window['go']['services']['NoteServiceImpl']['Update'](arg1, arg2);
+}
+
+export function UpdateDeck(arg1, arg2) {
+  return window['go']['services']['NoteServiceImpl']['UpdateDeck'](arg1, arg2);
+}
@@ -0,0 +1,16 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function FinishSession(arg1:number):Promise<types.JSResp>;
+
+export function NextCard(arg1:number):Promise<types.JSResp>;
+
+export function RecordOutcome(arg1:number,arg2:number,arg3:string,arg4:number):Promise<types.JSResp>;
+
+export function Sessions(arg1:number,arg2:number):Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
+
+export function StartSession(arg1:types.PracticeFilter):Promise<types.JSResp>;
@@ -0,0 +1,27 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function FinishSession(arg1) {
+  return window['go']['services']['PracticeServiceImpl']['FinishSession'](arg1);
+}
+
+export function NextCard(arg1) {
+  return window['go']['services']['PracticeServiceImpl']['NextCard'](arg1);
+}
+
+export function RecordOutcome(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['PracticeServiceImpl']['RecordOutcome'](arg1, arg2, arg3, arg4);
+}
+
+export function Sessions(arg1, arg2) {
+  return window['go']['services']['PracticeServiceImpl']['Sessions'](arg1, arg2);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['PracticeServiceImpl']['Start'](arg1);
+}
+
+export function StartSession(arg1) {
+  return window['go']['services']['PracticeServiceImpl']['StartSession'](arg1);
+}
@@ -0,0 +1,18 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function Active():Promise<types.JSResp>;
+
+export function Create(arg1:string):Promise<types.JSResp>;
+
+export function Delete(arg1:string):Promise<types.JSResp>;
+
+export function List():Promise<types.JSResp>;
+
+export function Rename(arg1:string,arg2:string):Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
+
+export function Switch(arg1:string):Promise<types.JSResp>;
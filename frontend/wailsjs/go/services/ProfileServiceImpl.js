@@ -0,0 +1,31 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Active() {
+  return window['go']['services']['ProfileServiceImpl']['Active']();
+}
+
+export function Create(arg1) {
+  return window['go']['services']['ProfileServiceImpl']['Create'](arg1);
+}
+
+export function Delete(arg1) {
+  return window['go']['services']['ProfileServiceImpl']['Delete'](arg1);
+}
+
+export function List() {
+  return window['go']['services']['ProfileServiceImpl']['List']();
+}
+
+export function Rename(arg1, arg2) {
+  return window['go']['services']['ProfileServiceImpl']['Rename'](arg1, arg2);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['ProfileServiceImpl']['Start'](arg1);
+}
+
+export function Switch(arg1) {
+  return window['go']['services']['ProfileServiceImpl']['Switch'](arg1);
+}
@@ -0,0 +1,20 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function Algorithm():Promise<types.JSResp>;
+
+export function CheckAnswer(arg1:number,arg2:string,arg3:string):Promise<types.JSResp>;
+
+export function GetDueNotes(arg1:number):Promise<types.JSResp>;
+
+export function History(arg1:number,arg2:number):Promise<types.JSResp>;
+
+export function SetAlgorithm(arg1:string):Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
+
+export function Stats():Promise<types.JSResp>;
+
+export function SubmitReview(arg1:number,arg2:number):Promise<types.JSResp>;
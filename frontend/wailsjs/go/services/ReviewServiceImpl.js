@@ -0,0 +1,35 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Algorithm() {
+  return window['go']['services']['ReviewServiceImpl']['Algorithm']();
+}
+
+export function CheckAnswer(arg1, arg2, arg3) {
+  return window['go']['services']['ReviewServiceImpl']['CheckAnswer'](arg1, arg2, arg3);
+}
+
+export function GetDueNotes(arg1) {
+  return window['go']['services']['ReviewServiceImpl']['GetDueNotes'](arg1);
+}
+
+export function History(arg1, arg2) {
+  return window['go']['services']['ReviewServiceImpl']['History'](arg1, arg2);
+}
+
+export function SetAlgorithm(arg1) {
+  return window['go']['services']['ReviewServiceImpl']['SetAlgorithm'](arg1);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['ReviewServiceImpl']['Start'](arg1);
+}
+
+export function Stats() {
+  return window['go']['services']['ReviewServiceImpl']['Stats']();
+}
+
+export function SubmitReview(arg1, arg2) {
+  return window['go']['services']['ReviewServiceImpl']['SubmitReview'](arg1, arg2);
+}
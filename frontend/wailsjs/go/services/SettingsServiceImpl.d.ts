@@ -0,0 +1,14 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function Choices():Promise<types.JSResp>;
+
+export function Get():Promise<types.JSResp>;
+
+export function Reset():Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
+
+export function Update(arg1:types.Settings):Promise<types.JSResp>;
@@ -0,0 +1,23 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function Choices() {
+  return window['go']['services']['SettingsServiceImpl']['Choices']();
+}
+
+export function Get() {
+  return window['go']['services']['SettingsServiceImpl']['Get']();
+}
+
+export function Reset() {
+  return window['go']['services']['SettingsServiceImpl']['Reset']();
+}
+
+export function Start(arg1) {
+  return window['go']['services']['SettingsServiceImpl']['Start'](arg1);
+}
+
+export function Update(arg1) {
+  return window['go']['services']['SettingsServiceImpl']['Update'](arg1);
+}
@@ -0,0 +1,10 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {types} from '../models';
+import {context} from '../models';
+
+export function FillTag(arg1:number,arg2:string,arg3:string):Promise<types.JSResp>;
+
+export function Generate(arg1:number,arg2:string,arg3:string,arg4:boolean):Promise<types.JSResp>;
+
+export function Start(arg1:context.Context):Promise<void>;
@@ -0,0 +1,15 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function FillTag(arg1, arg2, arg3) {
+  return window['go']['services']['SpeechServiceImpl']['FillTag'](arg1, arg2, arg3);
+}
+
+export function Generate(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['SpeechServiceImpl']['Generate'](arg1, arg2, arg3, arg4);
+}
+
+export function Start(arg1) {
+  return window['go']['services']['SpeechServiceImpl']['Start'](arg1);
+}
@@ -3,8 +3,13 @@ package main
 import (
 	"context"
 	"embed"
+	"langlearner1/backend/api"
+	"langlearner1/backend/i18n"
+	"langlearner1/backend/profiles"
 	"langlearner1/backend/services"
+	"langlearner1/backend/settings"
 	"langlearner1/backend/storage"
+	"log"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -15,30 +20,125 @@ import (
 var assets embed.FS
 
 func main() {
-	// Initialize database
-	if err := storage.InitDB("data/langlearner.db"); err != nil {
+	// Load the app settings from the user's config directory
+	settingsMgr, err := settings.Load(settings.DefaultPath())
+	if err != nil {
+		panic(err)
+	}
+	// The values the app could not use were replaced by their defaults
+	for _, problem := range settingsMgr.Problems() {
+		log.Println(problem)
+	}
+	config := settingsMgr.Get()
+
+	// Translate backend messages to the language of the settings or the system
+	i18n.SetLocale(settings.Locale(config))
+
+	// Load the learner profiles, every profile has its own database and files
+	profileMgr, err := profiles.Load(settings.DataPath(config, "profiles.json"))
+	if err != nil {
+		panic(err)
+	}
+	profile := profileMgr.Active()
+
+	// Open the database of the active profile
+	store, err := storage.Open(profileMgr.DatabasePath(profile))
+	if err != nil {
+		panic(err)
+	}
+	defer store.Close()
+
+	// Load the translation providers configured per language pair
+	translators, err := settings.Translators(settings.DataPath(config, "translate.json"), config)
+	if err != nil {
+		panic(err)
+	}
+
+	// Load the text-to-speech backend, none is configured without the file
+	synthesizer, err := settings.Synthesizer(settings.DataPath(config, "speech.json"), config)
+	if err != nil {
+		panic(err)
+	}
+
+	// Load the REST API server config, the server is off without the file
+	apiConfig, err := api.Load(settings.DataPath(config, "api.json"))
+	if err != nil {
 		panic(err)
 	}
 
 	// Create instance of the app service
-	tagSvc := services.NewTagService()
-	noteSvc := services.NewNoteServiceImpl()
+	tagSvc := services.NewTagService(store)
+	noteSvc := services.NewNoteServiceImpl(store, services.WithTranslators(translators))
+	categorySvc := services.NewCategoryService(store)
+	reviewSvc := services.NewReviewService(store)
+	practiceSvc := services.NewPracticeService(store)
+	csvSvc := services.NewCSVService(store)
+	assetSvc := services.NewAssetService(store, profileMgr.AssetDir(profile))
+	ankiSvc := services.NewAnkiService(store, assetSvc)
+	speechSvc := services.NewSpeechService(store, assetSvc, services.WithSynthesizer(synthesizer))
+	jobSvc := services.NewJobService(store, noteSvc, csvSvc, ankiSvc, speechSvc)
+	profileSvc := services.NewProfileService(profileMgr, store, assetSvc, jobSvc)
+	settingsSvc := services.NewSettingsService(settingsMgr, reviewSvc, practiceSvc)
+	var apiServer *api.Server
+	if apiConfig != nil {
+		apiServer = api.NewServer(*apiConfig, api.Services{
+			Tags:       tagSvc,
+			Notes:      noteSvc,
+			Categories: categorySvc,
+			Reviews:    reviewSvc,
+		})
+	}
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "langlearner1",
 		Width:  900,
 		Height: 600,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
+			// Note media is served from the asset store under /media/
+			Handler: services.NewAssetHandler(assetSvc),
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup: func(ctx context.Context) {
 			tagSvc.(*(services.TagServiceImpl)).Start(ctx)
 			noteSvc.(*(services.NoteServiceImpl)).Start(ctx)
+			categorySvc.(*(services.CategoryServiceImpl)).Start(ctx)
+			reviewSvc.(*(services.ReviewServiceImpl)).Start(ctx)
+			practiceSvc.(*(services.PracticeServiceImpl)).Start(ctx)
+			ankiSvc.(*(services.AnkiServiceImpl)).Start(ctx)
+			csvSvc.(*(services.CSVServiceImpl)).Start(ctx)
+			assetSvc.(*(services.AssetServiceImpl)).Start(ctx)
+			speechSvc.(*(services.SpeechServiceImpl)).Start(ctx)
+			// Started last, resumed jobs use the other services
+			jobSvc.(*(services.JobServiceImpl)).Start(ctx)
+			profileSvc.(*(services.ProfileServiceImpl)).Start(ctx)
+			settingsSvc.(*(services.SettingsServiceImpl)).Start(ctx)
+			if apiServer != nil {
+				if err := apiServer.Start(); err != nil {
+					println("Error:", err.Error())
+				}
+			}
+		},
+		OnShutdown: func(ctx context.Context) {
+			if apiServer != nil {
+				apiServer.Stop(ctx)
+			}
+			services.StopJobs(jobSvc)
 		},
 		Bind: []interface{}{
 			tagSvc,
+			noteSvc,
+			categorySvc,
+			reviewSvc,
+			practiceSvc,
+			ankiSvc,
+			csvSvc,
+			assetSvc,
+			speechSvc,
+			jobSvc,
+			profileSvc,
+			settingsSvc,
 		},
 	})
 